@@ -16,8 +16,9 @@ func main() {
 	defer stop()
 
 	logger := logging.New()
-	logger.Info("starting saki-tools MCP stdio server", map[string]any{
-		"debug": os.Getenv("SAKI_TOOLS_MCP_DEBUG"),
+	logger.Info("starting saki-tools MCP server", map[string]any{
+		"debug":     os.Getenv("SAKI_TOOLS_MCP_DEBUG"),
+		"http_addr": os.Getenv("SAKI_TOOLS_MCP_HTTP_ADDR"),
 	})
 	service := tool.NewService()
 	server := mcp.NewServer(service, logger)
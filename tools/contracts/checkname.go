@@ -0,0 +1,27 @@
+package contracts
+
+import "github.com/1800agents/saki/tools/internal/apperrors"
+
+// CheckNameInput is the request payload for the saki_check_name tool call.
+type CheckNameInput struct {
+	SakiControlPlaneURL string `json:"saki_control_plane_url"`
+	Name                string `json:"name"`
+}
+
+// CheckNameOutput is the response payload for the saki_check_name tool call.
+type CheckNameOutput struct {
+	Name string `json:"name"`
+	// Available is true when no app uses the name yet.
+	Available bool `json:"available"`
+	// OwnedByYou is true when the caller already owns an app with the name, so
+	// deploying updates it.
+	OwnedByYou bool `json:"owned_by_you"`
+}
+
+// Validate checks the name the same way DeployAppInput.Validate does.
+func (in CheckNameInput) Validate() error {
+	if err := validateName(in.Name); err != nil {
+		return apperrors.NewMulti(&FieldError{Field: "name", Err: err})
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package contracts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// DeleteAppInput is the request payload for the saki_delete_app tool call.
+type DeleteAppInput struct {
+	SakiControlPlaneURL string `json:"saki_control_plane_url"`
+	// AppID is the app_id returned by saki_deploy_app.
+	AppID string `json:"app_id"`
+}
+
+// DeleteAppOutput is the response payload for the saki_delete_app tool call.
+type DeleteAppOutput struct {
+	AppID  string `json:"app_id"`
+	Status string `json:"status"`
+}
+
+// Validate checks that an app id is present.
+func (in DeleteAppInput) Validate() error {
+	if strings.TrimSpace(in.AppID) == "" {
+		return apperrors.NewMulti(&FieldError{Field: "app_id", Err: fmt.Errorf("must not be empty")})
+	}
+	return nil
+}
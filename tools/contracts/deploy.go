@@ -2,24 +2,72 @@ package contracts
 
 import (
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
 )
 
 const (
 	maxNameLength        = 63
 	maxDescriptionLength = 300
+	maxNoteLength        = 500
 )
 
 var dnsSafeNamePattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$`)
 
+// imageRepositoryPattern matches an image repository reference without a tag
+// or digest: an optional registry host[:port] followed by lowercase path
+// components separated by slashes.
+var imageRepositoryPattern = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9.-]*[a-zA-Z0-9])?(?::[0-9]+)?/)?[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+
+var gitCommitPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+var noteLineBreaks = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
 // DeployAppInput is the request payload for the saki_deploy_app tool call.
 type DeployAppInput struct {
 	SakiControlPlaneURL string `json:"saki_control_plane_url"`
 	Name                string `json:"name"`
 	Description         string `json:"description"`
+	// Org optionally names the control plane org (namespace) the app belongs
+	// to, for multi-tenant control planes. It is a DNS-safe slug like Name.
+	Org string `json:"org,omitempty"`
 	// AppDir is the local directory containing the app source to build.
 	AppDir string `json:"app_dir"`
+	// Platforms optionally lists target build platforms (e.g. linux/amd64).
+	Platforms []string `json:"platforms,omitempty"`
+	// DryRun calls prepare and checks the app's current state, then returns a
+	// plan instead of building, pushing, or deploying.
+	DryRun bool `json:"dry_run,omitempty"`
+	// ValidateOnly checks the input and configuration without calling the
+	// control plane, docker, or the registry.
+	ValidateOnly bool `json:"validate_only,omitempty"`
+	// ImageRepository optionally replaces the repository returned by prepare
+	// (e.g. ghcr.io/team/my-app). The prepare required tag is still used.
+	ImageRepository string `json:"image_repository,omitempty"`
+	// GitCommit optionally names the commit the image is built from, skipping
+	// git entirely; for builds from an exported tree without a .git
+	// directory. It is a 7 to 40 character hex hash.
+	GitCommit string `json:"git_commit,omitempty"`
+	// Force pushes even when SAKI_IMMUTABLE_TAGS finds the tag already in the
+	// registry with different content.
+	Force bool `json:"force,omitempty"`
+	// StateFile is where deploy progress is saved after each stage, so a
+	// deploy interrupted by a crash can be resumed.
+	StateFile string `json:"state_file,omitempty"`
+	// Resume skips the stages StateFile records as completed. It requires
+	// StateFile.
+	Resume bool `json:"resume,omitempty"`
+	// BuildArgFiles maps docker build arg names to files whose contents,
+	// without a trailing newline, become the arg's value.
+	BuildArgFiles map[string]string `json:"build_arg_files,omitempty"`
+	// Note is free text recorded with the deployment for audit, such as
+	// "hotfix for incident 123". It does not affect the build.
+	Note string `json:"note,omitempty"`
 }
 
 // DeployAppOutput is the response payload for the saki_deploy_app tool call.
@@ -29,21 +77,93 @@ type DeployAppOutput struct {
 	Image        string `json:"image"`
 	URL          string `json:"url"`
 	Status       string `json:"status"`
+	// GitCommit is the commit the image was built from.
+	GitCommit string `json:"git_commit,omitempty"`
+	// TokenExpiresAt is the prepare push token expiry. It is informational only.
+	TokenExpiresAt time.Time `json:"token_expires_at,omitzero"`
+	// Plan is set for dry runs and describes what a real deploy would do.
+	Plan *DeployPlan `json:"plan,omitempty"`
+	// BuildCache summarizes BuildKit cache hits for the build. It is omitted
+	// when no build ran or the build output could not be parsed.
+	BuildCache *BuildCacheStats `json:"build_cache,omitempty"`
+	// Timings maps each completed stage (prepare, login, build, push, deploy,
+	// smoke_check), plus "total", to its duration in milliseconds.
+	Timings map[string]int64 `json:"timings_ms,omitempty"`
+}
+
+// BuildCacheStats reports how many Dockerfile steps were served from the
+// build cache.
+type BuildCacheStats struct {
+	CachedSteps int `json:"cached_steps"`
+	TotalSteps  int `json:"total_steps"`
+}
+
+// DeployPlan describes what a deploy would do, as reported by a dry run.
+type DeployPlan struct {
+	// Action is create, update, unchanged, or blocked.
+	Action string `json:"action"`
+	// CurrentImage is the image live for the app today, when it exists.
+	CurrentImage string `json:"current_image,omitempty"`
+	// Conflict explains why the app name cannot be deployed, when Action is blocked.
+	Conflict string `json:"conflict,omitempty"`
+	// Image is the fully qualified image a real deploy would build and push.
+	Image string `json:"image"`
+	// Registry is the registry host the image is pushed to.
+	Registry string `json:"registry"`
+	// ControlPlaneHost is the control plane host, without the token.
+	ControlPlaneHost string `json:"control_plane_host"`
+	// GitCommit is the commit the image would be built from.
+	GitCommit string `json:"git_commit"`
+	// Steps lists the side effects a real deploy would perform, in order.
+	Steps []string `json:"steps"`
+	// SkippedSteps lists optional steps the current configuration leaves out,
+	// each with the reason.
+	SkippedSteps []string `json:"skipped_steps"`
+}
+
+// FieldError reports one invalid DeployAppInput field.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid %s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
 }
 
+func (e *FieldError) ErrorCode() apperrors.Code {
+	return apperrors.CodeInvalidInput
+}
+
+// Validate checks every field and reports all invalid ones at once as
+// *FieldError values aggregated into an *apperrors.Multi.
 func (in DeployAppInput) Validate() error {
-	if err := validateName(in.Name); err != nil {
-		return fmt.Errorf("invalid name: %w", err)
+	checks := []struct {
+		field string
+		err   error
+	}{
+		{"name", validateName(in.Name)},
+		{"description", validateDescription(in.Description)},
+		{"org", validateOptionalOrg(in.Org)},
+		{"app_dir", validateAppDir(in.AppDir)},
+		{"image_repository", validateOptionalImageRepository(in.ImageRepository)},
+		{"git_commit", validateOptionalGitCommit(in.GitCommit)},
+		{"resume", validateResume(in.Resume, in.StateFile)},
+		{"build_arg_files", validateBuildArgFiles(in.BuildArgFiles)},
+		{"note", validateOptionalNote(in.Note)},
 	}
 
-	if err := validateDescription(in.Description); err != nil {
-		return fmt.Errorf("invalid description: %w", err)
+	var errs []error
+	for _, check := range checks {
+		if check.err != nil {
+			errs = append(errs, &FieldError{Field: check.field, Err: check.err})
+		}
 	}
-	if err := validateAppDir(in.AppDir); err != nil {
-		return fmt.Errorf("invalid app_dir: %w", err)
-	}
-
-	return nil
+	return apperrors.NewMulti(errs...)
 }
 
 func validateName(name string) error {
@@ -75,9 +195,95 @@ func validateDescription(description string) error {
 	return nil
 }
 
+func validateOptionalNote(note string) error {
+	if len(CleanNote(note)) > maxNoteLength {
+		return fmt.Errorf("must be %d characters or fewer", maxNoteLength)
+	}
+	return nil
+}
+
+// CleanNote flattens a deployment note to one line: each line break becomes
+// a space and surrounding whitespace is trimmed.
+func CleanNote(note string) string {
+	return strings.TrimSpace(noteLineBreaks.Replace(note))
+}
+
+func validateOptionalOrg(org string) error {
+	if org == "" {
+		return nil
+	}
+	return validateName(org)
+}
+
 func validateAppDir(appDir string) error {
 	if strings.TrimSpace(appDir) == "" {
 		return fmt.Errorf("must not be empty")
 	}
 	return nil
 }
+
+func validateOptionalImageRepository(repository string) error {
+	if strings.TrimSpace(repository) == "" {
+		return nil
+	}
+	return ValidateImageRepository(repository)
+}
+
+func validateResume(resume bool, stateFile string) error {
+	if resume && strings.TrimSpace(stateFile) == "" {
+		return fmt.Errorf("requires state_file")
+	}
+	return nil
+}
+
+// envNamePattern matches names docker accepts as build args and environment
+// variables.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// dockerCLIEnvNames configure the docker CLI itself. Build arg values reach
+// docker through its environment, so these names cannot be build args.
+var dockerCLIEnvNames = []string{"HOME", "PATH"}
+
+func validateBuildArgFiles(files map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("build arg %q must contain only letters, digits, and underscores, and not start with a digit", name)
+		}
+		if slices.Contains(dockerCLIEnvNames, name) || strings.HasPrefix(name, "DOCKER_") || strings.HasPrefix(name, "BUILDX_") {
+			return fmt.Errorf("build arg %q is reserved: it would configure the docker CLI", name)
+		}
+		if strings.TrimSpace(files[name]) == "" {
+			return fmt.Errorf("build arg %q needs a file path", name)
+		}
+	}
+	return nil
+}
+
+func validateOptionalGitCommit(commit string) error {
+	if strings.TrimSpace(commit) == "" {
+		return nil
+	}
+	return ValidateGitCommit(commit)
+}
+
+// ValidateGitCommit checks that commit is an abbreviated or full git commit
+// hash: 7 to 40 hex characters.
+func ValidateGitCommit(commit string) error {
+	if !gitCommitPattern.MatchString(strings.TrimSpace(commit)) {
+		return fmt.Errorf("must be a git commit hash of 7 to 40 hex characters")
+	}
+	return nil
+}
+
+// ValidateImageRepository checks that repository is a legal image repository
+// reference without a tag or digest.
+func ValidateImageRepository(repository string) error {
+	repository = strings.TrimSpace(repository)
+	if repository == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	if !imageRepositoryPattern.MatchString(repository) {
+		return fmt.Errorf("must be an image repository reference like registry.internal:5000/team/app (lowercase path, no scheme, tag, or digest)")
+	}
+	return nil
+}
@@ -1,8 +1,11 @@
 package contracts
 
 import (
+	"errors"
 	"strings"
 	"testing"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
 )
 
 func TestDeployAppInputValidate_Success(t *testing.T) {
@@ -82,3 +85,241 @@ func TestDeployAppInputValidate_InvalidAppDir(t *testing.T) {
 		t.Fatalf("expected validation error for app_dir")
 	}
 }
+
+func TestDeployAppInputValidate_ReportsEveryInvalidField(t *testing.T) {
+	in := DeployAppInput{
+		Name:        "Bad_Name",
+		Description: strings.Repeat("a", 301),
+		AppDir:      "",
+	}
+
+	err := in.Validate()
+	var multi *apperrors.Multi
+	if !errors.As(err, &multi) {
+		t.Fatalf("expected aggregated validation errors, got %T: %v", err, err)
+	}
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected code %q, got %q", apperrors.CodeInvalidInput, got)
+	}
+
+	var fields []string
+	for _, fieldErr := range multi.Errors {
+		var fe *FieldError
+		if !errors.As(fieldErr, &fe) {
+			t.Fatalf("expected *FieldError, got %T", fieldErr)
+		}
+		fields = append(fields, fe.Field)
+	}
+	if got := strings.Join(fields, ","); got != "name,description,app_dir" {
+		t.Fatalf("expected every invalid field to be reported, got %q", got)
+	}
+
+	for _, want := range []string{"invalid name:", "invalid description:", "invalid app_dir:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to include %q, got %q", want, err.Error())
+		}
+	}
+}
+
+func TestDeployAppInputValidate_ImageRepository(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: ""},
+		{value: "team/my-app"},
+		{value: "ghcr.io/team/my-app"},
+		{value: "registry.internal:5000/team/my_app"},
+		{value: "localhost:5000/my-app"},
+		{value: "https://ghcr.io/team/my-app", wantErr: true},
+		{value: "ghcr.io/team/my-app:latest", wantErr: true},
+		{value: "ghcr.io/team/my-app@sha256:abc", wantErr: true},
+		{value: "ghcr.io/Team/my-app", wantErr: true},
+		{value: "ghcr.io//my-app", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			in := DeployAppInput{
+				Name:            "valid-app",
+				Description:     "valid description",
+				AppDir:          "/tmp/my-app",
+				ImageRepository: tt.value,
+			}
+
+			err := in.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("image_repository %q: expected error=%v, got %v", tt.value, tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDeployAppInputValidate_GitCommit(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: ""},
+		{value: "abc1234"},
+		{value: "0123456789ABCDEF0123456789abcdef01234567"},
+		{value: "abc123", wantErr: true},
+		{value: "0123456789abcdef0123456789abcdef012345678", wantErr: true},
+		{value: "main", wantErr: true},
+		{value: "abc123g", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			in := DeployAppInput{
+				Name:        "valid-app",
+				Description: "valid description",
+				AppDir:      "/tmp/my-app",
+				GitCommit:   tt.value,
+			}
+
+			err := in.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("git_commit %q: expected error=%v, got %v", tt.value, tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDeployAppInputValidate_ResumeRequiresStateFile(t *testing.T) {
+	in := DeployAppInput{
+		Name:        "valid-app",
+		Description: "valid description",
+		AppDir:      "/tmp/my-app",
+		Resume:      true,
+	}
+	err := in.Validate()
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "resume" {
+		t.Fatalf("expected a resume field error, got %v", err)
+	}
+
+	in.StateFile = "/tmp/my-app.state.json"
+	if err := in.Validate(); err != nil {
+		t.Fatalf("expected resume with a state file to be valid, got %v", err)
+	}
+}
+
+func TestDeployAppInputValidate_BuildArgFiles(t *testing.T) {
+	tests := []struct {
+		files   map[string]string
+		wantErr string
+	}{
+		{files: map[string]string{"NPM_TOKEN": "/run/secrets/npm"}},
+		{files: map[string]string{"1ARG": "/tmp/a"}, wantErr: "must contain only letters"},
+		{files: map[string]string{"DOCKER_HOST": "/tmp/a"}, wantErr: "would configure the docker CLI"},
+		{files: map[string]string{"PATH": "/tmp/a"}, wantErr: "would configure the docker CLI"},
+		{files: map[string]string{"NPM_TOKEN": " "}, wantErr: "needs a file path"},
+	}
+
+	for _, tt := range tests {
+		in := DeployAppInput{
+			Name:          "valid-app",
+			Description:   "valid description",
+			AppDir:        "/tmp/my-app",
+			BuildArgFiles: tt.files,
+		}
+		err := in.Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Fatalf("%v: expected no error, got %v", tt.files, err)
+			}
+			continue
+		}
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) || fieldErr.Field != "build_arg_files" || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Fatalf("%v: expected a build_arg_files error containing %q, got %v", tt.files, tt.wantErr, err)
+		}
+	}
+}
+
+func TestDeployAppInputValidate_Org(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: ""},
+		{value: "platform-team"},
+		{value: "acme1"},
+		{value: "Platform", wantErr: true},
+		{value: "platform_team", wantErr: true},
+		{value: "-platform", wantErr: true},
+		{value: strings.Repeat("a", 64), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			in := DeployAppInput{
+				Name:        "valid-app",
+				Description: "valid description",
+				Org:         tt.value,
+				AppDir:      "/tmp/my-app",
+			}
+
+			err := in.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("org %q: expected error=%v, got %v", tt.value, tt.wantErr, err)
+			}
+			var fieldErr *FieldError
+			if tt.wantErr && (!errors.As(err, &fieldErr) || fieldErr.Field != "org") {
+				t.Fatalf("expected org field error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDeployAppInputValidate_Note(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "empty", value: ""},
+		{name: "short", value: "hotfix for incident 123"},
+		{name: "at limit", value: strings.Repeat("a", 500)},
+		{name: "at limit after trimming", value: "\n" + strings.Repeat("a", 500) + "\r\n"},
+		{name: "too long", value: strings.Repeat("a", 501), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := DeployAppInput{
+				Name:        "valid-app",
+				Description: "valid description",
+				AppDir:      "/tmp/my-app",
+				Note:        tt.value,
+			}
+
+			err := in.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			var fieldErr *FieldError
+			if tt.wantErr && (!errors.As(err, &fieldErr) || fieldErr.Field != "note") {
+				t.Fatalf("expected note field error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCleanNote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "hotfix for incident 123", want: "hotfix for incident 123"},
+		{in: "  hotfix\nfor incident 123\r\n", want: "hotfix for incident 123"},
+		{in: "line one\r\nline two\rline three", want: "line one line two line three"},
+		{in: "\n\n", want: ""},
+	}
+	for _, tt := range tests {
+		if got := CleanNote(tt.in); got != tt.want {
+			t.Fatalf("CleanNote(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
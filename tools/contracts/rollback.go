@@ -0,0 +1,39 @@
+package contracts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// RollbackAppInput is the request payload for the saki_rollback tool call.
+type RollbackAppInput struct {
+	SakiControlPlaneURL string `json:"saki_control_plane_url"`
+	// AppID is the app_id returned by saki_deploy_app.
+	AppID string `json:"app_id"`
+	// DeploymentID is the earlier deployment whose image is redeployed.
+	DeploymentID string `json:"deployment_id"`
+}
+
+// RollbackAppOutput is the response payload for the saki_rollback tool call.
+// DeploymentID is the new deployment the rollback started, not the one rolled
+// back to.
+type RollbackAppOutput struct {
+	AppID        string `json:"app_id"`
+	DeploymentID string `json:"deployment_id"`
+	URL          string `json:"url"`
+	Status       string `json:"status"`
+}
+
+// Validate checks that an app id and a deployment id are present.
+func (in RollbackAppInput) Validate() error {
+	var errs []error
+	if strings.TrimSpace(in.AppID) == "" {
+		errs = append(errs, &FieldError{Field: "app_id", Err: fmt.Errorf("must not be empty")})
+	}
+	if strings.TrimSpace(in.DeploymentID) == "" {
+		errs = append(errs, &FieldError{Field: "deployment_id", Err: fmt.Errorf("must not be empty")})
+	}
+	return apperrors.NewMulti(errs...)
+}
@@ -3,21 +3,48 @@ package controlplane
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net"
 	"net/http"
 	"net/url"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/jitter"
+	"github.com/1800agents/saki/tools/internal/logging"
+	"github.com/1800agents/saki/tools/internal/version"
 )
 
 const defaultRequestTimeout = 15 * time.Second
 
+// APIVersion is the control plane API version this client speaks by default.
+// It is sent as Accept-Version; responses carrying an X-API-Version with the
+// same major version are compatible.
+const APIVersion = "1.0"
+
+const (
+	acceptVersionHeader  = "Accept-Version"
+	idempotencyKeyHeader = "Idempotency-Key"
+	apiVersionHeader     = "X-API-Version"
+)
+
+// Logger receives structured log events from the control plane client.
+type Logger interface {
+	Info(msg string, fields map[string]any)
+	Error(msg string, fields map[string]any)
+}
+
 // HTTPClient abstracts http.Client for easier testing.
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
@@ -29,12 +56,42 @@ type Client struct {
 	token          string
 	httpClient     HTTPClient
 	requestTimeout time.Duration
+	apiVersion     string
+	logger         Logger
+	// statusMapper is set by WithStatusMapper.
+	statusMapper func(status int, body []byte) apperrors.Code
+	// observer is set by WithObserver.
+	observer func(op string, statusCode int, duration time.Duration, err error)
+	// tokenInHeader sends the token as a bearer Authorization header instead
+	// of the token query parameter; set by WithTokenInHeader.
+	tokenInHeader bool
+	// idempotencyKey is the Idempotency-Key for DeployApp requests that do
+	// not carry their own; set by WithIdempotencyKey.
+	idempotencyKey string
+	// userAgent is sent as User-Agent on every request; set by
+	// WithUserAgent.
+	userAgent string
+	// clientCerts and rootCAs configure TLS for the default HTTP client;
+	// set by WithClientCertificate and WithRootCAs.
+	clientCerts []tls.Certificate
+	rootCAs     *x509.CertPool
+	// proxyURL overrides the environment proxy; set by WithProxy.
+	proxyURL string
+	// basePath is the API prefix inserted before every endpoint path; set
+	// by WithBasePath and normalized by NewClient.
+	basePath string
+
+	// maxAttempts and retryBaseDelay are set by WithRetry.
+	maxAttempts    int
+	retryBaseDelay time.Duration
+	jitter         jitter.Func
 }
 
 // PrepareAppRequest is the payload for POST /apps/prepare.
 type PrepareAppRequest struct {
 	Name      string `json:"name"`
 	GitCommit string `json:"git_commit"`
+	Org       string `json:"org,omitempty"`
 }
 
 // PrepareAppResponse is the response body from POST /apps/prepare.
@@ -45,13 +102,39 @@ type PrepareAppResponse struct {
 	RequiredTag        string    `json:"required_tag"`
 	TemplateRepository string    `json:"template_repository"`
 	TemplateRef        string    `json:"template_ref"`
+	// DeploymentToken is a one-time token for the POST /apps that follows.
+	// The control plane dedupes deploys on it, so a deploy that carries it
+	// can be retried without creating a second deployment.
+	DeploymentToken string `json:"deployment_token,omitempty"`
+	// ExistingAppID is set when the caller already has an app with the
+	// requested name, for control planes that update apps with
+	// PATCH /apps/{app_id} rather than POST /apps.
+	ExistingAppID string `json:"existing_app_id,omitempty"`
 }
 
-// DeployAppRequest is the payload for POST /apps.
+// DeployAppRequest is the payload for POST /apps and PATCH /apps/{app_id}.
 type DeployAppRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Image       string `json:"image"`
+	Org         string `json:"org,omitempty"`
+	// DeploymentToken echoes PrepareAppResponse.DeploymentToken.
+	DeploymentToken string `json:"deployment_token,omitempty"`
+	// ContextHash identifies the build context the image was built from, for
+	// reproducibility auditing; sent when SAKI_CONTEXT_HASH is enabled.
+	ContextHash string `json:"context_hash,omitempty"`
+	// DockerfileHash and Dockerfile record the Dockerfile the image was
+	// built from; sent when SAKI_DOCKERFILE_HASH or SAKI_DOCKERFILE_CONTENT
+	// is enabled.
+	DockerfileHash string `json:"dockerfile_hash,omitempty"`
+	Dockerfile     string `json:"dockerfile,omitempty"`
+	// Note is the caller's free-text audit note for the deployment.
+	Note string `json:"note,omitempty"`
+	// IdempotencyKey is sent as the Idempotency-Key header, not in the body.
+	// Callers that retry a deploy reuse it so the control plane can dedupe.
+	// When empty, the client's WithIdempotencyKey key is used, or else a new
+	// key per DeployApp call.
+	IdempotencyKey string `json:"-"`
 }
 
 // DeployAppResponse is the response body from POST /apps.
@@ -62,33 +145,131 @@ type DeployAppResponse struct {
 	Status       string `json:"status"`
 }
 
+// App is the response body from GET /apps/{app_id}, and one entry of GET /apps.
+type App struct {
+	AppID        string    `json:"app_id"`
+	Name         string    `json:"name"`
+	Image        string    `json:"image"`
+	URL          string    `json:"url"`
+	Status       string    `json:"status"`
+	DeploymentID string    `json:"deployment_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AppStatusResponse is the status part of GET /apps/{app_id}, for polling an
+// app after POST /apps returns "deploying".
+type AppStatusResponse struct {
+	Status              string    `json:"status"`
+	URL                 string    `json:"url"`
+	CurrentDeploymentID string    `json:"current_deployment_id"`
+	UpdatedAt           time.Time `json:"updated_at"`
+	// Image is the image the app runs.
+	Image string `json:"image"`
+}
+
+// NameAvailability is the response body from GET /apps/check. A name that is
+// neither available nor owned by the caller belongs to someone else.
+type NameAvailability struct {
+	Available  bool `json:"available"`
+	OwnedByYou bool `json:"owned_by_you"`
+}
+
 // APIError describes a structured error returned by the control plane.
 type APIError struct {
 	StatusCode int
 	RemoteCode string
 	Message    string
 	Details    json.RawMessage
+	// Attempts is how many times the request was sent. It is 1 unless the
+	// client was built WithRetry.
+	Attempts int
+	// RetryAfter is the wait a 429 response asked for in its Retry-After
+	// header; zero when the header was missing or unparsable.
+	RetryAfter time.Duration
+
+	// code is set by a WithStatusMapper hook and replaces the default code.
+	code apperrors.Code
 }
 
 func (e *APIError) Error() string {
 	if e == nil {
 		return ""
 	}
+	msg := fmt.Sprintf("control plane error (%s): %s", e.RemoteCode, e.Message)
 	if e.RemoteCode == "" {
-		return fmt.Sprintf("control plane request failed with status %d: %s", e.StatusCode, e.Message)
+		msg = fmt.Sprintf("control plane request failed with status %d: %s", e.StatusCode, e.Message)
+	}
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (retry after %s)", e.RetryAfter)
 	}
-	return fmt.Sprintf("control plane error (%s): %s", e.RemoteCode, e.Message)
+	return msg
 }
 
 func (e *APIError) ErrorCode() apperrors.Code {
+	if e == nil {
+		return apperrors.CodeControlPlaneAPI
+	}
+	if e.code != "" {
+		return e.code
+	}
+	if e.StatusCode == http.StatusTooManyRequests {
+		return apperrors.CodeRateLimited
+	}
 	return apperrors.CodeControlPlaneAPI
 }
 
+// FieldError is one rejected field of a validation failure.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// FieldErrors decodes Details as per-field validation failures. Details may
+// be a list, [{"field": "name", "reason": "..."}], kept in order, or the older
+// {"fieldErrors": {"name": ["..."]}}, flattened to one FieldError per reason in
+// field order. It returns false for any other shape; Details itself is left
+// as sent.
+func (e *APIError) FieldErrors() ([]FieldError, bool) {
+	if e == nil || len(e.Details) == 0 {
+		return nil, false
+	}
+
+	var list []FieldError
+	if err := json.Unmarshal(e.Details, &list); err == nil {
+		if len(list) == 0 {
+			return nil, false
+		}
+		for _, fieldErr := range list {
+			if fieldErr.Field == "" {
+				return nil, false
+			}
+		}
+		return list, true
+	}
+
+	var details struct {
+		FieldErrors map[string][]string `json:"fieldErrors"`
+	}
+	if err := json.Unmarshal(e.Details, &details); err != nil || len(details.FieldErrors) == 0 {
+		return nil, false
+	}
+	fields := slices.Sorted(maps.Keys(details.FieldErrors))
+	for _, field := range fields {
+		for _, reason := range details.FieldErrors[field] {
+			list = append(list, FieldError{Field: field, Reason: reason})
+		}
+	}
+	return list, len(list) > 0
+}
+
 // RequestError represents transport-level failures, including timeouts.
 type RequestError struct {
 	Err       error
 	Timeout   bool
 	Operation string
+	// Attempts is how many times the request was sent. It is 1 unless the
+	// client was built WithRetry.
+	Attempts int
 }
 
 func (e *RequestError) Error() string {
@@ -118,7 +299,10 @@ func (e *RequestError) ErrorCode() apperrors.Code {
 // Option configures the control plane client.
 type Option func(*Client)
 
-// WithHTTPClient sets a custom HTTP client implementation.
+// WithHTTPClient sets a custom HTTP client implementation. It takes
+// precedence over WithClientCertificate, WithRootCAs, and WithProxy, whichever
+// order the options are given in; configure TLS and proxying on client
+// instead.
 func WithHTTPClient(client HTTPClient) Option {
 	return func(c *Client) {
 		if client != nil {
@@ -136,6 +320,93 @@ func WithRequestTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithAPIVersion sets the API version sent as Accept-Version and used to
+// check response compatibility. v must be MAJOR.MINOR.
+func WithAPIVersion(v string) Option {
+	return func(c *Client) {
+		if v = strings.TrimSpace(v); v != "" {
+			c.apiVersion = v
+		}
+	}
+}
+
+// WithLogger sets the logger used for non-fatal warnings such as API minor
+// version differences.
+func WithLogger(logger Logger) Option {
+	return func(c *Client) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// WithTokenInHeader sends the session token as "Authorization: Bearer
+// <token>" and keeps it out of every request URL, so it does not end up in
+// proxy access logs. The token is still read from the control plane URL.
+func WithTokenInHeader() Option {
+	return func(c *Client) {
+		c.tokenInHeader = true
+	}
+}
+
+// WithIdempotencyKey sends key as the Idempotency-Key of every DeployApp
+// request that does not set DeployAppRequest.IdempotencyKey. Use it with a
+// client dedicated to one logical deploy.
+func WithIdempotencyKey(key string) Option {
+	return func(c *Client) {
+		c.idempotencyKey = strings.TrimSpace(key)
+	}
+}
+
+// WithUserAgent sets the User-Agent sent on every request. An empty ua keeps
+// the default, DefaultUserAgent.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		if ua = strings.TrimSpace(ua); ua != "" {
+			c.userAgent = ua
+		}
+	}
+}
+
+// DefaultUserAgent returns "saki-tools/<version>", so control plane access
+// logs can tell saki-tools builds apart.
+func DefaultUserAgent() string {
+	return "saki-tools/" + version.Version
+}
+
+// WithStatusMapper lets integrators map error responses to internal codes
+// for control planes that use other statuses for the same condition (for
+// example 422 for validation failures). mapper sees the status and raw body
+// of every non-2xx response; a non-empty result becomes the APIError's
+// ErrorCode, and an empty one keeps the default mapping.
+func WithStatusMapper(mapper func(status int, body []byte) apperrors.Code) Option {
+	return func(c *Client) {
+		c.statusMapper = mapper
+	}
+}
+
+// WithObserver registers fn to be called after every request attempt, for
+// example to record per-operation timings. It receives the operation label
+// ("prepare app", "deploy app", ...), the HTTP status (0 when no response
+// arrived), the attempt's duration, and its error. Retried requests call fn
+// once per attempt. fn runs on the request goroutine and should not block.
+func WithObserver(fn func(op string, statusCode int, duration time.Duration, err error)) Option {
+	return func(c *Client) {
+		c.observer = fn
+	}
+}
+
+// WithBasePath mounts every endpoint under prefix, for control planes served
+// below a path such as /api/v1. The prefix is normalized to one leading slash
+// and no trailing slash, and it is not added again when the control plane URL
+// already ends with it, so https://cp and https://cp/api/v1 reach the same
+// endpoints. NewClient rejects a prefix with a query or fragment.
+func WithBasePath(prefix string) Option {
+	return func(c *Client) {
+		c.basePath = prefix
+	}
+}
+
 // NewClient creates a control plane client from a tokenized base URL.
 func NewClient(controlPlaneURL string, opts ...Option) (*Client, error) {
 	parsedURL, err := url.Parse(controlPlaneURL)
@@ -157,28 +428,160 @@ func NewClient(controlPlaneURL string, opts ...Option) (*Client, error) {
 	client := &Client{
 		baseURL:        &cleanURL,
 		token:          token,
-		httpClient:     &http.Client{},
 		requestTimeout: defaultRequestTimeout,
+		apiVersion:     APIVersion,
+		userAgent:      DefaultUserAgent(),
+		logger:         noopLogger{},
+		maxAttempts:    1,
+		jitter:         jitter.Full(nil),
 	}
 
 	for _, opt := range opts {
 		opt(client)
 	}
+	basePath, err := normalizeBasePath(client.basePath)
+	if err != nil {
+		return nil, err
+	}
+	client.basePath = basePath
+	if client.httpClient == nil {
+		httpClient, err := client.defaultHTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		client.httpClient = httpClient
+	}
 
 	return client, nil
 }
 
 // PrepareApp calls POST /apps/prepare with token forwarding.
 func (c *Client) PrepareApp(ctx context.Context, req PrepareAppRequest) (PrepareAppResponse, error) {
-	return doJSON[PrepareAppRequest, PrepareAppResponse](ctx, c, http.MethodPost, "/apps/prepare", req, "prepare app")
+	return doJSON[PrepareAppRequest, PrepareAppResponse](ctx, c, http.MethodPost, "/apps/prepare", req, "prepare app", true)
 }
 
-// DeployApp calls POST /apps with token forwarding.
+// DeployApp calls POST /apps with token forwarding and an Idempotency-Key
+// header (see DeployAppRequest.IdempotencyKey).
 func (c *Client) DeployApp(ctx context.Context, req DeployAppRequest) (DeployAppResponse, error) {
-	return doJSON[DeployAppRequest, DeployAppResponse](ctx, c, http.MethodPost, "/apps", req, "deploy app")
+	return c.sendDeploy(ctx, http.MethodPost, "/apps", "deploy app", req)
 }
 
-func doJSON[TReq any, TResp any](ctx context.Context, c *Client, method, path string, payload TReq, operation string) (TResp, error) {
+// UpdateApp calls PATCH /apps/{app_id} to deploy req to an existing app, for
+// control planes that treat POST /apps as create-only. It sends the same
+// payload and Idempotency-Key header as DeployApp and, like it, is not
+// retried by the client.
+func (c *Client) UpdateApp(ctx context.Context, appID string, req DeployAppRequest) (DeployAppResponse, error) {
+	return c.sendDeploy(ctx, http.MethodPatch, "/apps/"+url.PathEscape(appID), "update app", req)
+}
+
+// sendDeploy sends req with its Idempotency-Key header.
+func (c *Client) sendDeploy(ctx context.Context, method, path, operation string, req DeployAppRequest) (DeployAppResponse, error) {
+	key := req.IdempotencyKey
+	if key == "" {
+		key = c.idempotencyKey
+	}
+	if key == "" {
+		key = NewIdempotencyKey()
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return DeployAppResponse{}, apperrors.Wrap(apperrors.CodeInternal, "marshal "+operation+" payload", err)
+	}
+	body, err := c.doRequest(ctx, request{
+		method:      method,
+		path:        path,
+		operation:   operation,
+		body:        payload,
+		contentType: jsonContentType,
+		header:      http.Header{idempotencyKeyHeader: {key}},
+	})
+	if err != nil {
+		return DeployAppResponse{}, err
+	}
+	return decodeResponse[DeployAppResponse](body, operation)
+}
+
+// NewIdempotencyKey returns a random UUIDv4 for the Idempotency-Key header.
+func NewIdempotencyKey() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Ping calls GET /healthz to confirm the control plane is reachable and the
+// URL is well-formed.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := do[struct{}](ctx, c, http.MethodGet, "/healthz", nil, "ping", true)
+	return err
+}
+
+// GetApp calls GET /apps/{app_id} with token forwarding. The control plane
+// only serves apps by ID; use ListApps to find the ID for a name.
+func (c *Client) GetApp(ctx context.Context, appID string) (App, error) {
+	return do[App](ctx, c, http.MethodGet, "/apps/"+url.PathEscape(appID), nil, "get app", true)
+}
+
+// GetAppStatus calls GET /apps/{app_id} with token forwarding and returns the
+// app's status. Control planes that report the live deployment as
+// deployment_id (see spec/API.md) are accepted too.
+func (c *Client) GetAppStatus(ctx context.Context, appID string) (AppStatusResponse, error) {
+	type appStatusBody struct {
+		AppStatusResponse
+		DeploymentID string `json:"deployment_id"`
+	}
+
+	body, err := do[appStatusBody](ctx, c, http.MethodGet, "/apps/"+url.PathEscape(appID), nil, "get app status", true)
+	if err != nil {
+		return AppStatusResponse{}, err
+	}
+	status := body.AppStatusResponse
+	if status.CurrentDeploymentID == "" {
+		status.CurrentDeploymentID = body.DeploymentID
+	}
+	return status, nil
+}
+
+// RollbackRequest is the payload for POST /apps/{app_id}/rollback.
+type RollbackRequest struct {
+	DeploymentID string `json:"deployment_id"`
+}
+
+// RollbackDeployment calls POST /apps/{app_id}/rollback with token forwarding
+// to redeploy the image of an earlier deployment. The response describes the
+// new deployment. Like POST /apps it is never retried.
+func (c *Client) RollbackDeployment(ctx context.Context, appID, deploymentID string) (DeployAppResponse, error) {
+	return doJSON[RollbackRequest, DeployAppResponse](ctx, c, http.MethodPost, "/apps/"+url.PathEscape(appID)+"/rollback", RollbackRequest{DeploymentID: deploymentID}, "rollback deployment", false)
+}
+
+// DeleteApp calls DELETE /apps/{app_id} with token forwarding. A 200 or 204
+// means the app is gone; error envelopes decode to *APIError as usual.
+func (c *Client) DeleteApp(ctx context.Context, appID string) error {
+	_, err := do[struct{}](ctx, c, http.MethodDelete, "/apps/"+url.PathEscape(appID), nil, "delete app", true)
+	return err
+}
+
+// CheckName calls GET /apps/check to report whether name can be deployed by
+// the token's owner.
+func (c *Client) CheckName(ctx context.Context, name string) (NameAvailability, error) {
+	return do[NameAvailability](ctx, c, http.MethodGet, "/apps/check?name="+url.QueryEscape(name), nil, "check name", true)
+}
+
+// CancelDeployment calls POST /deployments/{id}/cancel to abort a rollout. A
+// 409 means the deployment already reached a terminal state and is treated as
+// success.
+func (c *Client) CancelDeployment(ctx context.Context, deploymentID string) error {
+	_, err := do[struct{}](ctx, c, http.MethodPost, "/deployments/"+url.PathEscape(deploymentID)+"/cancel", nil, "cancel deployment", true)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+		return nil
+	}
+	return err
+}
+
+func doJSON[TReq any, TResp any](ctx context.Context, c *Client, method, path string, payload TReq, operation string, idempotent bool) (TResp, error) {
 	var zero TResp
 
 	requestBody, err := json.Marshal(payload)
@@ -186,57 +589,281 @@ func doJSON[TReq any, TResp any](ctx context.Context, c *Client, method, path st
 		return zero, apperrors.Wrap(apperrors.CodeInternal, "marshal "+operation+" payload", err)
 	}
 
-	endpoint := c.endpointURL(path)
-	q := endpoint.Query()
-	q.Set("token", c.token)
-	endpoint.RawQuery = q.Encode()
+	return do[TResp](ctx, c, method, path, requestBody, operation, idempotent)
+}
+
+// do sends a request with an optional JSON body and decodes a JSON response.
+// Idempotent requests are retried as configured by WithRetry.
+func do[TResp any](ctx context.Context, c *Client, method, path string, requestBody []byte, operation string, idempotent bool) (TResp, error) {
+	var zero TResp
+
+	req := request{method: method, path: path, operation: operation, idempotent: idempotent}
+	if requestBody != nil {
+		req.body = requestBody
+		req.contentType = jsonContentType
+	}
+	body, err := c.doRequest(ctx, req)
+	if err != nil {
+		return zero, err
+	}
+	return decodeResponse[TResp](body, operation)
+}
+
+// decodeResponse decodes a JSON response body. An empty body decodes to the
+// zero value.
+func decodeResponse[TResp any](body []byte, operation string) (TResp, error) {
+	var out TResp
+	if len(bytes.TrimSpace(body)) == 0 {
+		return out, nil
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		var zero TResp
+		return zero, apperrors.Wrap(apperrors.CodeControlPlane, "decode "+operation+" response", err)
+	}
+	return out, nil
+}
+
+const jsonContentType = "application/json"
+
+// request describes one control plane call for doRequest.
+type request struct {
+	method    string
+	path      string
+	operation string
+	// body is sent as-is with contentType when non-nil.
+	body        []byte
+	contentType string
+	// accept is the Accept header; empty means JSON.
+	accept string
+	// header holds extra request headers.
+	header     http.Header
+	idempotent bool
+}
 
+// doRequest sends req with the client's token, API version, and retry
+// handling and returns the raw 2xx response body. It makes no assumption
+// about the body's format, so endpoints that are not JSON (logs, artifacts)
+// can set their own Content-Type and Accept. Error responses still decode to
+// *APIError.
+func (c *Client) doRequest(ctx context.Context, req request) ([]byte, error) {
+	attempts := 1
+	if req.idempotent {
+		attempts = max(c.maxAttempts, 1)
+	}
+
+	for attempt := 1; ; attempt++ {
+		body, err := c.doRequestOnce(ctx, req)
+		if err == nil {
+			if attempt > 1 {
+				c.logger.Info("control plane request succeeded after retry", logging.DeployFields(ctx, map[string]any{
+					"operation": req.operation,
+					"attempts":  attempt,
+				}))
+			}
+			return body, nil
+		}
+		setAttempts(err, attempt)
+
+		if attempt >= attempts || !Retryable(err) {
+			return nil, err
+		}
+		delay, ok := c.nextRetryDelay(err, attempt)
+		if !ok || !retryAllowed(ctx) || !waitRetry(ctx, delay) {
+			return nil, err
+		}
+		c.logger.Info("control plane request failed; retrying", logging.DeployFields(ctx, map[string]any{
+			"operation": req.operation,
+			"attempt":   attempt,
+			"delay":     delay.String(),
+			"error":     err.Error(),
+		}))
+	}
+}
+
+// doRequestOnce sends a single request attempt.
+func (c *Client) doRequestOnce(ctx context.Context, req request) ([]byte, error) {
 	ctxWithTimeout, cancel := withTimeout(ctx, c.requestTimeout)
 	defer cancel()
 
-	httpReq, err := http.NewRequestWithContext(ctxWithTimeout, method, endpoint.String(), bytes.NewReader(requestBody))
+	httpReq, err := c.newHTTPRequest(ctxWithTimeout, req)
 	if err != nil {
-		return zero, apperrors.Wrap(apperrors.CodeControlPlane, "build "+operation+" request", err)
+		return nil, err
 	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Accept", "application/json")
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return zero, &RequestError{Err: err, Timeout: isTimeoutError(err), Operation: operation}
+		reqErr := &RequestError{Err: err, Timeout: isTimeoutError(err), Operation: req.operation}
+		c.observe(req.operation, 0, start, reqErr)
+		return nil, reqErr
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		apiErr := decodeAPIError(resp)
-		if apiErr != nil {
-			return zero, apiErr
-		}
-		return zero, fmt.Errorf("%s failed with status %d", operation, resp.StatusCode)
+	body, err := c.readResponse(ctx, resp, req.operation)
+	c.observe(req.operation, resp.StatusCode, start, err)
+	return body, err
+}
+
+// readResponse checks resp and reads its body.
+func (c *Client) readResponse(ctx context.Context, resp *http.Response, operation string) ([]byte, error) {
+	if err := c.checkResponse(ctx, resp, operation); err != nil {
+		return nil, err
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return zero, apperrors.Wrap(apperrors.CodeControlPlane, "read "+operation+" response", err)
+		return nil, apperrors.Wrap(apperrors.CodeControlPlane, "read "+operation+" response", err)
 	}
+	return body, nil
+}
 
-	if len(bytes.TrimSpace(body)) == 0 {
-		return zero, nil
+// observe reports one attempt to the WithObserver hook, if any.
+func (c *Client) observe(operation string, statusCode int, start time.Time, err error) {
+	if c.observer != nil {
+		c.observer(operation, statusCode, time.Since(start), err)
 	}
+}
 
-	var out TResp
-	if err := json.Unmarshal(body, &out); err != nil {
-		return zero, apperrors.Wrap(apperrors.CodeControlPlane, "decode "+operation+" response", err)
+// newHTTPRequest builds req with the session token (query or header), the
+// Accept and Content-Type headers, and the API version.
+func (c *Client) newHTTPRequest(ctx context.Context, req request) (*http.Request, error) {
+	endpoint := c.endpointURL(req.path)
+	q := endpoint.Query()
+	if c.tokenInHeader {
+		q.Del("token")
+	} else {
+		q.Set("token", c.token)
 	}
+	endpoint.RawQuery = q.Encode()
 
-	return out, nil
+	var reqBody io.Reader
+	if req.body != nil {
+		reqBody = bytes.NewReader(req.body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, endpoint.String(), reqBody)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeControlPlane, "build "+req.operation+" request", err)
+	}
+	if req.body != nil && req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	accept := req.accept
+	if accept == "" {
+		accept = jsonContentType
+	}
+	httpReq.Header.Set("Accept", accept)
+	if c.tokenInHeader {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	httpReq.Header.Set(acceptVersionHeader, c.apiVersion)
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	for key, values := range req.header {
+		for _, value := range values {
+			httpReq.Header.Add(key, value)
+		}
+	}
+	return httpReq, nil
+}
+
+// checkResponse checks the server's API version and decodes a non-2xx
+// response to *APIError. It reads the body only for errors.
+func (c *Client) checkResponse(ctx context.Context, resp *http.Response, operation string) error {
+	if err := c.checkAPIVersion(ctx, resp.Header.Get(apiVersionHeader), operation); err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		apiErr := decodeAPIError(resp.StatusCode, body)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+		if c.statusMapper != nil {
+			apiErr.code = c.statusMapper(resp.StatusCode, body)
+		}
+		return apiErr
+	}
+	return nil
 }
 
+// checkAPIVersion compares the server's X-API-Version with the client's. A
+// missing header is accepted for older servers; a minor difference is logged;
+// a major difference (or an unparsable version) fails with CodeConfig.
+func (c *Client) checkAPIVersion(ctx context.Context, serverVersion, operation string) error {
+	serverVersion = strings.TrimSpace(serverVersion)
+	if serverVersion == "" || serverVersion == c.apiVersion {
+		return nil
+	}
+
+	clientMajor, clientMinor, clientOK := parseAPIVersion(c.apiVersion)
+	serverMajor, serverMinor, serverOK := parseAPIVersion(serverVersion)
+	if !clientOK || !serverOK {
+		return apperrors.New(apperrors.CodeConfig, "check control plane API version", fmt.Sprintf(
+			"cannot compare control plane API version %q with client version %q", serverVersion, c.apiVersion,
+		))
+	}
+
+	if serverMajor != clientMajor {
+		advice := "upgrade saki-tools"
+		if serverMajor < clientMajor {
+			advice = "upgrade the control plane or use an older saki-tools"
+		}
+		return apperrors.New(apperrors.CodeConfig, "check control plane API version", fmt.Sprintf(
+			"control plane API version %s is incompatible with client version %s; %s", serverVersion, c.apiVersion, advice,
+		))
+	}
+
+	if serverMinor != clientMinor {
+		c.logger.Error("control plane API minor version differs; continuing", logging.DeployFields(ctx, map[string]any{
+			"operation":      operation,
+			"server_version": serverVersion,
+			"client_version": c.apiVersion,
+		}))
+	}
+	return nil
+}
+
+// parseAPIVersion splits a MAJOR.MINOR version. A bare MAJOR is read as
+// MAJOR.0.
+func parseAPIVersion(v string) (major, minor int, ok bool) {
+	majorPart, minorPart, hasMinor := strings.Cut(strings.TrimPrefix(v, "v"), ".")
+	major, err := strconv.Atoi(majorPart)
+	if err != nil || major < 0 {
+		return 0, 0, false
+	}
+	if !hasMinor {
+		return major, 0, true
+	}
+	minor, err = strconv.Atoi(minorPart)
+	if err != nil || minor < 0 {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Info(string, map[string]any)  {}
+func (noopLogger) Error(string, map[string]any) {}
+
+// requestTimeoutKey carries a ContextWithRequestTimeout override.
+type requestTimeoutKey struct{}
+
+// ContextWithRequestTimeout returns a context whose requests use timeout
+// instead of the client's per-request timeout, for a call that needs more
+// room, such as one that may hit a control plane cold start.
+func ContextWithRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, requestTimeoutKey{}, timeout)
+}
+
+// withTimeout bounds one request attempt by timeout, or by the
+// ContextWithRequestTimeout override. An earlier deadline on ctx still wins.
 func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
-	if timeout <= 0 {
-		return ctx, func() {}
+	if override, ok := ctx.Value(requestTimeoutKey{}).(time.Duration); ok {
+		timeout = override
 	}
-	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+	if timeout <= 0 {
 		return ctx, func() {}
 	}
 	return context.WithTimeout(ctx, timeout)
@@ -251,9 +878,7 @@ func isTimeoutError(err error) bool {
 	return errors.As(err, &netErr) && netErr.Timeout()
 }
 
-func decodeAPIError(resp *http.Response) *APIError {
-	body, _ := io.ReadAll(resp.Body)
-
+func decodeAPIError(statusCode int, body []byte) *APIError {
 	type errorEnvelope struct {
 		Error struct {
 			Code    string          `json:"code"`
@@ -265,7 +890,7 @@ func decodeAPIError(resp *http.Response) *APIError {
 	var envelope errorEnvelope
 	if err := json.Unmarshal(body, &envelope); err == nil && (envelope.Error.Code != "" || envelope.Error.Message != "") {
 		return &APIError{
-			StatusCode: resp.StatusCode,
+			StatusCode: statusCode,
 			RemoteCode: envelope.Error.Code,
 			Message:    envelope.Error.Message,
 			Details:    envelope.Error.Details,
@@ -274,17 +899,64 @@ func decodeAPIError(resp *http.Response) *APIError {
 
 	message := strings.TrimSpace(string(body))
 	if message == "" {
-		message = http.StatusText(resp.StatusCode)
+		message = http.StatusText(statusCode)
 	}
 
 	return &APIError{
-		StatusCode: resp.StatusCode,
+		StatusCode: statusCode,
 		Message:    message,
 	}
 }
 
+// normalizeBasePath returns prefix as "/seg/seg", or "" for an empty or root
+// prefix.
+func normalizeBasePath(prefix string) (string, error) {
+	prefix = strings.TrimSpace(prefix)
+	if strings.ContainsAny(prefix, "?#") {
+		return "", apperrors.New(apperrors.CodeInvalidInput, "parse base path", fmt.Sprintf("base path %q must not contain a query or fragment", prefix))
+	}
+	prefix = path.Clean("/" + prefix)
+	if prefix == "/" {
+		return "", nil
+	}
+	return prefix, nil
+}
+
+// hasPathSuffix reports whether the last segments of p are the segments of
+// suffix, so a base URL that already ends in the base path is not prefixed
+// twice while "/xapi" does not count as ending in "/api".
+func hasPathSuffix(p, suffix string) bool {
+	if suffix == "" {
+		return true
+	}
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	want := strings.Split(strings.Trim(suffix, "/"), "/")
+	return len(segments) >= len(want) && slices.Equal(segments[len(segments)-len(want):], want)
+}
+
 func (c *Client) endpointURL(path string) *url.URL {
 	endpoint := *c.baseURL
-	endpoint.Path = strings.TrimRight(endpoint.Path, "/") + "/" + strings.TrimLeft(path, "/")
+	path, rawQuery, _ := strings.Cut(path, "?")
+	// Endpoint paths carry IDs already escaped with url.PathEscape, so the
+	// path is joined in escaped form and kept as RawPath; setting Path alone
+	// would escape their "%" a second time.
+	base := strings.TrimRight(endpoint.EscapedPath(), "/")
+	prefix := (&url.URL{Path: c.basePath}).EscapedPath()
+	if !hasPathSuffix(base, prefix) {
+		base += prefix
+	}
+	rawPath := base + "/" + strings.TrimLeft(path, "/")
+	endpoint.Path, endpoint.RawPath = rawPath, ""
+	if unescaped, err := url.PathUnescape(rawPath); err == nil {
+		endpoint.Path, endpoint.RawPath = unescaped, rawPath
+	}
+	if rawQuery != "" {
+		query := endpoint.Query()
+		extra, _ := url.ParseQuery(rawQuery)
+		for key, values := range extra {
+			query[key] = values
+		}
+		endpoint.RawQuery = query.Encode()
+	}
 	return &endpoint
 }
@@ -8,6 +8,9 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -47,7 +50,7 @@ func TestPrepareApp_ForwardsTokenAndPayload(t *testing.T) {
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		_, _ = io.WriteString(w, `{"repository":"registry.internal/o/my-app","push_token":"pt","expires_at":"2026-02-28T12:00:00Z","required_tag":"abcdef0"}`)
+		_, _ = io.WriteString(w, `{"repository":"registry.internal/o/my-app","push_token":"pt","expires_at":"2026-02-28T12:00:00Z","required_tag":"abcdef0","deployment_token":"dt-1"}`)
 	}))
 	defer srv.Close()
 
@@ -69,6 +72,36 @@ func TestPrepareApp_ForwardsTokenAndPayload(t *testing.T) {
 	if res.ExpiresAt.IsZero() {
 		t.Fatalf("expected expires_at to be parsed, got zero time")
 	}
+	if res.DeploymentToken != "dt-1" {
+		t.Fatalf("expected deployment_token to be parsed, got %q", res.DeploymentToken)
+	}
+}
+
+func TestWithTokenInHeader_SendsBearerToken(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-token" {
+			t.Errorf("expected bearer token header, got %q", got)
+		}
+		if strings.Contains(r.URL.RawQuery, "test-token") {
+			t.Errorf("expected no token in the request URL, got %q", r.URL.String())
+		}
+		if got := r.URL.Query().Get("org"); got != "acme" {
+			t.Errorf("expected other query parameters to be kept, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"name":"my-app"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL+"?org=acme&token=test-token", WithTokenInHeader())
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if _, err := client.GetApp(context.Background(), "my-app"); err != nil {
+		t.Fatalf("get app: %v", err)
+	}
 }
 
 func TestDeployApp_ReturnsAPIErrorEnvelope(t *testing.T) {
@@ -106,6 +139,96 @@ func TestDeployApp_ReturnsAPIErrorEnvelope(t *testing.T) {
 	}
 }
 
+func TestWithStatusMapper_OverridesDefaultCode(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusUnprocessableEntity
+		if r.URL.Path == "/apps/missing" {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, `{"error":{"code":"unprocessable","message":"bad input"}}`)
+	}))
+	defer srv.Close()
+
+	var seenBody string
+	client, err := NewClient(srv.URL+"?token=test-token", WithStatusMapper(func(status int, body []byte) apperrors.Code {
+		if status == http.StatusUnprocessableEntity {
+			seenBody = string(body)
+			return apperrors.CodeInvalidInput
+		}
+		return ""
+	}))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	_, err = client.GetApp(context.Background(), "my-app")
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected mapped code %q, got %q (%v)", apperrors.CodeInvalidInput, got, err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RemoteCode != "unprocessable" || !strings.Contains(seenBody, "bad input") {
+		t.Fatalf("expected the decoded envelope and raw body, got %v body %q", err, seenBody)
+	}
+
+	_, err = client.GetApp(context.Background(), "missing")
+	if got := apperrors.CodeOf(err); got != apperrors.CodeControlPlaneAPI {
+		t.Fatalf("expected unmapped statuses to keep %q, got %q", apperrors.CodeControlPlaneAPI, got)
+	}
+}
+
+func TestAPIError_NilReceiver(t *testing.T) {
+	var apiErr *APIError
+	if got := apiErr.Error(); got != "" {
+		t.Fatalf("expected empty message for nil error, got %q", got)
+	}
+	if got := apiErr.ErrorCode(); got != apperrors.CodeControlPlaneAPI {
+		t.Fatalf("expected %q for nil error, got %q", apperrors.CodeControlPlaneAPI, got)
+	}
+	if _, ok := apiErr.FieldErrors(); ok {
+		t.Fatal("expected no field errors for nil error")
+	}
+}
+
+func TestAPIError_FieldErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		details string
+		want    []FieldError
+	}{
+		{
+			name:    "list",
+			details: `[{"field":"name","reason":"too long"},{"field":"description","reason":"required"}]`,
+			want:    []FieldError{{Field: "name", Reason: "too long"}, {Field: "description", Reason: "required"}},
+		},
+		{
+			name:    "fieldErrors map",
+			details: `{"formErrors":[],"fieldErrors":{"name":["too long","invalid"],"description":["required"]}}`,
+			want:    []FieldError{{Field: "description", Reason: "required"}, {Field: "name", Reason: "too long"}, {Field: "name", Reason: "invalid"}},
+		},
+		{name: "other object", details: `{"expected":"owner/app"}`},
+		{name: "list without fields", details: `[{"reason":"too long"}]`},
+		{name: "list of strings", details: `["too long"]`},
+		{name: "empty list", details: `[]`},
+		{name: "no details"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiErr := &APIError{Details: json.RawMessage(tt.details)}
+			got, ok := apiErr.FieldErrors()
+			if ok != (tt.want != nil) || !slices.Equal(got, tt.want) {
+				t.Fatalf("expected %v (ok=%v), got %v (ok=%v)", tt.want, tt.want != nil, got, ok)
+			}
+			if string(apiErr.Details) != tt.details {
+				t.Fatalf("expected Details to stay raw, got %s", apiErr.Details)
+			}
+		})
+	}
+}
+
 func TestDeployApp_MapsTransportTimeout(t *testing.T) {
 	t.Parallel()
 
@@ -151,3 +274,707 @@ func (timeoutErr) Timeout() bool   { return true }
 func (timeoutErr) Temporary() bool { return false }
 
 var _ net.Error = timeoutErr{}
+
+func TestGetApp_UsesNameInPath(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Fatalf("expected GET method, got %s", r.Method)
+		}
+		if r.URL.Path != "/apps/my-app" {
+			t.Fatalf("expected /apps/my-app path, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("token"); got != "test-token" {
+			t.Fatalf("expected token query to be forwarded, got %q", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "" {
+			t.Fatalf("expected no content type on bodyless request, got %q", got)
+		}
+
+		_, _ = io.WriteString(w, `{"app_id":"app_1","name":"my-app","image":"registry.internal/o/my-app:abc","status":"running"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	app, err := client.GetApp(context.Background(), "my-app")
+	if err != nil {
+		t.Fatalf("get app: %v", err)
+	}
+	if app.AppID != "app_1" || app.Image != "registry.internal/o/my-app:abc" || app.Status != "running" {
+		t.Fatalf("unexpected app: %+v", app)
+	}
+}
+
+func TestGetAppStatus_DecodesStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "current_deployment_id", body: `{"status":"healthy","url":"https://my-app.saki.internal","current_deployment_id":"dep_2","updated_at":"2026-02-28T11:45:00Z"}`},
+		{name: "deployment_id", body: `{"app_id":"app_1","status":"healthy","url":"https://my-app.saki.internal","deployment_id":"dep_2","updated_at":"2026-02-28T11:45:00Z"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/apps/app_1" {
+					t.Errorf("expected GET /apps/app_1, got %s %s", r.Method, r.URL.Path)
+				}
+				if got := r.URL.Query().Get("token"); got != "test-token" {
+					t.Errorf("expected token query to be forwarded, got %q", got)
+				}
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			client, err := NewClient(srv.URL + "?token=test-token")
+			if err != nil {
+				t.Fatalf("new client: %v", err)
+			}
+
+			status, err := client.GetAppStatus(context.Background(), "app_1")
+			if err != nil {
+				t.Fatalf("get app status: %v", err)
+			}
+			want := AppStatusResponse{
+				Status:              "healthy",
+				URL:                 "https://my-app.saki.internal",
+				CurrentDeploymentID: "dep_2",
+				UpdatedAt:           time.Date(2026, 2, 28, 11, 45, 0, 0, time.UTC),
+			}
+			if status != want {
+				t.Fatalf("unexpected status:\ngot  %+v\nwant %+v", status, want)
+			}
+		})
+	}
+}
+
+func TestGetAppStatus_ReturnsAPIErrorEnvelope(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error":{"code":"not_found","message":"app not found"}}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	_, err = client.GetAppStatus(context.Background(), "app_1")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.RemoteCode != "not_found" {
+		t.Fatalf("expected not_found APIError, got %v", err)
+	}
+}
+
+func TestDeleteApp_SendsDelete(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantCode string
+	}{
+		{name: "no content", status: http.StatusNoContent},
+		{name: "ok with body", status: http.StatusOK, body: `{"app_id":"app_1"}`},
+		{name: "not found", status: http.StatusNotFound, body: `{"error":{"code":"not_found","message":"app not found"}}`, wantCode: "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete || r.URL.Path != "/apps/app_1" {
+					t.Errorf("expected DELETE /apps/app_1, got %s %s", r.Method, r.URL.Path)
+				}
+				if got := r.URL.Query().Get("token"); got != "test-token" {
+					t.Errorf("expected token query to be forwarded, got %q", got)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			client, err := NewClient(srv.URL + "?token=test-token")
+			if err != nil {
+				t.Fatalf("new client: %v", err)
+			}
+
+			err = client.DeleteApp(context.Background(), "app_1")
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.RemoteCode != tt.wantCode {
+				t.Fatalf("expected %s APIError, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestRollbackDeployment_PostsRollback(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method != http.MethodPost || r.URL.Path != "/apps/app_1/rollback" {
+			t.Errorf("expected POST /apps/app_1/rollback, got %s %s", r.Method, r.URL.Path)
+		}
+		var req RollbackRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.DeploymentID != "dep_1" {
+			t.Errorf("expected deployment_id dep_1 in body, got %+v (%v)", req, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"app_id":"app_1","deployment_id":"dep_3","url":"https://my-app.saki.internal","status":"deploying"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL+"?token=test-token", WithRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	res, err := client.RollbackDeployment(context.Background(), "app_1", "dep_1")
+	if err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if res.DeploymentID != "dep_3" || res.URL != "https://my-app.saki.internal" {
+		t.Fatalf("unexpected response: %+v", res)
+	}
+	if calls != 1 {
+		t.Fatalf("expected one call, got %d", calls)
+	}
+}
+
+func TestDoRequest_FetchesPlainText(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept"); got != "text/plain" {
+			t.Errorf("expected Accept text/plain, got %q", got)
+		}
+		if got := r.Header.Get(acceptVersionHeader); got == "" {
+			t.Errorf("expected %s to be sent", acceptVersionHeader)
+		}
+		if got := r.URL.Query().Get("token"); got != "test-token" {
+			t.Errorf("expected token query to be forwarded, got %q", got)
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "line one\nline two\n")
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	body, err := client.doRequest(context.Background(), request{
+		method:     http.MethodGet,
+		path:       "/deployments/dep_1/logs",
+		operation:  "get logs",
+		accept:     "text/plain",
+		idempotent: true,
+	})
+	if err != nil {
+		t.Fatalf("do request: %v", err)
+	}
+	if string(body) != "line one\nline two\n" {
+		t.Fatalf("expected the raw body, got %q", body)
+	}
+}
+
+func TestDoRequest_SendsCustomContentType(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/octet-stream" {
+			t.Errorf("expected Content-Type application/octet-stream, got %q", got)
+		}
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = io.WriteString(w, `{"error":{"code":"teapot","message":"short and stout"}}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	_, err = client.doRequest(context.Background(), request{
+		method:      http.MethodPut,
+		path:        "/artifacts/sbom",
+		operation:   "upload artifact",
+		body:        []byte{0x01, 0x02},
+		contentType: "application/octet-stream",
+	})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RemoteCode != "teapot" {
+		t.Fatalf("expected the error envelope to decode, got %v", err)
+	}
+}
+
+func TestDeployApp_SendsIdempotencyKey(t *testing.T) {
+	t.Parallel()
+
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		if _, ok := body["IdempotencyKey"]; ok {
+			t.Errorf("expected the idempotency key to stay out of the body, got %v", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"app_id":"app_1","deployment_id":"dep_1"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	pinned, err := NewClient(srv.URL+"?token=test-token", WithIdempotencyKey("client-key"))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, call := range []func() error{
+		func() error { _, err := client.DeployApp(ctx, DeployAppRequest{Name: "my-app"}); return err },
+		func() error { _, err := client.DeployApp(ctx, DeployAppRequest{Name: "my-app"}); return err },
+		func() error {
+			_, err := client.DeployApp(ctx, DeployAppRequest{Name: "my-app", IdempotencyKey: "request-key"})
+			return err
+		},
+		func() error { _, err := pinned.DeployApp(ctx, DeployAppRequest{Name: "my-app"}); return err },
+		func() error {
+			_, err := pinned.DeployApp(ctx, DeployAppRequest{Name: "my-app", IdempotencyKey: "request-key"})
+			return err
+		},
+	} {
+		if err := call(); err != nil {
+			t.Fatalf("deploy: %v", err)
+		}
+	}
+
+	uuidV4 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuidV4.MatchString(keys[0]) || !uuidV4.MatchString(keys[1]) {
+		t.Fatalf("expected generated UUIDv4 keys, got %q and %q", keys[0], keys[1])
+	}
+	if keys[0] == keys[1] {
+		t.Fatalf("expected a new key per call, got %q twice", keys[0])
+	}
+	if want := []string{"request-key", "client-key", "request-key"}; !slices.Equal(keys[2:], want) {
+		t.Fatalf("expected keys %v, got %v", want, keys[2:])
+	}
+}
+
+func TestPing_CallsHealthz(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/healthz" {
+			t.Fatalf("expected GET /healthz, got %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+}
+
+func TestCheckName_ReportsAvailability(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want NameAvailability
+	}{
+		{name: "available", body: `{"available":true,"owned_by_you":false}`, want: NameAvailability{Available: true}},
+		{name: "taken by other", body: `{"available":false,"owned_by_you":false}`, want: NameAvailability{}},
+		{name: "owned by you", body: `{"available":false,"owned_by_you":true}`, want: NameAvailability{OwnedByYou: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/api/apps/check" {
+					t.Errorf("expected GET /api/apps/check, got %s %s", r.Method, r.URL.Path)
+				}
+				if got := r.URL.Query().Get("name"); got != "my-app" {
+					t.Errorf("expected name query my-app, got %q", got)
+				}
+				if got := r.URL.Query().Get("token"); got != "test-token" {
+					t.Errorf("expected token query to be forwarded, got %q", got)
+				}
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			client, err := NewClient(srv.URL + "/api?token=test-token")
+			if err != nil {
+				t.Fatalf("new client: %v", err)
+			}
+			got, err := client.CheckName(context.Background(), "my-app")
+			if err != nil {
+				t.Fatalf("check name: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("unexpected availability: got %+v want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancelDeployment_PostsCancel(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/deployments/dep_1/cancel" {
+			t.Fatalf("expected POST /deployments/dep_1/cancel, got %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("token"); got != "test-token" {
+			t.Fatalf("expected token query to be forwarded, got %q", got)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if err := client.CancelDeployment(context.Background(), "dep_1"); err != nil {
+		t.Fatalf("cancel deployment: %v", err)
+	}
+}
+
+func TestCancelDeployment_TerminalStateIsNoOp(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/deployments/dep_gone/cancel" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `{"error":{"code":"not_found","message":"deployment not found"}}`)
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		_, _ = io.WriteString(w, `{"error":{"code":"deployment_terminal","message":"deployment already finished"}}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if err := client.CancelDeployment(context.Background(), "dep_1"); err != nil {
+		t.Fatalf("expected 409 to be a no-op, got %v", err)
+	}
+
+	err = client.CancelDeployment(context.Background(), "dep_gone")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 APIError, got %v", err)
+	}
+}
+
+func TestClient_NegotiatesAPIVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		serverVersion string
+		wantErr       bool
+		wantWarning   bool
+	}{
+		{name: "matching version", serverVersion: "2.1"},
+		{name: "missing header", serverVersion: ""},
+		{name: "minor difference warns", serverVersion: "2.3", wantWarning: true},
+		{name: "major difference errors", serverVersion: "3.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Accept-Version"); got != "2.1" {
+					t.Errorf("expected Accept-Version 2.1, got %q", got)
+				}
+				if tt.serverVersion != "" {
+					w.Header().Set("X-API-Version", tt.serverVersion)
+				}
+				_, _ = io.WriteString(w, `{"status":"ok"}`)
+			}))
+			defer srv.Close()
+
+			logger := &recordingLogger{}
+			client, err := NewClient(srv.URL+"?token=test-token", WithAPIVersion("2.1"), WithLogger(logger))
+			if err != nil {
+				t.Fatalf("new client: %v", err)
+			}
+
+			err = client.Ping(context.Background())
+			if tt.wantErr {
+				if got := apperrors.CodeOf(err); got != apperrors.CodeConfig {
+					t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeConfig, got, err)
+				}
+				if !strings.Contains(err.Error(), "upgrade saki-tools") {
+					t.Fatalf("expected upgrade advice, got %q", err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ping: %v", err)
+			}
+			if got := len(logger.errors) > 0; got != tt.wantWarning {
+				t.Fatalf("expected warning=%v, got log %v", tt.wantWarning, logger.errors)
+			}
+		})
+	}
+}
+
+type recordingLogger struct {
+	errors []string
+}
+
+func (l *recordingLogger) Info(string, map[string]any) {}
+
+func (l *recordingLogger) Error(msg string, _ map[string]any) {
+	l.errors = append(l.errors, msg)
+}
+
+func TestClient_SendsUserAgent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{name: "default", want: "saki-tools/dev"},
+		{name: "custom", opts: []Option{WithUserAgent("saki-tools/v1.4.0 (ci)")}, want: "saki-tools/v1.4.0 (ci)"},
+		{name: "empty keeps default", opts: []Option{WithUserAgent(" ")}, want: "saki-tools/dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var agents []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				agents = append(agents, r.Header.Get("User-Agent"))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, `{"name":"my-app"}`)
+			}))
+			defer srv.Close()
+
+			client, err := NewClient(srv.URL+"?token=test-token", tt.opts...)
+			if err != nil {
+				t.Fatalf("new client: %v", err)
+			}
+			if _, err := client.GetApp(context.Background(), "my-app"); err != nil {
+				t.Fatalf("get app: %v", err)
+			}
+			if _, err := client.DeployApp(context.Background(), DeployAppRequest{Name: "my-app"}); err != nil {
+				t.Fatalf("deploy app: %v", err)
+			}
+
+			for _, got := range agents {
+				if got != tt.want {
+					t.Fatalf("expected User-Agent %q on every request, got %v", tt.want, agents)
+				}
+			}
+		})
+	}
+}
+
+func TestWithBasePath_PrefixesEndpoints(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		baseURL string
+		prefix  string
+		want    string
+	}{
+		{name: "empty", baseURL: "https://cp.internal", prefix: "", want: "/apps/prepare"},
+		{name: "root", baseURL: "https://cp.internal", prefix: "/", want: "/apps/prepare"},
+		{name: "normalized", baseURL: "https://cp.internal", prefix: "/api/v1", want: "/api/v1/apps/prepare"},
+		{name: "trailing slash", baseURL: "https://cp.internal", prefix: "/api/v1/", want: "/api/v1/apps/prepare"},
+		{name: "missing leading slash", baseURL: "https://cp.internal", prefix: "api/v1", want: "/api/v1/apps/prepare"},
+		{name: "base URL already has the prefix", baseURL: "https://cp.internal/api/v1/", prefix: "api/v1", want: "/api/v1/apps/prepare"},
+		{name: "base URL has another path", baseURL: "https://cp.internal/saki", prefix: "/api/v1", want: "/saki/api/v1/apps/prepare"},
+		{name: "base URL ends with a longer segment", baseURL: "https://cp.internal/xapi", prefix: "/api", want: "/xapi/api/apps/prepare"},
+		{name: "base URL ends with part of the prefix", baseURL: "https://cp.internal/v1", prefix: "/api/v1", want: "/v1/api/v1/apps/prepare"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := NewClient(tt.baseURL+"?token=test-token", WithBasePath(tt.prefix))
+			if err != nil {
+				t.Fatalf("new client: %v", err)
+			}
+			if got := client.endpointURL("/apps/prepare").Path; got != tt.want {
+				t.Fatalf("expected path %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithBasePath_RejectsQueryAndFragment(t *testing.T) {
+	t.Parallel()
+
+	for _, prefix := range []string{"/api/v1?x=1", "/api/v1#top"} {
+		_, err := NewClient("https://cp.internal?token=test-token", WithBasePath(prefix))
+		if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+			t.Fatalf("%q: expected code %q, got %q (%v)", prefix, apperrors.CodeInvalidInput, got, err)
+		}
+	}
+}
+
+func TestEndpointURL_EscapesIDsOnce(t *testing.T) {
+	t.Parallel()
+
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"status":"healthy"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL+"/saki?token=test-token", WithBasePath("/api v1"))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if _, err := client.GetAppStatus(context.Background(), "a/b c"); err != nil {
+		t.Fatalf("get app status: %v", err)
+	}
+	if want := "/saki/api%20v1/apps/a%2Fb%20c"; gotPath != want {
+		t.Fatalf("expected path %q, got %q", want, gotPath)
+	}
+}
+
+func TestUpdateApp_SendsPatchToApp(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/apps/app_1" {
+			t.Errorf("expected PATCH /apps/app_1, got %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Idempotency-Key") != "request-key" {
+			t.Errorf("expected the request idempotency key, got %q", r.Header.Get("Idempotency-Key"))
+		}
+		var body DeployAppRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		if body.Name != "my-app" || body.Image != "registry.internal/owner/my-app:abc1234" {
+			t.Errorf("unexpected body: %+v", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"app_id":"app_1","deployment_id":"dep_2","status":"deploying"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	res, err := client.UpdateApp(context.Background(), "app_1", DeployAppRequest{
+		Name:           "my-app",
+		Image:          "registry.internal/owner/my-app:abc1234",
+		IdempotencyKey: "request-key",
+	})
+	if err != nil {
+		t.Fatalf("update app: %v", err)
+	}
+	if res.AppID != "app_1" || res.DeploymentID != "dep_2" {
+		t.Fatalf("unexpected response: %+v", res)
+	}
+}
+
+func TestRequestTimeout_AppliesUnderLongerDeadline(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"name":"my-app"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL+"?token=test-token", WithRequestTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	// A deploy-wide deadline must not replace the per-request timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	start := time.Now()
+	_, err = client.GetApp(ctx, "my-app")
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || !reqErr.Timeout {
+		t.Fatalf("expected a request timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the 50ms request timeout to apply, took %s", elapsed)
+	}
+}
+
+func TestContextWithRequestTimeout_OverridesClientTimeout(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"name":"my-app"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL+"?token=test-token", WithRequestTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	ctx := ContextWithRequestTimeout(context.Background(), 5*time.Second)
+	if _, err := client.GetApp(ctx, "my-app"); err != nil {
+		t.Fatalf("expected the longer override to let the request finish, got %v", err)
+	}
+}
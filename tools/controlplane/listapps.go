@@ -0,0 +1,108 @@
+package controlplane
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// listAllAppsPageSize is the page size ListAllApps asks for.
+const listAllAppsPageSize = 100
+
+// ListAppsRequest selects one page of GET /apps. A zero Limit leaves the page
+// size to the control plane; an empty Cursor starts from the first page.
+type ListAppsRequest struct {
+	Limit  int
+	Cursor string
+}
+
+// ListAppsResponse is one page of GET /apps. NextCursor is empty on the last
+// page.
+type ListAppsResponse struct {
+	Apps       []App  `json:"apps"`
+	NextCursor string `json:"next_cursor"`
+}
+
+// UnmarshalJSON reads the apps from "data", as spec/API.md §6.3 returns them,
+// falling back to "apps" for control planes that send that instead.
+func (r *ListAppsResponse) UnmarshalJSON(data []byte) error {
+	var body struct {
+		Data       []App  `json:"data"`
+		Apps       []App  `json:"apps"`
+		NextCursor string `json:"next_cursor"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+	r.Apps = body.Data
+	if r.Apps == nil {
+		r.Apps = body.Apps
+	}
+	r.NextCursor = body.NextCursor
+	return nil
+}
+
+// ListApps calls GET /apps with token forwarding and returns one page of the
+// caller's apps.
+func (c *Client) ListApps(ctx context.Context, req ListAppsRequest) (ListAppsResponse, error) {
+	query := url.Values{}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+	if req.Cursor != "" {
+		query.Set("cursor", req.Cursor)
+	}
+	path := "/apps"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return do[ListAppsResponse](ctx, c, http.MethodGet, path, nil, "list apps", true)
+}
+
+// ListAppsEach calls fn for every app, fetching each page only after fn has
+// returned for every app on the previous one, so at most one page is held in
+// memory. It stops at the first error from fn, a failed page, or a context
+// cancelled between pages, and returns that error.
+func (c *Client) ListAppsEach(ctx context.Context, fn func(App) error) error {
+	for app, err := range c.ListAllApps(ctx) {
+		if err != nil {
+			return err
+		}
+		if err := fn(app); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListAllApps yields every app, following next_cursor from page to page. A
+// failed page, or a context cancelled between pages, is yielded as the final
+// error.
+func (c *Client) ListAllApps(ctx context.Context) iter.Seq2[App, error] {
+	return func(yield func(App, error) bool) {
+		cursor := ""
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(App{}, err)
+				return
+			}
+			page, err := c.ListApps(ctx, ListAppsRequest{Limit: listAllAppsPageSize, Cursor: cursor})
+			if err != nil {
+				yield(App{}, err)
+				return
+			}
+			for _, app := range page.Apps {
+				if !yield(app, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" || page.NextCursor == cursor {
+				return
+			}
+			cursor = page.NextCursor
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package controlplane
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pagedAppsServer serves apps app_1..app_n in pages of size, using the index
+// of the next app as the cursor.
+func pagedAppsServer(t *testing.T, n, size int, onPage func()) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/apps" {
+			t.Errorf("expected GET /apps, got %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("token"); got != "test-token" {
+			t.Errorf("expected token query to be forwarded, got %q", got)
+		}
+		start := 0
+		if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+			_, _ = fmt.Sscanf(cursor, "c%d", &start)
+		}
+		end := min(start+size, n)
+
+		body := `{"apps":[`
+		for i := start; i < end; i++ {
+			if i > start {
+				body += ","
+			}
+			body += fmt.Sprintf(`{"app_id":"app_%d","name":"app-%d","url":"https://app-%d.saki.internal","status":"healthy","created_at":"2026-02-28T11:40:00Z"}`, i+1, i+1, i+1)
+		}
+		next := ""
+		if end < n {
+			next = fmt.Sprintf("c%d", end)
+		}
+		body += fmt.Sprintf(`],"next_cursor":%q}`, next)
+		if onPage != nil {
+			onPage()
+		}
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestListApps_ReadsSpecDataField(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"data":[{"app_id":"app_1","name":"my-app","status":"healthy","url":"https://my-app.saki.internal"}]}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	page, err := client.ListApps(context.Background(), ListAppsRequest{})
+	if err != nil {
+		t.Fatalf("list apps: %v", err)
+	}
+	if len(page.Apps) != 1 || page.Apps[0].AppID != "app_1" || page.NextCursor != "" {
+		t.Fatalf("expected one app from data, got %+v", page)
+	}
+}
+
+func TestListApps_SendsPaginationParams(t *testing.T) {
+	t.Parallel()
+
+	var gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("limit") + "|" + r.URL.Query().Get("cursor")
+		_, _ = io.WriteString(w, `{"apps":[{"app_id":"app_1","name":"my-app","url":"https://my-app.saki.internal","status":"healthy","created_at":"2026-02-28T11:40:00Z"}],"next_cursor":"c1"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	page, err := client.ListApps(context.Background(), ListAppsRequest{Limit: 10, Cursor: "c0"})
+	if err != nil {
+		t.Fatalf("list apps: %v", err)
+	}
+	if gotQuery != "10|c0" {
+		t.Fatalf("expected limit and cursor query params, got %q", gotQuery)
+	}
+	want := App{
+		AppID:     "app_1",
+		Name:      "my-app",
+		URL:       "https://my-app.saki.internal",
+		Status:    "healthy",
+		CreatedAt: time.Date(2026, 2, 28, 11, 40, 0, 0, time.UTC),
+	}
+	if len(page.Apps) != 1 || page.Apps[0] != want || page.NextCursor != "c1" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+}
+
+func TestListAllApps_FollowsCursor(t *testing.T) {
+	t.Parallel()
+
+	var pages atomic.Int32
+	srv := pagedAppsServer(t, 5, 2, func() { pages.Add(1) })
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	var ids []string
+	for app, err := range client.ListAllApps(context.Background()) {
+		if err != nil {
+			t.Fatalf("list all apps: %v", err)
+		}
+		ids = append(ids, app.AppID)
+	}
+	if len(ids) != 5 || ids[0] != "app_1" || ids[4] != "app_5" || pages.Load() != 3 {
+		t.Fatalf("expected 5 apps over 3 pages, got %v over %d pages", ids, pages.Load())
+	}
+}
+
+func TestListAllApps_StopsWhenContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	var pages atomic.Int32
+	srv := pagedAppsServer(t, 5, 2, func() { pages.Add(1) })
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ids []string
+	var lastErr error
+	for app, err := range client.ListAllApps(ctx) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		ids = append(ids, app.AppID)
+		if app.AppID == "app_2" {
+			cancel()
+		}
+	}
+	if !errors.Is(lastErr, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", lastErr)
+	}
+	if len(ids) != 2 || pages.Load() != 1 {
+		t.Fatalf("expected to stop after the first page, got %v over %d pages", ids, pages.Load())
+	}
+}
+
+func TestListAppsEach_FetchesPagesLazily(t *testing.T) {
+	t.Parallel()
+
+	var pages atomic.Int32
+	srv := pagedAppsServer(t, 5, 2, func() { pages.Add(1) })
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	fetched := map[string]int32{}
+	err = client.ListAppsEach(context.Background(), func(app App) error {
+		fetched[app.AppID] = pages.Load()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("list apps each: %v", err)
+	}
+	want := map[string]int32{"app_1": 1, "app_2": 1, "app_3": 2, "app_4": 2, "app_5": 3}
+	if fmt.Sprint(fetched) != fmt.Sprint(want) {
+		t.Fatalf("expected each page fetched only when reached, got pages seen %v", fetched)
+	}
+}
+
+func TestListAppsEach_StopsOnCallbackError(t *testing.T) {
+	t.Parallel()
+
+	var pages atomic.Int32
+	srv := pagedAppsServer(t, 5, 2, func() { pages.Add(1) })
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	errStop := errors.New("stop")
+	var ids []string
+	err = client.ListAppsEach(context.Background(), func(app App) error {
+		ids = append(ids, app.AppID)
+		if app.AppID == "app_2" {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("expected the callback error, got %v", err)
+	}
+	if len(ids) != 2 || pages.Load() != 1 {
+		t.Fatalf("expected to stop after the first page, got %v over %d pages", ids, pages.Load())
+	}
+}
@@ -0,0 +1,105 @@
+package controlplane
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const (
+	eventStreamContentType = "text/event-stream"
+
+	// logStreamBufferSize is the most StreamDeploymentLogs holds between the
+	// response body and the caller's writer.
+	logStreamBufferSize = 32 << 10
+)
+
+// StreamDeploymentLogs calls GET /deployments/{id}/logs?follow=true and
+// copies the response body to w as it arrives, until the server closes the
+// stream or ctx is done. The body (server-sent events or plain chunks) is
+// copied unparsed.
+//
+// Each chunk is written to w before the next is read, through a fixed
+// logStreamBufferSize buffer, so a slow w holds back the stream (and, through
+// TCP flow control, the server) instead of logs piling up in memory.
+//
+// The request timeout applies to gaps in the stream rather than to the whole
+// call: when no data arrives for that long, the stream is dropped with a
+// *RequestError whose Timeout is set. Time spent blocked in w.Write does not
+// count as a gap. Cancelling ctx returns ctx.Err(). Streams are never
+// retried.
+func (c *Client) StreamDeploymentLogs(ctx context.Context, deploymentID string, w io.Writer) error {
+	const operation = "stream deployment logs"
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// stalled is set when the idle timer, not the caller, cancelled the
+	// stream.
+	var stalled atomic.Bool
+	resetIdle, stopIdle := func() {}, func() {}
+	if c.requestTimeout > 0 {
+		idle := time.AfterFunc(c.requestTimeout, func() {
+			stalled.Store(true)
+			cancel()
+		})
+		defer idle.Stop()
+		resetIdle = func() { idle.Reset(c.requestTimeout) }
+		stopIdle = func() { idle.Stop() }
+	}
+	streamErr := func(err error) error {
+		if stalled.Load() {
+			return &RequestError{Err: fmt.Errorf("no log data for %s: %w", c.requestTimeout, context.DeadlineExceeded), Timeout: true, Operation: operation}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return &RequestError{Err: err, Timeout: isTimeoutError(err), Operation: operation}
+	}
+
+	httpReq, err := c.newHTTPRequest(streamCtx, request{
+		method:    http.MethodGet,
+		path:      "/deployments/" + url.PathEscape(deploymentID) + "/logs?follow=true",
+		operation: operation,
+		accept:    eventStreamContentType,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return streamErr(err)
+	}
+	defer resp.Body.Close()
+
+	if err := c.checkResponse(ctx, resp, operation); err != nil {
+		return err
+	}
+
+	buf := make([]byte, logStreamBufferSize)
+	for {
+		resetIdle()
+		n, readErr := resp.Body.Read(buf)
+		if n > 0 {
+			// A slow consumer is not a stalled stream.
+			stopIdle()
+			if _, err := w.Write(buf[:n]); err != nil {
+				return apperrors.Wrap(apperrors.CodeInternal, "write deployment logs", err)
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+		if readErr != nil {
+			return streamErr(readErr)
+		}
+	}
+}
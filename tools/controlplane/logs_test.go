@@ -0,0 +1,191 @@
+package controlplane
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStreamDeploymentLogs_CopiesStreamUntilEOF(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/deployments/dep_1/logs" {
+			t.Errorf("expected GET /deployments/dep_1/logs, got %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("follow"); got != "true" {
+			t.Errorf("expected follow=true, got %q", got)
+		}
+		if got := r.URL.Query().Get("token"); got != "test-token" {
+			t.Errorf("expected token query to be forwarded, got %q", got)
+		}
+		if got := r.Header.Get("Accept"); got != "text/event-stream" {
+			t.Errorf("expected Accept text/event-stream, got %q", got)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, line := range []string{"data: starting\n\n", "data: listening on :3000\n\n"} {
+			_, _ = io.WriteString(w, line)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := client.StreamDeploymentLogs(context.Background(), "dep_1", &out); err != nil {
+		t.Fatalf("stream logs: %v", err)
+	}
+	if want := "data: starting\n\ndata: listening on :3000\n\n"; out.String() != want {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+}
+
+func TestStreamDeploymentLogs_TimesOutWhenStreamStalls(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "data: starting\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	client, err := NewClient(srv.URL+"?token=test-token", WithRequestTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	var out bytes.Buffer
+	err = client.StreamDeploymentLogs(context.Background(), "dep_1", &out)
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || !reqErr.Timeout {
+		t.Fatalf("expected a timed-out RequestError, got %v", err)
+	}
+	if out.String() != "data: starting\n\n" {
+		t.Fatalf("expected the data before the stall to be copied, got %q", out.String())
+	}
+}
+
+func TestStreamDeploymentLogs_KeepsStreamingPastRequestTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		for range 5 {
+			_, _ = io.WriteString(w, "data: tick\n\n")
+			w.(http.Flusher).Flush()
+			time.Sleep(40 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL+"?token=test-token", WithRequestTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := client.StreamDeploymentLogs(context.Background(), "dep_1", &out); err != nil {
+		t.Fatalf("expected a steady stream to outlive the request timeout, got %v", err)
+	}
+	if got := bytes.Count(out.Bytes(), []byte("tick")); got != 5 {
+		t.Fatalf("expected 5 events, got %d", got)
+	}
+}
+
+// slowWriter blocks for delay on each of its first slowWrites writes and
+// records the largest write it saw.
+type slowWriter struct {
+	delay      time.Duration
+	slowWrites int
+	writes     int
+	maxWrite   int
+	total      int
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes <= w.slowWrites {
+		time.Sleep(w.delay)
+	}
+	w.maxWrite = max(w.maxWrite, len(p))
+	w.total += len(p)
+	return len(p), nil
+}
+
+func TestStreamDeploymentLogs_AppliesBackpressureToSlowWriter(t *testing.T) {
+	const chunk, chunks = 16 << 10, 16
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		line := bytes.Repeat([]byte("x"), chunk)
+		for range chunks {
+			_, _ = w.Write(line)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL+"?token=test-token", WithRequestTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	// Each slow write outlasts the request timeout; blocking in the writer
+	// must not count as a stalled stream.
+	out := &slowWriter{delay: 120 * time.Millisecond, slowWrites: 3}
+	if err := client.StreamDeploymentLogs(context.Background(), "dep_1", out); err != nil {
+		t.Fatalf("expected a slow writer to hold back the stream, got %v", err)
+	}
+	if out.total != chunk*chunks {
+		t.Fatalf("expected %d bytes, got %d", chunk*chunks, out.total)
+	}
+	if out.maxWrite > logStreamBufferSize {
+		t.Fatalf("expected writes of at most %d bytes, got %d", logStreamBufferSize, out.maxWrite)
+	}
+}
+
+func TestStreamDeploymentLogs_StopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "data: starting\n\n")
+		w.(http.Flusher).Flush()
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL+"?token=test-token", WithRequestTimeout(time.Minute))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	err = client.StreamDeploymentLogs(ctx, "dep_1", io.Discard)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestStreamDeploymentLogs_DecodesErrorEnvelope(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error":{"code":"not_found","message":"deployment not found"}}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	err = client.StreamDeploymentLogs(context.Background(), "dep_1", io.Discard)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RemoteCode != "not_found" {
+		t.Fatalf("expected not_found APIError, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package controlplane
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRetryDelay caps a single backoff wait.
+const maxRetryDelay = 30 * time.Second
+
+// WithRetry retries idempotent requests (GET requests, prepare, and cancel;
+// never POST /apps) up to maxAttempts times in total when the control plane
+// answers 5xx or 429 or the request times out. Waits grow exponentially from
+// baseDelay with full jitter, except that a 429 with a Retry-After header
+// waits exactly as long as the header asks. Retrying stops early when the
+// context is cancelled or its deadline would pass during the wait, or when a
+// ContextWithRetryGate gate refuses. Other 4xx responses are caller errors
+// and are never retried.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		if maxAttempts > 0 {
+			c.maxAttempts = maxAttempts
+		}
+		if baseDelay > 0 {
+			c.retryBaseDelay = baseDelay
+		}
+	}
+}
+
+type retryGateKey struct{}
+
+// retryGate wraps the ContextWithRetryGate func so a nil func can be told
+// apart from no gate at all.
+type retryGate struct {
+	allow func() bool
+}
+
+// ContextWithRetryGate returns a context whose requests are only retried
+// when allow returns true; it is called once before each retry, so callers
+// can charge client retries to their own retry budget. A nil allow disables
+// client retries, for calls the caller already retries itself.
+func ContextWithRetryGate(ctx context.Context, allow func() bool) context.Context {
+	return context.WithValue(ctx, retryGateKey{}, retryGate{allow: allow})
+}
+
+// retryAllowed consults the ContextWithRetryGate gate on ctx, if any.
+func retryAllowed(ctx context.Context) bool {
+	gate, ok := ctx.Value(retryGateKey{}).(retryGate)
+	if !ok {
+		return true
+	}
+	return gate.allow != nil && gate.allow()
+}
+
+// Retryable reports whether err may be transient: a 5xx or 429 answer or a
+// timed-out request. These are the failures WithRetry retries.
+func Retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	var reqErr *RequestError
+	return errors.As(err, &reqErr) && reqErr.Timeout
+}
+
+// setAttempts records the attempt count on the client's error types.
+func setAttempts(err error, attempts int) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		apiErr.Attempts = attempts
+		return
+	}
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		reqErr.Attempts = attempts
+	}
+}
+
+// nextRetryDelay is the wait before the attempt after attempt: the 429
+// Retry-After when the server sent one, otherwise jittered backoff. It
+// reports false when Retry-After asks for more than maxRetryDelay, so the
+// caller gives up instead of blocking that long.
+func (c *Client) nextRetryDelay(err error, attempt int) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, apiErr.RetryAfter <= maxRetryDelay
+	}
+	return c.jitter(backoffDelay(c.retryBaseDelay, attempt)), true
+}
+
+// parseRetryAfter reads a Retry-After header in either delta-seconds or
+// HTTP-date form. It returns zero when value is empty, malformed, or a date
+// not after now.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now).Round(time.Second), 0)
+	}
+	return 0
+}
+
+// backoffDelay is base doubled for every attempt already made, capped at
+// maxRetryDelay.
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// waitRetry sleeps for delay. It returns false without waiting when ctx is
+// already done or its deadline falls within delay, and false when ctx is
+// cancelled during the wait.
+func waitRetry(ctx context.Context, delay time.Duration) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+		return false
+	}
+	if delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
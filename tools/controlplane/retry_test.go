@@ -0,0 +1,357 @@
+package controlplane
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// flakyServer answers the first failures requests with status, then 200.
+func flakyServer(t *testing.T, failures int, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if int(calls.Add(1)) <= failures {
+			w.WriteHeader(status)
+			_, _ = io.WriteString(w, `{"error":{"code":"unavailable","message":"restarting"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"name":"my-app"}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestWithRetry_RetriesServerErrors(t *testing.T) {
+	srv, calls := flakyServer(t, 2, http.StatusBadGateway)
+	client, err := NewClient(srv.URL+"?token=test-token", WithRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	app, err := client.GetApp(context.Background(), "my-app")
+	if err != nil {
+		t.Fatalf("expected retries to succeed, got %v", err)
+	}
+	if app.Name != "my-app" || calls.Load() != 3 {
+		t.Fatalf("expected success on the third attempt, got %+v after %d calls", app, calls.Load())
+	}
+}
+
+func TestWithRetry_ReportsAttemptsWhenExhausted(t *testing.T) {
+	srv, calls := flakyServer(t, 5, http.StatusServiceUnavailable)
+	client, err := NewClient(srv.URL+"?token=test-token", WithRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	_, err = client.GetApp(context.Background(), "my-app")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the last 503, got %v", err)
+	}
+	if apiErr.Attempts != 3 || calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got Attempts=%d calls=%d", apiErr.Attempts, calls.Load())
+	}
+}
+
+func TestContextWithRetryGate_LimitsRetries(t *testing.T) {
+	srv, calls := flakyServer(t, 5, http.StatusServiceUnavailable)
+	client, err := NewClient(srv.URL+"?token=test-token", WithRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	allowed := 1
+	ctx := ContextWithRetryGate(context.Background(), func() bool {
+		allowed--
+		return allowed >= 0
+	})
+	if _, err := client.GetApp(ctx, "app_123"); err == nil {
+		t.Fatal("expected the 503 to be returned")
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected the gate to allow one retry, got %d calls", calls.Load())
+	}
+
+	calls.Store(0)
+	if _, err := client.GetApp(ContextWithRetryGate(context.Background(), nil), "app_123"); err == nil {
+		t.Fatal("expected the 503 to be returned")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected a nil gate to disable retries, got %d calls", calls.Load())
+	}
+}
+
+func TestWithRetry_DoesNotRetryClientErrors(t *testing.T) {
+	srv, calls := flakyServer(t, 5, http.StatusNotFound)
+	client, err := NewClient(srv.URL+"?token=test-token", WithRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	_, err = client.GetApp(context.Background(), "my-app")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Attempts != 1 || calls.Load() != 1 {
+		t.Fatalf("expected a single attempt for a 404, got %v after %d calls", err, calls.Load())
+	}
+}
+
+func TestWithRetry_DoesNotRetryDeploy(t *testing.T) {
+	srv, calls := flakyServer(t, 5, http.StatusBadGateway)
+	client, err := NewClient(srv.URL+"?token=test-token", WithRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	if _, err := client.DeployApp(context.Background(), DeployAppRequest{Name: "my-app"}); err == nil {
+		t.Fatal("expected deploy error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected POST /apps to be sent once, got %d calls", calls.Load())
+	}
+}
+
+func TestWithRetry_DoesNotRetryRollback(t *testing.T) {
+	srv, calls := flakyServer(t, 5, http.StatusBadGateway)
+	client, err := NewClient(srv.URL+"?token=test-token", WithRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	if _, err := client.RollbackDeployment(context.Background(), "app_1", "dep_1"); err == nil {
+		t.Fatal("expected rollback error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected the rollback to be sent once, got %d calls", calls.Load())
+	}
+}
+
+func TestWithRetry_RetriesTimeouts(t *testing.T) {
+	doer := &countingTimeoutClient{}
+	client, err := NewClient("https://cp.internal?token=test-token", WithHTTPClient(doer), WithRetry(2, time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	_, err = client.PrepareApp(context.Background(), PrepareAppRequest{Name: "my-app", GitCommit: "abc"})
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) || !reqErr.Timeout || reqErr.Attempts != 2 || doer.calls != 2 {
+		t.Fatalf("expected two timed-out attempts, got %v after %d calls", err, doer.calls)
+	}
+}
+
+func TestWithRetry_StopsWhenContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		cancel()
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL+"?token=test-token", WithRetry(5, time.Hour))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	if _, err := client.GetApp(ctx, "my-app"); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected no retry after cancellation, got %d calls", calls.Load())
+	}
+}
+
+func TestWithRetry_HonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"name":"my-app"}`)
+	}))
+	defer srv.Close()
+
+	// The backoff base is an hour, so a prompt success proves Retry-After
+	// was used instead.
+	client, err := NewClient(srv.URL+"?token=test-token", WithRetry(2, time.Hour))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := client.GetApp(context.Background(), "my-app"); err != nil {
+		t.Fatalf("expected retry after 429 to succeed, got %v", err)
+	}
+	if waited := time.Since(start); waited < time.Second || waited > 10*time.Second {
+		t.Fatalf("expected to wait about 1s, waited %s", waited)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls.Load())
+	}
+}
+
+func TestWithRetry_RateLimitedFallsBackToBackoff(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusTooManyRequests)
+	client, err := NewClient(srv.URL+"?token=test-token", WithRetry(2, time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	if _, err := client.GetApp(context.Background(), "my-app"); err != nil || calls.Load() != 2 {
+		t.Fatalf("expected a backoff retry without Retry-After, got %v after %d calls", err, calls.Load())
+	}
+}
+
+func TestWithRetry_RateLimitedErrorReportsWait(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = io.WriteString(w, `{"error":{"code":"rate_limited","message":"slow down"}}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL+"?token=test-token", WithRetry(3, time.Millisecond))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	_, err = client.GetApp(context.Background(), "my-app")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != time.Hour {
+		t.Fatalf("expected a 429 carrying its Retry-After, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected no retry for a wait beyond the cap, got %d calls", calls.Load())
+	}
+	if got := apperrors.CodeOf(err); got != apperrors.CodeRateLimited {
+		t.Fatalf("expected code %q, got %q", apperrors.CodeRateLimited, got)
+	}
+	if !strings.Contains(err.Error(), "retry after 1h0m0s") {
+		t.Fatalf("expected the wait in the error message, got %q", err.Error())
+	}
+}
+
+type observedAttempt struct {
+	op         string
+	statusCode int
+	failed     bool
+}
+
+func TestWithObserver_ReportsEachAttempt(t *testing.T) {
+	srv, _ := flakyServer(t, 2, http.StatusBadGateway)
+	var observed []observedAttempt
+	client, err := NewClient(srv.URL+"?token=test-token",
+		WithRetry(3, time.Millisecond),
+		WithObserver(func(op string, statusCode int, duration time.Duration, err error) {
+			if duration <= 0 {
+				t.Errorf("expected a positive duration, got %s", duration)
+			}
+			observed = append(observed, observedAttempt{op: op, statusCode: statusCode, failed: err != nil})
+		}),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	if _, err := client.GetApp(context.Background(), "my-app"); err != nil {
+		t.Fatalf("expected retries to succeed, got %v", err)
+	}
+	want := []observedAttempt{
+		{op: "get app", statusCode: http.StatusBadGateway, failed: true},
+		{op: "get app", statusCode: http.StatusBadGateway, failed: true},
+		{op: "get app", statusCode: http.StatusOK},
+	}
+	if !slices.Equal(observed, want) {
+		t.Fatalf("expected attempts %+v, got %+v", want, observed)
+	}
+}
+
+func TestWithObserver_ReportsZeroStatusOnTransportError(t *testing.T) {
+	var observed []observedAttempt
+	client, err := NewClient("https://cp.internal?token=test-token",
+		WithHTTPClient(&countingTimeoutClient{}),
+		WithRetry(2, time.Millisecond),
+		WithObserver(func(op string, statusCode int, _ time.Duration, err error) {
+			observed = append(observed, observedAttempt{op: op, statusCode: statusCode, failed: err != nil})
+		}),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	if _, err := client.PrepareApp(context.Background(), PrepareAppRequest{Name: "my-app", GitCommit: "abc"}); err == nil {
+		t.Fatal("expected prepare error")
+	}
+	want := []observedAttempt{
+		{op: "prepare app", failed: true},
+		{op: "prepare app", failed: true},
+	}
+	if !slices.Equal(observed, want) {
+		t.Fatalf("expected attempts %+v, got %+v", want, observed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "120", want: 2 * time.Minute},
+		{value: " 0 ", want: 0},
+		{value: "Sun, 01 Mar 2026 12:00:30 GMT", want: 30 * time.Second},
+		{value: "Sun, 01 Mar 2026 11:59:00 GMT", want: 0},
+		{value: "-5", want: 0},
+		{value: "soon", want: 0},
+		{value: "", want: 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Fatalf("%q: expected %s, got %s", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 4, want: 800 * time.Millisecond},
+		{attempt: 20, want: maxRetryDelay},
+	}
+	for _, tt := range tests {
+		if got := backoffDelay(100*time.Millisecond, tt.attempt); got != tt.want {
+			t.Fatalf("attempt %d: expected %s, got %s", tt.attempt, tt.want, got)
+		}
+	}
+}
+
+type countingTimeoutClient struct {
+	calls int
+}
+
+func (c *countingTimeoutClient) Do(*http.Request) (*http.Response, error) {
+	c.calls++
+	return nil, timeoutErr{}
+}
@@ -0,0 +1,75 @@
+package controlplane
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// proxyFromEnvironment picks the proxy when WithProxy is not set; a variable
+// so tests can observe it being consulted.
+var proxyFromEnvironment = http.ProxyFromEnvironment
+
+// WithProxy sends every request through the proxy at proxyURL (for example
+// http://proxy.corp:3128) instead of the one HTTPS_PROXY, HTTP_PROXY, and
+// NO_PROXY select. NewClient fails with CodeInvalidInput when proxyURL is not
+// an absolute URL. An empty proxyURL keeps the environment proxy. Like the
+// TLS options, it is ignored when WithHTTPClient is set.
+func WithProxy(proxyURL string) Option {
+	return func(c *Client) {
+		c.proxyURL = strings.TrimSpace(proxyURL)
+	}
+}
+
+// WithClientCertificate presents cert to control planes that require mutual
+// TLS. The client then uses its own http.Transport, cloned from
+// http.DefaultTransport, with cert in its TLS config. WithHTTPClient takes
+// precedence: when both are set, in any order, the WithHTTPClient client is
+// used as is and cert is ignored. WithRequestTimeout applies either way, since
+// timeouts are set per request.
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(c *Client) {
+		c.clientCerts = append(c.clientCerts, cert)
+	}
+}
+
+// WithRootCAs verifies the control plane's server certificate against pool
+// instead of the system roots, for control planes behind an internal CA. It
+// builds the same transport as WithClientCertificate, and WithHTTPClient takes
+// precedence over it in the same way.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(c *Client) {
+		if pool != nil {
+			c.rootCAs = pool
+		}
+	}
+}
+
+// defaultHTTPClient builds the client used without WithHTTPClient: a
+// transport cloned from http.DefaultTransport that always takes its proxy from
+// WithProxy or, without it, from the environment, with TLS set up when a
+// client certificate or root CAs were configured.
+func (c *Client) defaultHTTPClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxyFromEnvironment
+	if c.proxyURL != "" {
+		proxy, err := url.Parse(c.proxyURL)
+		if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			return nil, apperrors.New(apperrors.CodeInvalidInput, "parse proxy URL", fmt.Sprintf("proxy URL %q must be absolute, like http://proxy.internal:3128", c.proxyURL))
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if len(c.clientCerts) > 0 || c.rootCAs != nil {
+		transport.TLSClientConfig = &tls.Config{
+			Certificates: c.clientCerts,
+			RootCAs:      c.rootCAs,
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,178 @@
+package controlplane
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// mtlsServer starts a TLS server that requires a client certificate signed
+// by itself, and returns it with that certificate and a pool trusting the
+// server.
+func mtlsServer(t *testing.T) (*httptest.Server, tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	clientCert, clientLeaf := selfSignedCert(t)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"name":"my-app"}`)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientLeaf)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	return srv, clientCert, roots
+}
+
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "saki-tools"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, leaf
+}
+
+func TestWithClientCertificate_PresentsCertificate(t *testing.T) {
+	srv, cert, roots := mtlsServer(t)
+
+	client, err := NewClient(srv.URL+"?token=test-token", WithClientCertificate(cert), WithRootCAs(roots), WithRequestTimeout(5*time.Second))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if _, err := client.GetApp(context.Background(), "my-app"); err != nil {
+		t.Fatalf("expected the mTLS request to succeed, got %v", err)
+	}
+}
+
+func TestWithRootCAs_WithoutClientCertificateIsRejected(t *testing.T) {
+	srv, _, roots := mtlsServer(t)
+
+	client, err := NewClient(srv.URL+"?token=test-token", WithRootCAs(roots))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	_, err = client.GetApp(context.Background(), "my-app")
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("expected a request error without a client certificate, got %v", err)
+	}
+}
+
+func TestWithHTTPClient_TakesPrecedenceOverTransportOptions(t *testing.T) {
+	srv, cert, roots := mtlsServer(t)
+
+	for _, opts := range [][]Option{
+		{WithHTTPClient(&countingTimeoutClient{}), WithClientCertificate(cert), WithRootCAs(roots), WithProxy("http://proxy.internal:3128")},
+		{WithClientCertificate(cert), WithRootCAs(roots), WithProxy("http://proxy.internal:3128"), WithHTTPClient(&countingTimeoutClient{})},
+	} {
+		client, err := NewClient(srv.URL+"?token=test-token", opts...)
+		if err != nil {
+			t.Fatalf("new client: %v", err)
+		}
+		doer, ok := client.httpClient.(*countingTimeoutClient)
+		if !ok {
+			t.Fatalf("expected the WithHTTPClient client, got %T", client.httpClient)
+		}
+		_, _ = client.GetApp(context.Background(), "my-app")
+		if doer.calls != 1 {
+			t.Fatalf("expected the request to go through the custom client, got %d calls", doer.calls)
+		}
+	}
+}
+
+func TestNewClient_UsesEnvironmentProxyByDefault(t *testing.T) {
+	var consulted []string
+	original := proxyFromEnvironment
+	proxyFromEnvironment = func(r *http.Request) (*url.URL, error) {
+		consulted = append(consulted, r.URL.Host)
+		return nil, nil
+	}
+	t.Cleanup(func() { proxyFromEnvironment = original })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"name":"my-app"}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if _, err := client.GetApp(context.Background(), "my-app"); err != nil {
+		t.Fatalf("get app: %v", err)
+	}
+	if host := strings.TrimPrefix(srv.URL, "http://"); len(consulted) != 1 || consulted[0] != host {
+		t.Fatalf("expected the environment proxy to be consulted for %s, got %v", host, consulted)
+	}
+}
+
+func TestWithProxy_SendsRequestsThroughProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute target URL.
+		proxied = append(proxied, r.URL.String())
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"name":"my-app"}`)
+	}))
+	defer proxy.Close()
+
+	client, err := NewClient("http://cp.internal?token=test-token", WithProxy(proxy.URL))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if _, err := client.GetApp(context.Background(), "my-app"); err != nil {
+		t.Fatalf("expected the proxy to answer, got %v", err)
+	}
+	if len(proxied) != 1 || !strings.HasPrefix(proxied[0], "http://cp.internal/apps/my-app") {
+		t.Fatalf("expected the request to go through the proxy, got %v", proxied)
+	}
+}
+
+func TestWithProxy_RejectsRelativeURL(t *testing.T) {
+	_, err := NewClient("https://cp.internal?token=test-token", WithProxy("proxy.corp:3128"))
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected %q, got %v", apperrors.CodeInvalidInput, err)
+	}
+}
@@ -3,14 +3,19 @@ package docker
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/url"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/logging"
 )
 
 // Logger receives structured log events from the Docker adapter.
@@ -30,6 +35,14 @@ type CommandRequest struct {
 	Args  []string
 	Dir   string
 	Stdin string
+	// Stdout, when set, receives stdout as the command produces it. The
+	// output is still captured in CommandResult.Stdout.
+	Stdout io.Writer
+	// Env holds KEY=value pairs added to the command's environment.
+	Env []string
+	// Redact lists values replaced by "<redacted>" in the stderr kept on
+	// errors and logs. Runners ignore it.
+	Redact []string
 }
 
 // CommandResult captures command output and exit information.
@@ -39,10 +52,17 @@ type CommandResult struct {
 	ExitCode int
 }
 
+// DefaultStderrTailLines is how many trailing stderr lines a CommandError
+// keeps unless SetStderrTailLines overrides it.
+const DefaultStderrTailLines = 40
+
+const stderrTruncatedMarker = "... (truncated, see logs)"
+
 // Adapter wraps Docker CLI actions used by the deploy flow.
 type Adapter struct {
-	runner CommandRunner
-	logger Logger
+	runner          CommandRunner
+	logger          Logger
+	stderrTailLines int
 }
 
 // CommandError is a structured error from a failed Docker command.
@@ -54,14 +74,69 @@ type CommandError struct {
 	Err      error
 }
 
+// stderrClass recognizes a docker failure that is not caused by the app
+// itself from its stderr and says what to do about it.
+type stderrClass struct {
+	patterns []string
+	code     apperrors.Code
+	advice   string
+}
+
+var stderrClasses = []stderrClass{
+	{
+		patterns: []string{"no space left on device", "failed to register layer"},
+		code:     apperrors.CodeDiskFull,
+		advice:   "the docker host is out of disk space; free space (for example with `docker system prune`) and retry",
+	},
+	{
+		patterns: []string{"no builder"},
+		code:     apperrors.CodeConfig,
+		advice:   "the buildx builder does not exist; create it with `docker buildx create --name <builder>` or change SAKI_BUILDX_BUILDER",
+	},
+	{
+		patterns: []string{"cannot connect to the docker daemon", "is the docker daemon running"},
+		code:     apperrors.CodeConfig,
+		advice:   "the docker daemon is not running; start Docker (for example Docker Desktop or `sudo systemctl start docker`) and retry",
+	},
+}
+
+func (e *CommandError) class() *stderrClass {
+	if e == nil || e.Stderr == "" {
+		return nil
+	}
+	stderr := strings.ToLower(e.Stderr)
+	for i := range stderrClasses {
+		for _, pattern := range stderrClasses[i].patterns {
+			if strings.Contains(stderr, pattern) {
+				return &stderrClasses[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Advice returns the recommended fix for a recognized environment failure
+// (such as a full disk or a stopped daemon), or "" when the stderr does not
+// match one.
+func (e *CommandError) Advice() string {
+	if class := e.class(); class != nil {
+		return class.advice
+	}
+	return ""
+}
+
 func (e *CommandError) Error() string {
 	if e == nil {
 		return "<nil>"
 	}
+	msg := fmt.Sprintf("docker %s failed: %v", e.Op, e.Err)
 	if e.ExitCode >= 0 {
-		return fmt.Sprintf("docker %s failed (exit=%d): %v", e.Op, e.ExitCode, e.Err)
+		msg = fmt.Sprintf("docker %s failed (exit=%d): %v", e.Op, e.ExitCode, e.Err)
 	}
-	return fmt.Sprintf("docker %s failed: %v", e.Op, e.Err)
+	if advice := e.Advice(); advice != "" {
+		msg += "; " + advice
+	}
+	return msg
 }
 
 func (e *CommandError) Unwrap() error {
@@ -75,6 +150,9 @@ func (e *CommandError) ErrorCode() apperrors.Code {
 	if e != nil && errors.Is(e.Err, context.DeadlineExceeded) {
 		return apperrors.CodeTimeout
 	}
+	if class := e.class(); class != nil {
+		return class.code
+	}
 	return apperrors.CodeDocker
 }
 
@@ -87,7 +165,16 @@ func NewAdapter(logger Logger, runner CommandRunner) *Adapter {
 		runner = execRunner{}
 	}
 
-	return &Adapter{runner: runner, logger: logger}
+	return &Adapter{runner: runner, logger: logger, stderrTailLines: DefaultStderrTailLines}
+}
+
+// SetStderrTailLines limits CommandError.Stderr to the last n lines. Values
+// below 1 restore DefaultStderrTailLines. The full stderr is always logged.
+func (a *Adapter) SetStderrTailLines(n int) {
+	if n < 1 {
+		n = DefaultStderrTailLines
+	}
+	a.stderrTailLines = n
 }
 
 // Login runs `docker login` using stdin for the password.
@@ -104,51 +191,373 @@ func (a *Adapter) Login(ctx context.Context, registry, username, password string
 	})
 }
 
-// Build runs `docker build -t <image> .` in workDir.
-func (a *Adapter) Build(ctx context.Context, workDir, image string) error {
-	return a.run(ctx, "build", CommandRequest{
-		Name: "docker",
-		Args: []string{"build", "-t", image, "."},
-		Dir:  workDir,
+// BuildOptions customizes the image build.
+type BuildOptions struct {
+	// Platforms lists target platforms such as linux/amd64. More than one
+	// platform switches to `docker buildx build --push`, which pushes the
+	// multi-arch manifest as part of the build.
+	Platforms []string
+	// Labels are applied with --label, in key order.
+	Labels map[string]string
+	// CPUQuota and Memory constrain the build container with --cpu-quota
+	// (microseconds per 100ms period) and --memory (such as 512m or 2g). Only
+	// the single-platform `docker build` path accepts them; buildx builds
+	// ignore them.
+	CPUQuota string
+	Memory   string
+	// Builder pins multi-platform builds to a named buildx builder instance
+	// with --builder. Empty uses the current builder.
+	Builder string
+	// CacheStats, when set, switches to `--progress=plain` and receives the
+	// BuildKit cache-hit summary after a successful build. It is not called
+	// when the output holds no recognizable steps.
+	CacheStats func(BuildCacheStats)
+	// ExtraBuildArgs are appended verbatim after the modeled flags, just
+	// before the build context, for flags without first-class support such as
+	// --add-host or --shm-size. Advanced and unsafe: they are not validated
+	// and can change what is built or pushed. Elements containing secret
+	// markers (token=, password=, ...) are redacted from logs.
+	ExtraBuildArgs []string
+	// BuildArgs are passed as `--build-arg KEY`, in key order, with each
+	// value in the docker process environment, so values stay out of the
+	// command line and logs. Values that look secret are also redacted from
+	// build output kept on errors.
+	BuildArgs map[string]string
+}
+
+// PushesOnBuild reports whether the build also pushes the image, so a
+// separate Push call must be skipped.
+func (o BuildOptions) PushesOnBuild() bool {
+	return len(o.Platforms) > 1
+}
+
+// Build runs `docker build -t <image> .` in workDir, or
+// `docker buildx build --platform <list> -t <image> --push .` for multi-arch builds,
+// after checking that buildx and the pinned builder are available.
+func (a *Adapter) Build(ctx context.Context, workDir, image string, opts BuildOptions) error {
+	if opts.PushesOnBuild() {
+		if err := a.checkBuildx(ctx, opts.Builder); err != nil {
+			return err
+		}
+	}
+
+	res, err := a.runResult(ctx, "build", CommandRequest{
+		Name:   "docker",
+		Args:   buildArgs(image, opts),
+		Dir:    workDir,
+		Env:    buildArgEnv(opts.BuildArgs),
+		Redact: secretBuildArgValues(opts.BuildArgs),
 	})
+	if err != nil || opts.CacheStats == nil {
+		return err
+	}
+
+	// BuildKit writes plain progress to stderr.
+	if stats, ok := parseBuildCacheStats(res.Stderr); ok {
+		opts.CacheStats(stats)
+	}
+	return nil
+}
+
+// checkBuildx fails with CodeConfig before a multi-platform build when the
+// buildx plugin is missing, cannot list builders, or does not know builder
+// (when one is pinned). Otherwise those surface as an obscure build failure.
+func (a *Adapter) checkBuildx(ctx context.Context, builder string) error {
+	const op = "check buildx"
+
+	if err := a.run(ctx, op, CommandRequest{Name: "docker", Args: []string{"buildx", "version"}}); err != nil {
+		return apperrors.Wrap(apperrors.CodeConfig, op, fmt.Errorf(
+			"multi-platform builds need docker buildx, which is not available; install the buildx plugin (included with Docker Desktop, or the docker-buildx-plugin package) or build a single platform: %w", err,
+		))
+	}
+
+	res, err := a.runResult(ctx, op, CommandRequest{Name: "docker", Args: []string{"buildx", "ls"}})
+	if err != nil {
+		return apperrors.Wrap(apperrors.CodeConfig, op, fmt.Errorf(
+			"docker buildx cannot list builders; create one with `docker buildx create --use` and retry: %w", err,
+		))
+	}
+
+	builder = strings.TrimSpace(builder)
+	if builder != "" && !slices.Contains(buildxBuilderNames(res.Stdout), builder) {
+		return apperrors.New(apperrors.CodeConfig, op, fmt.Sprintf(
+			"buildx builder %q does not exist; create it with `docker buildx create --name %s` or change SAKI_BUILDX_BUILDER", builder, builder,
+		))
+	}
+	return nil
+}
+
+// buildxBuilderNames reads builder names from `docker buildx ls` output:
+// the first column of unindented rows, without the current-builder "*".
+// Node rows are indented and the header starts with NAME/NODE.
+func buildxBuilderNames(out string) []string {
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		if line == "" || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "NAME") || strings.HasPrefix(fields[0], "\\_") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(fields[0], "*"))
+	}
+	return names
+}
+
+func buildArgs(image string, opts BuildOptions) []string {
+	if opts.PushesOnBuild() {
+		args := []string{"buildx", "build"}
+		if builder := strings.TrimSpace(opts.Builder); builder != "" {
+			args = append(args, "--builder", builder)
+		}
+		args = append(args, "--platform", strings.Join(opts.Platforms, ","))
+		args = append(args, progressArgs(opts)...)
+		args = append(args, labelArgs(opts.Labels)...)
+		args = append(args, "-t", image, "--push")
+		args = append(args, buildArgFlags(opts.BuildArgs)...)
+		args = append(args, opts.ExtraBuildArgs...)
+		return append(args, ".")
+	}
+
+	args := []string{"build"}
+	if len(opts.Platforms) == 1 {
+		args = append(args, "--platform", opts.Platforms[0])
+	}
+	if opts.CPUQuota != "" {
+		args = append(args, "--cpu-quota", opts.CPUQuota)
+	}
+	if opts.Memory != "" {
+		args = append(args, "--memory", opts.Memory)
+	}
+	args = append(args, progressArgs(opts)...)
+	args = append(args, labelArgs(opts.Labels)...)
+	args = append(args, "-t", image)
+	args = append(args, buildArgFlags(opts.BuildArgs)...)
+	args = append(args, opts.ExtraBuildArgs...)
+	return append(args, ".")
+}
+
+func progressArgs(opts BuildOptions) []string {
+	if opts.CacheStats == nil {
+		return nil
+	}
+	return []string{"--progress=plain"}
+}
+
+func labelArgs(labels map[string]string) []string {
+	args := make([]string, 0, 2*len(labels))
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		args = append(args, "--label", key+"="+labels[key])
+	}
+	return args
+}
+
+func buildArgFlags(buildArgs map[string]string) []string {
+	args := make([]string, 0, 2*len(buildArgs))
+	for _, key := range slices.Sorted(maps.Keys(buildArgs)) {
+		args = append(args, "--build-arg", key)
+	}
+	return args
+}
+
+func buildArgEnv(buildArgs map[string]string) []string {
+	env := make([]string, 0, len(buildArgs))
+	for _, key := range slices.Sorted(maps.Keys(buildArgs)) {
+		env = append(env, key+"="+buildArgs[key])
+	}
+	return env
 }
 
-// Push runs `docker push <image>`.
-func (a *Adapter) Push(ctx context.Context, image string) error {
-	return a.run(ctx, "push", CommandRequest{
+// secretBuildArgMarkers flag build args whose name suggests a credential.
+var secretBuildArgMarkers = []string{"token", "password", "passwd", "secret"}
+
+// minRedactedValueLength keeps short values such as "1" from being redacted
+// all over the build output.
+const minRedactedValueLength = 4
+
+// secretBuildArgValues returns the build arg values to redact from command
+// output: those whose name holds a secret marker, or whose value holds a
+// credential logging.RedactSecrets recognizes.
+func secretBuildArgValues(buildArgs map[string]string) []string {
+	var values []string
+	for _, key := range slices.Sorted(maps.Keys(buildArgs)) {
+		value := buildArgs[key]
+		if len(value) < minRedactedValueLength {
+			continue
+		}
+		lower := strings.ToLower(key)
+		secretName := slices.ContainsFunc(secretBuildArgMarkers, func(marker string) bool {
+			return strings.Contains(lower, marker)
+		})
+		if secretName || logging.RedactSecrets(value) != value {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Push runs `docker push <image>`. With opts.Progress set, the push output is
+// streamed and parsed into aggregate progress updates, which are also logged
+// every 10%.
+func (a *Adapter) Push(ctx context.Context, image string, opts PushOptions) error {
+	req := CommandRequest{
 		Name: "docker",
 		Args: []string{"push", image},
+	}
+	if opts.Progress == nil {
+		return a.run(ctx, "push", req)
+	}
+
+	logged := -1
+	progress := newPushProgressWriter(func(p PushProgress) {
+		if p.Digest != "" || p.Percent/10 > logged {
+			logged = p.Percent / 10
+			fields := map[string]any{"image": image, "percent": p.Percent}
+			if p.Digest != "" {
+				fields["digest"] = p.Digest
+			}
+			a.logger.Info("docker push progress", logging.DeployFields(ctx, fields))
+		}
+		opts.Progress(p)
+	})
+	req.Stdout = progress
+	err := a.run(ctx, "push", req)
+	progress.flush()
+	return err
+}
+
+// Tag runs `docker tag <source> <target>`.
+func (a *Adapter) Tag(ctx context.Context, source, target string) error {
+	return a.run(ctx, "tag", CommandRequest{
+		Name: "docker",
+		Args: []string{"tag", source, target},
+	})
+}
+
+// CopyImage points target at the manifest of source in the registry via
+// `docker buildx imagetools create`. Unlike Tag it needs no local image, so
+// it also promotes multi-platform indexes that buildx pushed directly.
+func (a *Adapter) CopyImage(ctx context.Context, source, target string) error {
+	return a.run(ctx, "imagetools create", CommandRequest{
+		Name: "docker",
+		Args: []string{"buildx", "imagetools", "create", "--tag", target, source},
+	})
+}
+
+// LocalImageID returns the ID of a local image, which is the digest of its
+// config, via `docker image inspect`.
+func (a *Adapter) LocalImageID(ctx context.Context, image string) (string, error) {
+	res, err := a.runResult(ctx, "image inspect", CommandRequest{
+		Name: "docker",
+		Args: []string{"image", "inspect", "--format", "{{.Id}}", image},
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(res.Stdout), nil
+}
+
+// RemoteImageID returns the config digest of image in its registry via
+// `docker manifest inspect`, so it can be compared with LocalImageID. exists
+// is false when the registry has no such tag. A multi-platform index has no
+// single config and reports exists with an empty ID.
+func (a *Adapter) RemoteImageID(ctx context.Context, image string) (id string, exists bool, err error) {
+	res, err := a.runResult(ctx, "manifest inspect", CommandRequest{
+		Name: "docker",
+		Args: []string{"manifest", "inspect", image},
+	})
+	if err != nil {
+		var cmdErr *CommandError
+		if errors.As(err, &cmdErr) && isManifestNotFound(cmdErr.Stderr) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	var manifest struct {
+		Config struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal([]byte(res.Stdout), &manifest); err != nil {
+		return "", true, apperrors.Wrap(apperrors.CodeDocker, "decode manifest of "+image, err)
+	}
+	return manifest.Config.Digest, true, nil
+}
+
+func isManifestNotFound(stderr string) bool {
+	stderr = strings.ToLower(stderr)
+	return strings.Contains(stderr, "no such manifest") || strings.Contains(stderr, "manifest unknown")
+}
+
+// InspectManifest runs `docker manifest inspect <image>`, which fails unless
+// the registry serves a manifest for image.
+func (a *Adapter) InspectManifest(ctx context.Context, image string) error {
+	return a.run(ctx, "manifest inspect", CommandRequest{
+		Name: "docker",
+		Args: []string{"manifest", "inspect", image},
 	})
 }
 
 func (a *Adapter) run(ctx context.Context, op string, req CommandRequest) error {
+	_, err := a.runResult(ctx, op, req)
+	return err
+}
+
+// runResult runs req and also returns its output, for callers that parse it.
+func (a *Adapter) runResult(ctx context.Context, op string, req CommandRequest) (CommandResult, error) {
 	redacted := redactedCommand(req.Name, req.Args)
-	a.logger.Info("docker command", map[string]any{
+	a.logger.Info("docker command", logging.DeployFields(ctx, map[string]any{
 		"op":      op,
 		"command": redacted,
-	})
+	}))
 
 	res, err := a.runner.Run(ctx, req)
 	if err == nil {
-		return nil
+		return res, nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		// A killed process reports "signal: killed"; keep the context cause so
+		// deadline-driven cancellation maps to CodeTimeout.
+		err = fmt.Errorf("%w: %v", ctxErr, err)
 	}
 
+	// Registry errors can echo tokenized URLs or credentials; CommandError
+	// stderr ends up in error messages returned to MCP clients.
+	stderr := logging.RedactSecrets(strings.TrimSpace(res.Stderr))
+	for _, value := range req.Redact {
+		stderr = strings.ReplaceAll(stderr, value, "<redacted>")
+	}
 	cmdErr := &CommandError{
 		Op:       op,
 		Command:  redacted,
 		ExitCode: res.ExitCode,
-		Stderr:   strings.TrimSpace(res.Stderr),
+		Stderr:   tailLines(stderr, a.stderrTailLines),
 		Err:      err,
 	}
 
-	a.logger.Error("docker command failed", map[string]any{
+	a.logger.Error("docker command failed", logging.DeployFields(ctx, map[string]any{
 		"op":        op,
 		"command":   redacted,
 		"exit_code": cmdErr.ExitCode,
-		"stderr":    cmdErr.Stderr,
-	})
+		"stderr":    stderr,
+	}))
 
-	return cmdErr
+	return res, cmdErr
+}
+
+// tailLines keeps the last n lines of s, prefixed with a truncation marker
+// when anything was dropped.
+func tailLines(s string, n int) string {
+	if n < 1 {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) <= n {
+		return s
+	}
+	return stderrTruncatedMarker + "\n" + strings.Join(lines[len(lines)-n:], "\n")
 }
 
 func redactedCommand(name string, args []string) string {
@@ -179,7 +588,8 @@ func shouldRedactArg(args []string, idx int) bool {
 	return strings.Contains(arg, "token=") ||
 		strings.Contains(arg, "password=") ||
 		strings.Contains(arg, "passwd=") ||
-		strings.Contains(arg, "secret=")
+		strings.Contains(arg, "secret=") ||
+		strings.Contains(arg, "bearer ")
 }
 
 func redactURLUserInfo(raw string) string {
@@ -202,10 +612,16 @@ type execRunner struct{}
 func (execRunner) Run(ctx context.Context, req CommandRequest) (CommandResult, error) {
 	cmd := exec.CommandContext(ctx, req.Name, req.Args...)
 	cmd.Dir = req.Dir
+	if len(req.Env) > 0 {
+		cmd.Env = append(os.Environ(), req.Env...)
+	}
 
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &stdout
+	if req.Stdout != nil {
+		cmd.Stdout = io.MultiWriter(&stdout, req.Stdout)
+	}
 	cmd.Stderr = &stderr
 
 	if req.Stdin != "" {
@@ -3,8 +3,13 @@ package docker
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
 )
 
 func TestLogin_UsesPasswordStdinAndRedactsLogs(t *testing.T) {
@@ -37,11 +42,21 @@ func TestLogin_UsesPasswordStdinAndRedactsLogs(t *testing.T) {
 	}
 }
 
+func TestRedactedCommand_HidesBearerTokens(t *testing.T) {
+	got := redactedCommand("docker", []string{"build", "--build-arg", "AUTH=Authorization: Bearer abc123", "."})
+	if strings.Contains(got, "abc123") {
+		t.Fatalf("command leaked bearer token: %q", got)
+	}
+	if want := "docker build --build-arg <redacted> ."; got != want {
+		t.Fatalf("unexpected command: got %q want %q", got, want)
+	}
+}
+
 func TestBuild_SetsWorkingDirectory(t *testing.T) {
 	runner := &stubRunner{}
 	adapter := NewAdapter(nil, runner)
 
-	if err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123"); err != nil {
+	if err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", BuildOptions{}); err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
 
@@ -53,6 +68,81 @@ func TestBuild_SetsWorkingDirectory(t *testing.T) {
 	}
 }
 
+func TestBuild_MultiPlatformUsesBuildxPush(t *testing.T) {
+	runner := &stubRunner{}
+	adapter := NewAdapter(nil, runner)
+
+	opts := BuildOptions{Platforms: []string{"linux/amd64", "linux/arm64"}}
+	if err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := "buildx build --platform linux/amd64,linux/arm64 -t registry.internal/me/app:123 --push ."
+	if got := strings.Join(runner.last.Args, " "); got != want {
+		t.Fatalf("unexpected build args: got %q want %q", got, want)
+	}
+	if !opts.PushesOnBuild() {
+		t.Fatal("expected multi-platform build to push on build")
+	}
+}
+
+func TestBuild_SinglePlatformUsesPlainBuild(t *testing.T) {
+	runner := &stubRunner{}
+	adapter := NewAdapter(nil, runner)
+
+	opts := BuildOptions{Platforms: []string{"linux/arm64"}}
+	if err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := "build --platform linux/arm64 -t registry.internal/me/app:123 ."
+	if got := strings.Join(runner.last.Args, " "); got != want {
+		t.Fatalf("unexpected build args: got %q want %q", got, want)
+	}
+}
+
+func TestBuild_AppliesLabelsInKeyOrder(t *testing.T) {
+	runner := &stubRunner{}
+	adapter := NewAdapter(nil, runner)
+
+	opts := BuildOptions{Labels: map[string]string{
+		"org.opencontainers.image.revision": "abc123",
+		"org.opencontainers.image.created":  "2026-01-02T03:04:05Z",
+	}}
+	if err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := "build --label org.opencontainers.image.created=2026-01-02T03:04:05Z --label org.opencontainers.image.revision=abc123 -t registry.internal/me/app:123 ."
+	if got := strings.Join(runner.last.Args, " "); got != want {
+		t.Fatalf("unexpected build args: got %q want %q", got, want)
+	}
+}
+
+func TestBuild_AppendsExtraArgsAfterModeledFlags(t *testing.T) {
+	runner := &stubRunner{}
+	logger := &captureLogger{}
+	adapter := NewAdapter(logger, runner)
+
+	opts := BuildOptions{
+		Platforms:      []string{"linux/amd64"},
+		Labels:         map[string]string{"org.opencontainers.image.revision": "abc123"},
+		ExtraBuildArgs: []string{"--add-host", "db.internal:10.0.0.5", "--build-arg", "NPM_TOKEN=s3cr3t"},
+	}
+	if err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := "build --platform linux/amd64 --label org.opencontainers.image.revision=abc123 -t registry.internal/me/app:123 --add-host db.internal:10.0.0.5 --build-arg NPM_TOKEN=s3cr3t ."
+	if got := strings.Join(runner.last.Args, " "); got != want {
+		t.Fatalf("unexpected build args: got %q want %q", got, want)
+	}
+
+	if cmd := logger.lastCommand(t); strings.Contains(cmd, "s3cr3t") || !strings.Contains(cmd, "--add-host db.internal:10.0.0.5") {
+		t.Fatalf("unexpected logged command: %q", cmd)
+	}
+}
+
 func TestPush_ReturnsStructuredCommandError(t *testing.T) {
 	runner := &stubRunner{
 		result: CommandResult{ExitCode: 1, Stderr: "denied"},
@@ -60,7 +150,7 @@ func TestPush_ReturnsStructuredCommandError(t *testing.T) {
 	}
 	adapter := NewAdapter(nil, runner)
 
-	err := adapter.Push(context.Background(), "registry.internal/me/app:123")
+	err := adapter.Push(context.Background(), "registry.internal/me/app:123", PushOptions{})
 	if err == nil {
 		t.Fatalf("expected error")
 	}
@@ -81,6 +171,394 @@ func TestPush_ReturnsStructuredCommandError(t *testing.T) {
 	}
 }
 
+func TestPush_ReportsAggregateProgress(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	output := strings.Join([]string{
+		"The push refers to repository [registry.internal/me/app]",
+		"aaaaaaaaaaaa: Preparing",
+		"bbbbbbbbbbbb: Preparing",
+		"cccccccccccc: Preparing",
+		"cccccccccccc: Layer already exists",
+		"aaaaaaaaaaaa: Pushing [=>        ]  10MB/100MB\r" +
+			"aaaaaaaaaaaa: Pushing [=====>    ]  50MB/100MB",
+		"bbbbbbbbbbbb: Pushing [==>       ]  25MB/100MB",
+		"aaaaaaaaaaaa: Pushed",
+		"bbbbbbbbbbbb: Pushing [======>   ]  2.5MB/100MB",
+		"bbbbbbbbbbbb: Pushed",
+		"123: digest: " + digest + " size: 1234",
+	}, "\n")
+
+	logger := &captureLogger{}
+	runner := &stubRunner{result: CommandResult{Stdout: output}}
+	adapter := NewAdapter(logger, runner)
+
+	var updates []PushProgress
+	opts := PushOptions{Progress: func(p PushProgress) { updates = append(updates, p) }}
+	if err := adapter.Push(context.Background(), "registry.internal/me/app:123", opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var percents []int
+	for _, update := range updates[:len(updates)-1] {
+		percents = append(percents, update.Percent)
+		if update.Digest != "" {
+			t.Fatalf("expected digest only on the final update, got %+v", update)
+		}
+	}
+	if got := fmt.Sprint(percents); got != "[0 33 50 62 99]" {
+		t.Fatalf("unexpected progress percentages: %s", got)
+	}
+	last := updates[len(updates)-1]
+	if last != (PushProgress{Percent: 100, Digest: digest}) {
+		t.Fatalf("expected final digest update, got %+v", last)
+	}
+
+	entry := logger.entries[len(logger.entries)-1]
+	if entry.message != "docker push progress" || entry.fields["digest"] != digest {
+		t.Fatalf("expected digest in the final progress log, got %#v", entry)
+	}
+}
+
+func TestTagAndInspectManifest(t *testing.T) {
+	runner := &stubRunner{}
+	adapter := NewAdapter(nil, runner)
+
+	if err := adapter.Tag(context.Background(), "registry.internal/me/app:123", "registry.internal/me/app:123-staging"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := strings.Join(runner.last.Args, " "); got != "tag registry.internal/me/app:123 registry.internal/me/app:123-staging" {
+		t.Fatalf("unexpected tag args: %q", got)
+	}
+
+	if err := adapter.CopyImage(context.Background(), "registry.internal/me/app:123-staging", "registry.internal/me/app:123"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := strings.Join(runner.last.Args, " "); got != "buildx imagetools create --tag registry.internal/me/app:123 registry.internal/me/app:123-staging" {
+		t.Fatalf("unexpected imagetools args: %q", got)
+	}
+
+	runner.err = errors.New("exit status 1")
+	runner.result = CommandResult{ExitCode: 1, Stderr: "no such manifest: registry.internal/me/app:123-staging"}
+	err := adapter.InspectManifest(context.Background(), "registry.internal/me/app:123-staging")
+	if got := strings.Join(runner.last.Args, " "); got != "manifest inspect registry.internal/me/app:123-staging" {
+		t.Fatalf("unexpected inspect args: %q", got)
+	}
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Op != "manifest inspect" {
+		t.Fatalf("expected manifest inspect command error, got %v", err)
+	}
+}
+
+func TestRemoteImageID(t *testing.T) {
+	runner := &stubRunner{result: CommandResult{Stdout: `{"schemaVersion":2,"config":{"digest":"sha256:abc"}}`}}
+	adapter := NewAdapter(nil, runner)
+
+	id, exists, err := adapter.RemoteImageID(context.Background(), "registry.internal/me/app:123")
+	if err != nil || !exists || id != "sha256:abc" {
+		t.Fatalf("unexpected result: id=%q exists=%v err=%v", id, exists, err)
+	}
+	if got := strings.Join(runner.last.Args, " "); got != "manifest inspect registry.internal/me/app:123" {
+		t.Fatalf("unexpected args: %q", got)
+	}
+
+	runner.err = errors.New("exit status 1")
+	runner.result = CommandResult{ExitCode: 1, Stderr: "no such manifest: registry.internal/me/app:123"}
+	id, exists, err = adapter.RemoteImageID(context.Background(), "registry.internal/me/app:123")
+	if err != nil || exists || id != "" {
+		t.Fatalf("expected missing tag, got id=%q exists=%v err=%v", id, exists, err)
+	}
+}
+
+func TestBuild_TruncatesLongStderr(t *testing.T) {
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("step %d", i+1)
+	}
+	fullStderr := strings.Join(lines, "\n")
+
+	logger := &captureLogger{}
+	runner := &stubRunner{
+		result: CommandResult{ExitCode: 1, Stderr: fullStderr},
+		err:    errors.New("exit status 1"),
+	}
+	adapter := NewAdapter(logger, runner)
+	adapter.SetStderrTailLines(3)
+
+	err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", BuildOptions{})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("expected CommandError, got %T", err)
+	}
+
+	want := "... (truncated, see logs)\nstep 98\nstep 99\nstep 100"
+	if cmdErr.Stderr != want {
+		t.Fatalf("unexpected stderr:\ngot  %q\nwant %q", cmdErr.Stderr, want)
+	}
+
+	last := logger.entries[len(logger.entries)-1]
+	if last.message != "docker command failed" || last.fields["stderr"] != fullStderr {
+		t.Fatalf("expected full stderr in failure log, got %#v", last)
+	}
+}
+
+func TestBuild_PassesBuildArgsThroughEnvironment(t *testing.T) {
+	logger := &captureLogger{}
+	runner := &stubRunner{
+		result: CommandResult{ExitCode: 1, Stderr: "npm ERR! 401 Unauthorized: npm-registry-token-0123456789 (NODE_ENV=production)"},
+		err:    errors.New("exit status 1"),
+	}
+	adapter := NewAdapter(logger, runner)
+
+	err := adapter.Build(context.Background(), "/tmp/app", "registry/app:123", BuildOptions{
+		BuildArgs: map[string]string{"NPM_TOKEN": "npm-registry-token-0123456789", "NODE_ENV": "production"},
+	})
+
+	wantArgs := []string{"build", "-t", "registry/app:123", "--build-arg", "NODE_ENV", "--build-arg", "NPM_TOKEN", "."}
+	if !slices.Equal(runner.last.Args, wantArgs) {
+		t.Fatalf("expected args %v, got %v", wantArgs, runner.last.Args)
+	}
+	wantEnv := []string{"NODE_ENV=production", "NPM_TOKEN=npm-registry-token-0123456789"}
+	if !slices.Equal(runner.last.Env, wantEnv) {
+		t.Fatalf("expected env %v, got %v", wantEnv, runner.last.Env)
+	}
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("expected CommandError, got %T", err)
+	}
+	if strings.Contains(cmdErr.Error(), "npm-registry-token") || !strings.Contains(cmdErr.Stderr, "NODE_ENV=production") {
+		t.Fatalf("expected only the secret build arg to be redacted, got %q", cmdErr.Stderr)
+	}
+	for _, entry := range logger.entries {
+		if command, _ := entry.fields["command"].(string); strings.Contains(command, "npm-registry-token") {
+			t.Fatalf("expected build arg values to stay out of logged commands, got %q", command)
+		}
+	}
+}
+
+func TestPush_RedactsCredentialsInStderr(t *testing.T) {
+	logger := &captureLogger{}
+	runner := &stubRunner{
+		result: CommandResult{ExitCode: 1, Stderr: "error: failed to authorize: https://registry.internal/token?scope=push&token=secret123: 401"},
+		err:    errors.New("exit status 1"),
+	}
+	adapter := NewAdapter(logger, runner)
+
+	err := adapter.Push(context.Background(), "registry.internal/me/app:123", PushOptions{})
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) {
+		t.Fatalf("expected CommandError, got %T", err)
+	}
+	if strings.Contains(cmdErr.Stderr, "secret123") || !strings.Contains(cmdErr.Stderr, "token=<redacted>") {
+		t.Fatalf("expected redacted stderr, got %q", cmdErr.Stderr)
+	}
+	last := logger.entries[len(logger.entries)-1]
+	if stderr, _ := last.fields["stderr"].(string); strings.Contains(stderr, "secret123") {
+		t.Fatalf("expected redacted stderr in failure log, got %q", stderr)
+	}
+}
+
+func TestBuild_MapsDiskFullStderr(t *testing.T) {
+	runner := &stubRunner{
+		result: CommandResult{
+			ExitCode: 1,
+			Stderr:   "ERROR: failed to solve: write /var/lib/docker/tmp/layer.tar: no space left on device",
+		},
+		err: errors.New("exit status 1"),
+	}
+	adapter := NewAdapter(nil, runner)
+
+	err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", BuildOptions{})
+	if got := apperrors.CodeOf(err); got != apperrors.CodeDiskFull {
+		t.Fatalf("expected code %q, got %q", apperrors.CodeDiskFull, got)
+	}
+	if !strings.Contains(err.Error(), "docker system prune") {
+		t.Fatalf("expected prune advice in error, got %q", err.Error())
+	}
+}
+
+func TestBuild_MapsDaemonDownToConfigError(t *testing.T) {
+	runner := &stubRunner{
+		result: CommandResult{
+			ExitCode: 1,
+			Stderr:   "Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?",
+		},
+		err: errors.New("exit status 1"),
+	}
+	adapter := NewAdapter(nil, runner)
+
+	err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", BuildOptions{})
+	if got := apperrors.CodeOf(err); got != apperrors.CodeConfig {
+		t.Fatalf("expected code %q, got %q", apperrors.CodeConfig, got)
+	}
+	if !strings.Contains(err.Error(), "start Docker") {
+		t.Fatalf("expected start-docker advice in error, got %q", err.Error())
+	}
+}
+
+func TestBuild_PinsBuildxBuilder(t *testing.T) {
+	platforms := []string{"linux/amd64", "linux/arm64"}
+	tests := []struct {
+		builder string
+		want    string
+	}{
+		{builder: "ci-builder", want: "buildx build --builder ci-builder --platform linux/amd64,linux/arm64 -t registry.internal/me/app:123 --push ."},
+		{builder: "", want: "buildx build --platform linux/amd64,linux/arm64 -t registry.internal/me/app:123 --push ."},
+	}
+
+	for _, tt := range tests {
+		runner := &stubRunner{result: CommandResult{Stdout: "NAME/NODE     DRIVER/ENDPOINT    STATUS\nci-builder *  docker-container\n"}}
+		adapter := NewAdapter(nil, runner)
+
+		opts := BuildOptions{Platforms: platforms, Builder: tt.builder}
+		if err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", opts); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got := strings.Join(runner.last.Args, " "); got != tt.want {
+			t.Fatalf("builder %q: got %q want %q", tt.builder, got, tt.want)
+		}
+	}
+}
+
+func TestBuild_AppliesResourceLimits(t *testing.T) {
+	runner := &stubRunner{}
+	adapter := NewAdapter(nil, runner)
+
+	opts := BuildOptions{CPUQuota: "50000", Memory: "2g"}
+	if err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got, want := strings.Join(runner.last.Args, " "), "build --cpu-quota 50000 --memory 2g -t registry.internal/me/app:123 ."; got != want {
+		t.Fatalf("unexpected build args: got %q want %q", got, want)
+	}
+
+	opts.Platforms = []string{"linux/amd64", "linux/arm64"}
+	if err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got, want := strings.Join(runner.last.Args, " "), "buildx build --platform linux/amd64,linux/arm64 -t registry.internal/me/app:123 --push ."; got != want {
+		t.Fatalf("expected buildx to skip resource limits: got %q want %q", got, want)
+	}
+}
+
+func TestBuild_MapsMissingBuilderToConfigError(t *testing.T) {
+	// The builder passes the preflight but is gone by the time buildx builds.
+	runner := &scriptedRunner{results: map[string]scriptedResult{
+		"buildx ls": {result: CommandResult{Stdout: "ci-builder  docker-container\n"}},
+		"buildx build": {
+			result: CommandResult{ExitCode: 1, Stderr: `ERROR: no builder "ci-builder" found`},
+			err:    errors.New("exit status 1"),
+		},
+	}}
+	adapter := NewAdapter(nil, runner)
+
+	opts := BuildOptions{Platforms: []string{"linux/amd64", "linux/arm64"}, Builder: "ci-builder"}
+	err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", opts)
+	if got := apperrors.CodeOf(err); got != apperrors.CodeConfig {
+		t.Fatalf("expected code %q, got %q", apperrors.CodeConfig, got)
+	}
+	if !strings.Contains(err.Error(), "docker buildx create") {
+		t.Fatalf("expected builder advice in error, got %q", err.Error())
+	}
+}
+
+func TestBuild_ChecksBuildxBeforeMultiPlatformBuild(t *testing.T) {
+	platforms := []string{"linux/amd64", "linux/arm64"}
+	missing := scriptedResult{
+		result: CommandResult{ExitCode: 1, Stderr: "docker: 'buildx' is not a docker command."},
+		err:    errors.New("exit status 1"),
+	}
+
+	tests := []struct {
+		name      string
+		results   map[string]scriptedResult
+		platforms []string
+		builder   string
+		wantErr   string
+		wantCalls []string
+	}{
+		{
+			name:      "buildx missing",
+			results:   map[string]scriptedResult{"buildx version": missing},
+			platforms: platforms,
+			wantErr:   "install the buildx plugin",
+			wantCalls: []string{"buildx version"},
+		},
+		{
+			name:      "pinned builder missing",
+			results:   map[string]scriptedResult{"buildx ls": {result: CommandResult{Stdout: "NAME/NODE    DRIVER/ENDPOINT\ndefault *    docker\n \\_ default   default\n"}}},
+			platforms: platforms,
+			builder:   "ci-builder",
+			wantErr:   "docker buildx create --name ci-builder",
+			wantCalls: []string{"buildx version", "buildx ls"},
+		},
+		{
+			name:      "single platform skips the check",
+			results:   map[string]scriptedResult{"buildx version": missing},
+			platforms: []string{"linux/amd64"},
+			wantCalls: []string{"build"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &scriptedRunner{results: tt.results}
+			adapter := NewAdapter(nil, runner)
+
+			err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", BuildOptions{Platforms: tt.platforms, Builder: tt.builder})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+			} else {
+				if got := apperrors.CodeOf(err); got != apperrors.CodeConfig {
+					t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeConfig, got, err)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected %q in error, got %q", tt.wantErr, err.Error())
+				}
+			}
+			if len(runner.calls) != len(tt.wantCalls) {
+				t.Fatalf("unexpected docker calls: got %q want prefixes %q", runner.calls, tt.wantCalls)
+			}
+			for i, prefix := range tt.wantCalls {
+				if !strings.HasPrefix(runner.calls[i], prefix) {
+					t.Fatalf("unexpected docker calls: got %q want prefixes %q", runner.calls, tt.wantCalls)
+				}
+			}
+		})
+	}
+}
+
+// scriptedRunner answers each command by the longest matching args prefix in
+// results and succeeds with empty output otherwise. calls records the joined
+// args in order.
+type scriptedRunner struct {
+	results map[string]scriptedResult
+	calls   []string
+}
+
+type scriptedResult struct {
+	result CommandResult
+	err    error
+}
+
+func (s *scriptedRunner) Run(_ context.Context, req CommandRequest) (CommandResult, error) {
+	args := strings.Join(req.Args, " ")
+	s.calls = append(s.calls, args)
+
+	var match string
+	for prefix := range s.results {
+		if strings.HasPrefix(args, prefix) && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+	if match == "" {
+		return CommandResult{}, nil
+	}
+	return s.results[match].result, s.results[match].err
+}
+
 type stubRunner struct {
 	last   CommandRequest
 	result CommandResult
@@ -89,6 +567,9 @@ type stubRunner struct {
 
 func (s *stubRunner) Run(_ context.Context, req CommandRequest) (CommandResult, error) {
 	s.last = req
+	if req.Stdout != nil {
+		_, _ = io.WriteString(req.Stdout, s.result.Stdout)
+	}
 	return s.result, s.err
 }
 
@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"regexp"
+	"strings"
+)
+
+// BuildCacheStats summarizes how many Dockerfile steps BuildKit served from
+// its cache.
+type BuildCacheStats struct {
+	CachedSteps int
+	TotalSteps  int
+}
+
+var (
+	// buildStepPattern matches a BuildKit plain-progress step header such as
+	// `#6 [2/4] RUN make` or `#9 [builder 3/5] COPY . .`. Internal vertices
+	// like `#1 [internal] load build definition` have no n/m counter.
+	buildStepPattern   = regexp.MustCompile(`^#(\d+) \[(?:[^\]]*\s)?\d+/\d+\]`)
+	buildCachedPattern = regexp.MustCompile(`^#(\d+) CACHED\s*$`)
+)
+
+// parseBuildCacheStats counts cached and total steps in BuildKit
+// `--progress=plain` output. It reports false when no steps were found, for
+// example with the classic builder.
+func parseBuildCacheStats(output string) (BuildCacheStats, bool) {
+	steps := map[string]bool{}
+	var cached []string
+	for line := range strings.Lines(output) {
+		line = strings.TrimSpace(line)
+		if m := buildStepPattern.FindStringSubmatch(line); m != nil {
+			steps[m[1]] = true
+		} else if m := buildCachedPattern.FindStringSubmatch(line); m != nil {
+			cached = append(cached, m[1])
+		}
+	}
+	if len(steps) == 0 {
+		return BuildCacheStats{}, false
+	}
+
+	stats := BuildCacheStats{TotalSteps: len(steps)}
+	seen := map[string]bool{}
+	for _, id := range cached {
+		if steps[id] && !seen[id] {
+			seen[id] = true
+			stats.CachedSteps++
+		}
+	}
+	return stats, true
+}
@@ -0,0 +1,74 @@
+package docker
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const buildKitPlainOutput = `#0 building with "default" instance using docker driver
+
+#1 [internal] load build definition from Dockerfile
+#1 transferring dockerfile: 312B done
+#1 DONE 0.0s
+
+#2 [internal] load metadata for docker.io/library/golang:1.26
+#2 DONE 0.6s
+
+#3 [internal] load .dockerignore
+#3 DONE 0.0s
+
+#4 [builder 1/4] FROM docker.io/library/golang:1.26@sha256:0123
+#4 CACHED
+
+#5 [builder 2/4] WORKDIR /src
+#5 CACHED
+
+#6 [builder 3/4] COPY . .
+#6 DONE 0.2s
+
+#7 [builder 4/4] RUN go build -o /app .
+#7 0.512 go: downloading example.com/mod v1.0.0
+#7 DONE 14.1s
+
+#8 [stage-1 1/2] FROM gcr.io/distroless/static
+#8 CACHED
+
+#9 [stage-1 2/2] COPY --from=builder /app /app
+#9 DONE 0.1s
+
+#10 exporting to image
+#10 DONE 0.3s
+`
+
+func TestParseBuildCacheStats(t *testing.T) {
+	stats, ok := parseBuildCacheStats(buildKitPlainOutput)
+	if !ok {
+		t.Fatal("expected stats to parse")
+	}
+	if stats.CachedSteps != 3 || stats.TotalSteps != 6 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	if _, ok := parseBuildCacheStats("Step 1/4 : FROM golang\n ---> Using cache\n"); ok {
+		t.Fatal("expected classic builder output not to parse")
+	}
+}
+
+func TestBuild_ReportsCacheStats(t *testing.T) {
+	runner := &stubRunner{result: CommandResult{Stderr: buildKitPlainOutput}}
+	adapter := NewAdapter(nil, runner)
+
+	var got *BuildCacheStats
+	opts := BuildOptions{CacheStats: func(stats BuildCacheStats) { got = &stats }}
+	if err := adapter.Build(context.Background(), "/tmp/app", "registry.internal/me/app:123", opts); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if args := strings.Join(runner.last.Args, " "); args != "build --progress=plain -t registry.internal/me/app:123 ." {
+		t.Fatalf("unexpected build args: %q", args)
+	}
+	if got == nil || got.CachedSteps != 3 || got.TotalSteps != 6 {
+		t.Fatalf("unexpected stats: %+v", got)
+	}
+}
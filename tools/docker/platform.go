@@ -0,0 +1,62 @@
+package docker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// supportedPlatforms is the allowlist of build platforms accepted by the tool.
+var supportedPlatforms = []string{
+	"linux/amd64",
+	"linux/arm64",
+	"linux/arm/v7",
+	"linux/arm/v6",
+	"linux/386",
+	"linux/ppc64le",
+	"linux/s390x",
+	"linux/riscv64",
+}
+
+// ParsePlatforms splits a comma-separated platform list (e.g.
+// "linux/amd64,linux/arm64") and validates every entry.
+func ParsePlatforms(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	platforms := make([]string, 0, len(parts))
+	for _, part := range parts {
+		platforms = append(platforms, strings.TrimSpace(part))
+	}
+
+	if err := ValidatePlatforms(platforms); err != nil {
+		return nil, err
+	}
+	return platforms, nil
+}
+
+// ValidatePlatforms checks each platform against the allowlist and rejects duplicates.
+func ValidatePlatforms(platforms []string) error {
+	seen := make(map[string]bool, len(platforms))
+	for _, platform := range platforms {
+		if platform == "" {
+			return apperrors.New(apperrors.CodeInvalidInput, "validate platforms", "platform list contains an empty entry")
+		}
+		if !slices.Contains(supportedPlatforms, platform) {
+			return apperrors.New(apperrors.CodeInvalidInput, "validate platforms", fmt.Sprintf(
+				"unsupported platform %q (expected os/arch such as %s)",
+				platform,
+				strings.Join(supportedPlatforms, ", "),
+			))
+		}
+		if seen[platform] {
+			return apperrors.New(apperrors.CodeInvalidInput, "validate platforms", fmt.Sprintf("platform %q is listed more than once", platform))
+		}
+		seen[platform] = true
+	}
+	return nil
+}
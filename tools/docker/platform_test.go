@@ -0,0 +1,32 @@
+package docker
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestParsePlatforms(t *testing.T) {
+	got, err := ParsePlatforms("linux/amd64, linux/arm64")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !slices.Equal(got, []string{"linux/amd64", "linux/arm64"}) {
+		t.Fatalf("unexpected platforms: %v", got)
+	}
+
+	got, err = ParsePlatforms(" ")
+	if err != nil || got != nil {
+		t.Fatalf("expected empty value to yield no platforms, got %v (%v)", got, err)
+	}
+}
+
+func TestParsePlatforms_RejectsMalformedValues(t *testing.T) {
+	for _, value := range []string{"linux", "windows/amd64", "linux/amd64,", "linux/amd64,linux/amd64"} {
+		_, err := ParsePlatforms(value)
+		if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+			t.Fatalf("expected code %q for %q, got %q", apperrors.CodeInvalidInput, value, got)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package docker
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PushProgress is an aggregate progress update for one `docker push`.
+type PushProgress struct {
+	// Percent is the share of layer bytes pushed so far (0-100). It only
+	// increases and reaches 100 once the registry reports the digest.
+	Percent int
+	// Digest is set on the final update, from the `<tag>: digest: sha256:...`
+	// line.
+	Digest string
+}
+
+// PushOptions customizes an image push.
+type PushOptions struct {
+	// Progress, when set, receives aggregate updates while the push streams.
+	// It is called synchronously from the output reader and must not block.
+	Progress func(PushProgress)
+}
+
+var (
+	pushLayerPattern  = regexp.MustCompile(`^([0-9a-f]{6,64}): (.+)$`)
+	pushBytesPattern  = regexp.MustCompile(`([0-9.]+\s*[kKMGT]?B)/([0-9.]+\s*[kKMGT]?B)\s*$`)
+	pushDigestPattern = regexp.MustCompile(`digest: (sha256:[0-9a-f]{64})`)
+	byteSizePattern   = regexp.MustCompile(`^([0-9.]+)\s*([kKMGT]?)B$`)
+)
+
+var byteSizeUnits = map[string]float64{
+	"":  1,
+	"k": 1e3,
+	"K": 1e3,
+	"M": 1e6,
+	"G": 1e9,
+	"T": 1e12,
+}
+
+type pushLayer struct {
+	current int64
+	total   int64
+	done    bool
+}
+
+// pushProgressWriter parses `docker push` output as it is written and reports
+// aggregate progress. Lines may end in \n or \r (terminal redraws).
+type pushProgressWriter struct {
+	mu      sync.Mutex
+	report  func(PushProgress)
+	partial []byte
+	layers  map[string]*pushLayer
+	percent int
+}
+
+func newPushProgressWriter(report func(PushProgress)) *pushProgressWriter {
+	return &pushProgressWriter{report: report, layers: map[string]*pushLayer{}, percent: -1}
+}
+
+func (w *pushProgressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		idx := bytes.IndexAny(w.partial, "\r\n")
+		if idx < 0 {
+			break
+		}
+		w.parseLine(string(w.partial[:idx]))
+		w.partial = w.partial[idx+1:]
+	}
+	return len(p), nil
+}
+
+// flush parses a final line that was not newline-terminated.
+func (w *pushProgressWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.parseLine(string(w.partial))
+	w.partial = nil
+}
+
+func (w *pushProgressWriter) parseLine(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+
+	if match := pushDigestPattern.FindStringSubmatch(line); match != nil {
+		w.percent = 100
+		w.report(PushProgress{Percent: 100, Digest: match[1]})
+		return
+	}
+
+	match := pushLayerPattern.FindStringSubmatch(line)
+	if match == nil {
+		return
+	}
+	layer, ok := w.layers[match[1]]
+	if !ok {
+		layer = &pushLayer{}
+		w.layers[match[1]] = layer
+	}
+
+	status := match[2]
+	switch {
+	case strings.HasPrefix(status, "Pushed"),
+		strings.HasPrefix(status, "Layer already exists"),
+		strings.HasPrefix(status, "Mounted from"):
+		layer.done = true
+		layer.current = layer.total
+	case strings.HasPrefix(status, "Pushing"):
+		if sizes := pushBytesPattern.FindStringSubmatch(status); sizes != nil {
+			current, currentOK := parseByteSize(sizes[1])
+			total, totalOK := parseByteSize(sizes[2])
+			if currentOK && totalOK && total > 0 {
+				layer.current = min(current, total)
+				layer.total = total
+			}
+		}
+	}
+
+	// Percent stays below 100 until the digest line confirms the push.
+	if percent := min(w.aggregatePercent(), 99); percent > w.percent {
+		w.percent = percent
+		w.report(PushProgress{Percent: percent})
+	}
+}
+
+// aggregatePercent weighs layers by size when sizes are known; layers the
+// registry already had carry no bytes. Without any sizes it falls back to the
+// share of finished layers.
+func (w *pushProgressWriter) aggregatePercent() int {
+	var pushed, total int64
+	done := 0
+	for _, layer := range w.layers {
+		pushed += layer.current
+		total += layer.total
+		if layer.done {
+			done++
+		}
+	}
+	if total > 0 {
+		return int(pushed * 100 / total)
+	}
+	if len(w.layers) == 0 {
+		return 0
+	}
+	return done * 100 / len(w.layers)
+}
+
+// parseByteSize parses docker's human-readable sizes (512B, 3.4kB, 52.3MB).
+func parseByteSize(s string) (int64, bool) {
+	match := byteSizePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if match == nil {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return int64(value * byteSizeUnits[match[2]]), true
+}
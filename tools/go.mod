@@ -2,9 +2,13 @@ module github.com/1800agents/saki/tools
 
 go 1.26.0
 
+require (
+	github.com/modelcontextprotocol/go-sdk v1.4.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
 require (
 	github.com/google/jsonschema-go v0.4.2 // indirect
-	github.com/modelcontextprotocol/go-sdk v1.4.0 // indirect
 	github.com/segmentio/asm v1.1.3 // indirect
 	github.com/segmentio/encoding v0.5.3 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
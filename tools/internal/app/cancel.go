@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"strings"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const statusCancelRequested = "cancel_requested"
+
+type cancelService interface {
+	CancelDeployment(ctx context.Context, controlPlaneURL, deploymentID string) error
+}
+
+// cancelOutput is written to stdout after the control plane accepts a cancel
+// (or reports the deployment already finished).
+type cancelOutput struct {
+	DeploymentID string `json:"deployment_id"`
+	Status       string `json:"status"`
+}
+
+// runCancel implements `saki-tools cancel [--control-plane-url URL] <deployment-id>`.
+func runCancel(ctx context.Context, args []string, stdout io.Writer, service cancelService) error {
+	fs := flag.NewFlagSet("cancel", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	controlPlaneURL := fs.String("control-plane-url", "", "tokenized Saki control plane URL (or set SAKI_CONTROL_PLANE_URL)")
+	if err := fs.Parse(args); err != nil {
+		return apperrors.Wrap(apperrors.CodeInvalidInput, "parse cancel flags", err)
+	}
+	if fs.NArg() != 1 || strings.TrimSpace(fs.Arg(0)) == "" {
+		return apperrors.New(apperrors.CodeInvalidInput, "parse cancel command", "usage: saki-tools cancel [--control-plane-url URL] <deployment-id>")
+	}
+	deploymentID := strings.TrimSpace(fs.Arg(0))
+
+	if err := service.CancelDeployment(ctx, *controlPlaneURL, deploymentID); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(cancelOutput{DeploymentID: deploymentID, Status: statusCancelRequested}); err != nil {
+		return apperrors.Wrap(apperrors.CodeInternal, "write cancel output", err)
+	}
+	return nil
+}
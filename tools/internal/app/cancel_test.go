@@ -0,0 +1,45 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestRunCancel_CancelsDeployment(t *testing.T) {
+	service := &stubCancelService{}
+	var stdout bytes.Buffer
+
+	err := runCancel(context.Background(), []string{"--control-plane-url", "https://cp.internal?token=t", "dep_123"}, &stdout, service)
+	if err != nil {
+		t.Fatalf("run cancel: %v", err)
+	}
+	if service.controlPlaneURL != "https://cp.internal?token=t" || service.deploymentID != "dep_123" {
+		t.Fatalf("unexpected cancel call: url=%q id=%q", service.controlPlaneURL, service.deploymentID)
+	}
+	if !strings.Contains(stdout.String(), `"status": "cancel_requested"`) {
+		t.Fatalf("expected cancel output, got %q", stdout.String())
+	}
+}
+
+func TestRunCancel_RequiresDeploymentID(t *testing.T) {
+	err := runCancel(context.Background(), nil, &bytes.Buffer{}, &stubCancelService{})
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected code %q, got %q", apperrors.CodeInvalidInput, got)
+	}
+}
+
+type stubCancelService struct {
+	controlPlaneURL string
+	deploymentID    string
+	err             error
+}
+
+func (s *stubCancelService) CancelDeployment(_ context.Context, controlPlaneURL, deploymentID string) error {
+	s.controlPlaneURL = controlPlaneURL
+	s.deploymentID = deploymentID
+	return s.err
+}
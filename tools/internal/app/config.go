@@ -0,0 +1,95 @@
+package app
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/config"
+	"github.com/1800agents/saki/tools/internal/tool"
+)
+
+type configResolver interface {
+	ResolvedConfig() (tool.ResolvedConfig, error)
+}
+
+// effectiveConfig is printed by `saki-tools config show`: the process
+// settings from config.Load plus the resolved deploy settings.
+type effectiveConfig struct {
+	Mode string `json:"mode"`
+	Addr string `json:"addr"`
+	tool.ResolvedConfig
+}
+
+// runConfig implements `saki-tools config show [--format json|table]`.
+func runConfig(args []string, stdout io.Writer, cfg config.Config, resolver configResolver) error {
+	if len(args) == 0 || args[0] != "show" {
+		return apperrors.New(apperrors.CodeInvalidInput, "parse config command", "usage: saki-tools config show [--format json|table]")
+	}
+
+	fs := flag.NewFlagSet("config show", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("format", "json", "output format (json or table)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return apperrors.Wrap(apperrors.CodeInvalidInput, "parse config flags", err)
+	}
+
+	resolved, err := resolver.ResolvedConfig()
+	if err != nil {
+		return err
+	}
+	effective := effectiveConfig{Mode: cfg.Mode, Addr: cfg.Addr, ResolvedConfig: resolved}
+
+	switch strings.TrimSpace(*format) {
+	case "json":
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(effective); err != nil {
+			return apperrors.Wrap(apperrors.CodeInternal, "write config", err)
+		}
+		return nil
+	case "table":
+		return writeConfigTable(stdout, effective)
+	default:
+		return apperrors.New(apperrors.CodeInvalidInput, "parse config flags", fmt.Sprintf("unsupported --format value %q (supported: json, table)", *format))
+	}
+}
+
+// writeConfigTable prints one "key  value" row per setting, using the JSON
+// field names so both formats read the same.
+func writeConfigTable(stdout io.Writer, effective effectiveConfig) error {
+	data, err := json.Marshal(effective)
+	if err != nil {
+		return apperrors.Wrap(apperrors.CodeInternal, "write config", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return apperrors.Wrap(apperrors.CodeInternal, "write config", err)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for _, key := range keys {
+		value := string(fields[key])
+		var s string
+		if json.Unmarshal(fields[key], &s) == nil {
+			value = s
+		}
+		fmt.Fprintf(w, "%s\t%s\n", key, value)
+	}
+	if err := w.Flush(); err != nil {
+		return apperrors.Wrap(apperrors.CodeInternal, "write config", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/internal/config"
+	"github.com/1800agents/saki/tools/internal/tool"
+)
+
+func TestRunConfig_ShowsResolvedRegistryAndRedactsToken(t *testing.T) {
+	t.Setenv("SAKI_DOCKER_REGISTRY", "https://registry.internal:8443/v2/")
+	t.Setenv("SAKI_CONTROL_PLANE_URL", "https://cp.internal/api?token=11111111-2222-4333-8444-555555555555")
+
+	for _, format := range []string{"json", "table"} {
+		t.Run(format, func(t *testing.T) {
+			var stdout bytes.Buffer
+			err := runConfig([]string{"show", "--format", format}, &stdout, config.Config{Mode: "local", Addr: "127.0.0.1:8080"}, tool.NewService())
+			if err != nil {
+				t.Fatalf("runConfig returned error: %v", err)
+			}
+
+			output := stdout.String()
+			if strings.Contains(output, "11111111-2222-4333-8444-555555555555") {
+				t.Fatalf("expected token to be redacted, got %s", output)
+			}
+			for _, want := range []string{"registry.internal:8443", "https://cp.internal/api?token=<redacted>"} {
+				if !strings.Contains(output, want) {
+					t.Fatalf("expected output to include %q, got %s", want, output)
+				}
+			}
+
+			if format == "json" {
+				var got effectiveConfig
+				if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+					t.Fatalf("output is not valid JSON: %v", err)
+				}
+				if got.ImageRegistry != "registry.internal:8443" || got.Mode != "local" {
+					t.Fatalf("unexpected config: %+v", got)
+				}
+			}
+		})
+	}
+}
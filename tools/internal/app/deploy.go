@@ -0,0 +1,223 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/docker"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/tool"
+)
+
+const progressNDJSON = "ndjson"
+
+type deployService interface {
+	DeployAppWithProgress(ctx context.Context, in contracts.DeployAppInput, progress tool.ProgressFunc) (contracts.DeployAppOutput, error)
+	DeployApps(ctx context.Context, inputs []contracts.DeployAppInput, opts tool.BatchOptions) ([]contracts.DeployAppOutput, error)
+	DeployAppToTargets(ctx context.Context, in contracts.DeployAppInput, targets []string, opts tool.FanOutOptions) ([]contracts.DeployAppOutput, error)
+}
+
+// runDeploy implements `saki-tools deploy`. The deploy output is written to
+// stdout as JSON; logs stay on stderr via the shared logger.
+func runDeploy(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, service deployService) error {
+	fs := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	var in contracts.DeployAppInput
+	var progressMode, manifestPath, platforms, summaryPath, inputPath string
+	var batch tool.BatchOptions
+	var targets, targetRegistries []string
+	fs.StringVar(&in.SakiControlPlaneURL, "control-plane-url", "", "tokenized Saki control plane URL (or set SAKI_CONTROL_PLANE_URL)")
+	fs.StringVar(&in.Name, "name", "", "DNS-safe app name")
+	fs.StringVar(&in.Description, "description", "", "short human-readable app purpose")
+	fs.StringVar(&in.Org, "org", "", "control plane org (namespace) the app belongs to, for multi-tenant control planes")
+	fs.StringVar(&in.Note, "note", "", "free-text note recorded with the deployment for audit (max 500 characters)")
+	fs.StringVar(&in.AppDir, "app-dir", "", "local directory containing the app source to build")
+	fs.StringVar(&in.ImageRepository, "image-repository", "", "push to this image repository instead of the one returned by prepare (the prepare tag is kept)")
+	fs.StringVar(&in.GitCommit, "commit", "", "build from this git commit hash (7-40 hex characters) instead of running git, e.g. for an exported tree without .git (or set SAKI_GIT_COMMIT)")
+	fs.StringVar(&platforms, "platform", "", "comma-separated target platforms (e.g. linux/amd64,linux/arm64)")
+	fs.StringVar(&progressMode, "progress", "", "progress output format (ndjson)")
+	fs.StringVar(&manifestPath, "manifest", "", "YAML manifest listing apps to deploy in one invocation")
+	fs.StringVar(&inputPath, "input-file", "", "read a JSON deploy input from this path (- for stdin); explicit flags override its fields")
+	fs.StringVar(&summaryPath, "summary-file", "", "write a JSON deploy summary to this path after a successful deploy")
+	fs.IntVar(&batch.Concurrency, "concurrency", 1, "maximum apps deployed concurrently with --manifest")
+	fs.BoolVar(&in.ValidateOnly, "validate-only", false, "check the input, configuration, app directory, and git commit without calling the control plane or docker")
+	fs.BoolVar(&in.Force, "force", false, "push even when SAKI_IMMUTABLE_TAGS finds the image tag in the registry with different content")
+	fs.BoolVar(&in.DryRun, "dry-run", false, "call prepare and check the app's current state, then print the deploy plan without building, pushing, or deploying")
+	fs.StringVar(&in.StateFile, "state-file", "", "save deploy progress to this file after each stage so an interrupted deploy can be resumed")
+	fs.BoolVar(&in.Resume, "resume", false, "skip the stages --state-file records as completed")
+	fs.Func("build-arg-file", "KEY=path: pass the file's contents, without a trailing newline, as docker build arg KEY (repeatable)", func(value string) error {
+		name, path, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("--build-arg-file must be KEY=path, got %q", value)
+		}
+		name = strings.TrimSpace(name)
+		if _, dup := in.BuildArgFiles[name]; dup {
+			return fmt.Errorf("--build-arg-file %s is set twice", name)
+		}
+		if in.BuildArgFiles == nil {
+			in.BuildArgFiles = map[string]string{}
+		}
+		in.BuildArgFiles[name] = path
+		return nil
+	})
+	fs.BoolVar(&batch.FailFast, "fail-fast", false, "stop remaining --manifest or --target deploys after the first failure")
+	fs.Func("target", "control plane URL to deploy the built image to, optionally followed by ,registry=<registry> to push there for this target (repeatable)", func(value string) error {
+		target, registry := splitTargetRegistry(value)
+		if target == "" {
+			return fmt.Errorf("--target must not be empty")
+		}
+		targets = append(targets, target)
+		targetRegistries = append(targetRegistries, registry)
+		return nil
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return apperrors.Wrap(apperrors.CodeInvalidInput, "parse deploy flags", err)
+	}
+
+	parsedPlatforms, err := docker.ParsePlatforms(platforms)
+	if err != nil {
+		return err
+	}
+	in.Platforms = parsedPlatforms
+
+	if inputPath = strings.TrimSpace(inputPath); inputPath != "" {
+		if strings.TrimSpace(manifestPath) != "" {
+			return apperrors.New(apperrors.CodeInvalidInput, "parse deploy flags", "--input-file is not supported with --manifest")
+		}
+		fileIn, err := readDeployInput(inputPath, stdin)
+		if err != nil {
+			return err
+		}
+		set := map[string]bool{}
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		in = mergeDeployInput(fileIn, in, set)
+		if err := in.Validate(); err != nil {
+			return apperrors.Wrap(apperrors.CodeInvalidInput, "validate deploy input", err)
+		}
+	}
+
+	progressMode = strings.TrimSpace(progressMode)
+	if progressMode != "" && progressMode != progressNDJSON {
+		return apperrors.New(apperrors.CodeInvalidInput, "parse deploy flags", fmt.Sprintf("unsupported --progress value %q (supported: %s)", progressMode, progressNDJSON))
+	}
+
+	summaryPath = strings.TrimSpace(summaryPath)
+	if summaryPath != "" && (strings.TrimSpace(manifestPath) != "" || len(targets) > 0) {
+		return apperrors.New(apperrors.CodeInvalidInput, "parse deploy flags", "--summary-file is only supported for single-app deploys")
+	}
+
+	if strings.TrimSpace(manifestPath) != "" {
+		if progressMode != "" {
+			return apperrors.New(apperrors.CodeInvalidInput, "parse deploy flags", "--progress is not supported with --manifest")
+		}
+		if len(targets) > 0 {
+			return apperrors.New(apperrors.CodeInvalidInput, "parse deploy flags", "--target is not supported with --manifest")
+		}
+		if strings.TrimSpace(in.StateFile) != "" {
+			return apperrors.New(apperrors.CodeInvalidInput, "parse deploy flags", "--state-file is not supported with --manifest")
+		}
+		if strings.TrimSpace(in.ImageRepository) != "" {
+			return apperrors.New(apperrors.CodeInvalidInput, "parse deploy flags", "--image-repository is not supported with --manifest: every app would push to the same repository")
+		}
+		return runBatchDeploy(ctx, manifestPath, in, batch, stdout, service)
+	}
+
+	if len(targets) > 0 {
+		if progressMode != "" {
+			return apperrors.New(apperrors.CodeInvalidInput, "parse deploy flags", "--progress is not supported with --target")
+		}
+		if in.DryRun {
+			return apperrors.New(apperrors.CodeInvalidInput, "parse deploy flags", "--dry-run is not supported with --target")
+		}
+		if in.ValidateOnly {
+			return apperrors.New(apperrors.CodeInvalidInput, "parse deploy flags", "--validate-only is not supported with --target")
+		}
+		if strings.TrimSpace(in.StateFile) != "" {
+			return apperrors.New(apperrors.CodeInvalidInput, "parse deploy flags", "--state-file is not supported with --target")
+		}
+		outputs, deployErr := service.DeployAppToTargets(ctx, in, targets, tool.FanOutOptions{FailFast: batch.FailFast, Registries: targetRegistries})
+		return writeOutputs(stdout, outputs, deployErr)
+	}
+
+	encoder := json.NewEncoder(stdout)
+	var progress tool.ProgressFunc
+	if progressMode == progressNDJSON {
+		progress = func(event tool.ProgressEvent) {
+			_ = encoder.Encode(event)
+		}
+	} else {
+		encoder.SetIndent("", "  ")
+	}
+
+	out, err := service.DeployAppWithProgress(ctx, in, progress)
+	if err != nil {
+		return err
+	}
+
+	if summaryPath != "" {
+		if err := writeSummaryFile(summaryPath, newDeploySummary(out, tool.DockerRegistry())); err != nil {
+			return err
+		}
+	}
+
+	if err := encoder.Encode(out); err != nil {
+		return apperrors.Wrap(apperrors.CodeInternal, "write deploy output", err)
+	}
+	return nil
+}
+
+// targetRegistrySuffix separates a --target control plane URL from the
+// registry that target pushes to.
+const targetRegistrySuffix = ",registry="
+
+// splitTargetRegistry splits a --target value of the form
+// <url>[,registry=<registry>].
+func splitTargetRegistry(value string) (target, registry string) {
+	target = strings.TrimSpace(value)
+	if i := strings.LastIndex(target, targetRegistrySuffix); i >= 0 {
+		target, registry = strings.TrimSpace(target[:i]), strings.TrimSpace(target[i+len(targetRegistrySuffix):])
+	}
+	return target, registry
+}
+
+// runBatchDeploy deploys every manifest entry and writes the per-app outputs
+// as a JSON array, even when some apps fail. Flag values in defaults apply to
+// every entry.
+func runBatchDeploy(ctx context.Context, manifestPath string, defaults contracts.DeployAppInput, opts tool.BatchOptions, stdout io.Writer, service deployService) error {
+	inputs, err := loadManifest(manifestPath, defaults.SakiControlPlaneURL)
+	if err != nil {
+		return err
+	}
+	for i := range inputs {
+		inputs[i].Platforms = defaults.Platforms
+		inputs[i].DryRun = defaults.DryRun
+		inputs[i].ValidateOnly = defaults.ValidateOnly
+		inputs[i].Force = defaults.Force
+		inputs[i].Org = defaults.Org
+		inputs[i].Note = defaults.Note
+		inputs[i].GitCommit = defaults.GitCommit
+		inputs[i].BuildArgFiles = maps.Clone(defaults.BuildArgFiles)
+	}
+
+	outputs, deployErr := service.DeployApps(ctx, inputs, opts)
+	return writeOutputs(stdout, outputs, deployErr)
+}
+
+// writeOutputs writes per-deploy outputs as an indented JSON array and then
+// returns deployErr, so partial results are reported even on failure.
+func writeOutputs(stdout io.Writer, outputs []contracts.DeployAppOutput, deployErr error) error {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(outputs); err != nil {
+		return apperrors.Wrap(apperrors.CodeInternal, "write deploy output", err)
+	}
+	return deployErr
+}
@@ -0,0 +1,550 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/tool"
+)
+
+func TestRunDeploy_NDJSONProgress(t *testing.T) {
+	service := &stubDeployService{
+		events: []tool.ProgressEvent{
+			{Stage: tool.StagePrepare, Status: tool.ProgressStarted},
+			{Stage: tool.StagePrepare, Status: tool.ProgressCompleted},
+			{Stage: tool.StageBuild, Status: tool.ProgressStarted},
+			{Stage: tool.StageBuild, Status: tool.ProgressCompleted},
+		},
+		out: contracts.DeployAppOutput{
+			AppID:  "app_123",
+			Image:  "registry.internal/owner/my-app:abc",
+			URL:    "https://my-app.saki.internal",
+			Status: "deploying",
+		},
+	}
+
+	var stdout bytes.Buffer
+	err := runDeploy(context.Background(), []string{
+		"--name", "my-app",
+		"--description", "internal app",
+		"--app-dir", "/tmp/my-app",
+		"--progress=ndjson",
+	}, nil, &stdout, service)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if service.in.Name != "my-app" || service.in.Description != "internal app" || service.in.AppDir != "/tmp/my-app" {
+		t.Fatalf("unexpected deploy input: %+v", service.in)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != len(service.events)+1 {
+		t.Fatalf("expected %d lines, got %d: %q", len(service.events)+1, len(lines), stdout.String())
+	}
+	for i, line := range lines[:len(lines)-1] {
+		var event tool.ProgressEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v (%q)", i, err, line)
+		}
+		if event != service.events[i] {
+			t.Fatalf("line %d: expected %+v, got %+v", i, service.events[i], event)
+		}
+	}
+
+	var out contracts.DeployAppOutput
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &out); err != nil {
+		t.Fatalf("final line is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(out, service.out) {
+		t.Fatalf("expected final line to be deploy output %+v, got %+v", service.out, out)
+	}
+}
+
+func TestRunDeploy_ParsesPlatformList(t *testing.T) {
+	service := &stubDeployService{}
+
+	err := runDeploy(context.Background(), []string{
+		"--name", "my-app",
+		"--platform", "linux/amd64,linux/arm64",
+	}, nil, &bytes.Buffer{}, service)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := service.in.Platforms; len(got) != 2 || got[0] != "linux/amd64" || got[1] != "linux/arm64" {
+		t.Fatalf("expected parsed platforms to reach the service, got %v", got)
+	}
+}
+
+func TestRunDeploy_RejectsMalformedPlatform(t *testing.T) {
+	service := &stubDeployService{}
+
+	err := runDeploy(context.Background(), []string{"--platform", "linux/amd64,amd64"}, nil, &bytes.Buffer{}, service)
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected code %q, got %q", apperrors.CodeInvalidInput, got)
+	}
+	if service.in.Name != "" {
+		t.Fatal("expected service not to be called for malformed platforms")
+	}
+}
+
+func TestRunDeploy_RejectsUnknownProgressMode(t *testing.T) {
+	err := runDeploy(context.Background(), []string{"--progress=xml"}, nil, &bytes.Buffer{}, &stubDeployService{})
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected code %q, got %q", apperrors.CodeInvalidInput, got)
+	}
+}
+
+func TestRunDeploy_ReturnsServiceError(t *testing.T) {
+	deployErr := errors.New("deploy failed")
+	var stdout bytes.Buffer
+
+	err := runDeploy(context.Background(), nil, nil, &stdout, &stubDeployService{err: deployErr})
+	if !errors.Is(err, deployErr) {
+		t.Fatalf("expected service error, got %v", err)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("expected no output on failure, got %q", stdout.String())
+	}
+}
+
+func TestRunDeploy_ManifestReportsPartialFailure(t *testing.T) {
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "apps.yaml")
+	manifest := `apps:
+  - name: web
+    description: Web frontend
+    app_dir: ./web
+  - name: api
+    description: API backend
+    app_dir: /srv/api
+`
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	apiErr := errors.New("api build failed")
+	service := &stubDeployService{
+		batchOuts: []contracts.DeployAppOutput{{Image: "registry.internal/owner/web:abc", Status: "deploying"}, {}},
+		batchErr:  apperrors.NewMulti(apiErr),
+	}
+
+	var stdout bytes.Buffer
+	err := runDeploy(context.Background(), []string{
+		"--manifest", manifestPath,
+		"--control-plane-url", "https://cp.internal?token=test-token",
+		"--concurrency", "2",
+		"--fail-fast",
+	}, nil, &stdout, service)
+	if !errors.Is(err, apiErr) {
+		t.Fatalf("expected aggregated api error, got %v", err)
+	}
+
+	if service.batchOpts.Concurrency != 2 || !service.batchOpts.FailFast {
+		t.Fatalf("unexpected batch options: %+v", service.batchOpts)
+	}
+	if len(service.batchIn) != 2 {
+		t.Fatalf("expected two manifest entries, got %d", len(service.batchIn))
+	}
+	web := service.batchIn[0]
+	if web.Name != "web" || web.AppDir != filepath.Join(dir, "web") || web.SakiControlPlaneURL != "https://cp.internal?token=test-token" {
+		t.Fatalf("unexpected web input: %+v", web)
+	}
+	if service.batchIn[1].AppDir != "/srv/api" {
+		t.Fatalf("expected absolute app_dir to be kept, got %q", service.batchIn[1].AppDir)
+	}
+
+	var outputs []contracts.DeployAppOutput
+	if err := json.Unmarshal(stdout.Bytes(), &outputs); err != nil {
+		t.Fatalf("expected JSON array output: %v", err)
+	}
+	if len(outputs) != 2 || outputs[0].Image != "registry.internal/owner/web:abc" {
+		t.Fatalf("unexpected outputs: %+v", outputs)
+	}
+}
+
+func TestRunDeploy_ManifestAppliesBuildFlagsToEveryEntry(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "apps.yaml")
+	manifest := `apps:
+  - name: web
+    description: Web frontend
+    app_dir: /srv/web
+  - name: api
+    description: API backend
+    app_dir: /srv/api
+`
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	service := &stubDeployService{}
+	err := runDeploy(context.Background(), []string{
+		"--manifest", manifestPath,
+		"--commit", "abc1234",
+		"--build-arg-file", "NPM_TOKEN=/run/secrets/npm",
+	}, nil, &bytes.Buffer{}, service)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(service.batchIn) != 2 {
+		t.Fatalf("expected two manifest entries, got %d", len(service.batchIn))
+	}
+	for _, in := range service.batchIn {
+		if in.GitCommit != "abc1234" || in.BuildArgFiles["NPM_TOKEN"] != "/run/secrets/npm" {
+			t.Fatalf("expected --commit and --build-arg-file on every entry, got %+v", in)
+		}
+	}
+
+	err = runDeploy(context.Background(), []string{
+		"--manifest", manifestPath,
+		"--image-repository", "ghcr.io/team/app",
+	}, nil, &bytes.Buffer{}, service)
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected --image-repository with --manifest to be rejected, got %v", err)
+	}
+}
+
+func TestRunDeploy_WritesSummaryFile(t *testing.T) {
+	t.Setenv("SAKI_DOCKER_REGISTRY", "registry.example.com")
+	service := &stubDeployService{
+		events: []tool.ProgressEvent{
+			{Stage: tool.StagePrepare, Status: tool.ProgressStarted},
+			{Stage: tool.StagePrepare, Status: tool.ProgressCompleted},
+		},
+		out: contracts.DeployAppOutput{
+			AppID:     "app_123",
+			Image:     "registry.example.com/owner/my-app:abc1234",
+			Status:    "deploying",
+			GitCommit: "abc1234def",
+			Timings:   map[string]int64{tool.StagePrepare: 120, "total": 500},
+		},
+	}
+
+	summaryPath := filepath.Join(t.TempDir(), "artifacts", "deploy", "summary.json")
+	err := runDeploy(context.Background(), []string{
+		"--name", "my-app",
+		"--summary-file", summaryPath,
+	}, nil, &bytes.Buffer{}, service)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatalf("read summary: %v", err)
+	}
+	var summary deploySummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("decode summary: %v", err)
+	}
+	if !reflect.DeepEqual(summary.DeployAppOutput, service.out) {
+		t.Fatalf("expected summary output %+v, got %+v", service.out, summary.DeployAppOutput)
+	}
+	if summary.Registry != "registry.example.com" {
+		t.Fatalf("unexpected registry: %q", summary.Registry)
+	}
+	if !reflect.DeepEqual(summary.DurationsMS, service.out.Timings) {
+		t.Fatalf("expected durations from the deploy timings %v, got %v", service.out.Timings, summary.DurationsMS)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(summaryPath))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected only the summary file to remain, got %v (%v)", entries, err)
+	}
+}
+
+func TestRunDeploy_SkipsSummaryFileOnFailure(t *testing.T) {
+	service := &stubDeployService{err: errors.New("deploy failed")}
+	summaryPath := filepath.Join(t.TempDir(), "summary.json")
+
+	err := runDeploy(context.Background(), []string{"--name", "my-app", "--summary-file", summaryPath}, nil, &bytes.Buffer{}, service)
+	if err == nil {
+		t.Fatal("expected deploy error")
+	}
+	if _, statErr := os.Stat(summaryPath); !os.IsNotExist(statErr) {
+		t.Fatalf("expected no summary file after failure, got %v", statErr)
+	}
+}
+
+func TestRunDeploy_ReadsInputFile(t *testing.T) {
+	inputPath := filepath.Join(t.TempDir(), "input.json")
+	input := `{"saki_control_plane_url":"https://cp.internal?token=test-token","name":"my-app","description":"internal app","app_dir":"/tmp/my-app","platforms":["linux/arm64"]}`
+	if err := os.WriteFile(inputPath, []byte(input), 0o644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	service := &stubDeployService{}
+	if err := runDeploy(context.Background(), []string{"--input-file", inputPath}, nil, &bytes.Buffer{}, service); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := contracts.DeployAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		Name:                "my-app",
+		Description:         "internal app",
+		AppDir:              "/tmp/my-app",
+	}
+	got := service.in
+	if len(got.Platforms) != 1 || got.Platforms[0] != "linux/arm64" {
+		t.Fatalf("expected platforms from input file, got %v", got.Platforms)
+	}
+	got.Platforms = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestRunDeploy_ReadsInputFromStdin(t *testing.T) {
+	stdin := strings.NewReader(`{"name":"my-app","description":"internal app","app_dir":"/tmp/my-app"}`)
+
+	service := &stubDeployService{}
+	if err := runDeploy(context.Background(), []string{"--input-file", "-"}, stdin, &bytes.Buffer{}, service); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if service.in.Name != "my-app" || service.in.AppDir != "/tmp/my-app" {
+		t.Fatalf("unexpected deploy input: %+v", service.in)
+	}
+}
+
+func TestRunDeploy_FlagsOverrideInputFile(t *testing.T) {
+	stdin := strings.NewReader(`{"name":"file-app","description":"from file","app_dir":"/tmp/file-app","platforms":["linux/arm64"]}`)
+
+	service := &stubDeployService{}
+	err := runDeploy(context.Background(), []string{
+		"--input-file", "-",
+		"--name", "flag-app",
+		"--platform", "linux/amd64",
+	}, stdin, &bytes.Buffer{}, service)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if service.in.Name != "flag-app" {
+		t.Fatalf("expected --name to override input file, got %q", service.in.Name)
+	}
+	if service.in.Description != "from file" || service.in.AppDir != "/tmp/file-app" {
+		t.Fatalf("expected unset flags to keep input file values, got %+v", service.in)
+	}
+	if len(service.in.Platforms) != 1 || service.in.Platforms[0] != "linux/amd64" {
+		t.Fatalf("expected --platform to override input file, got %v", service.in.Platforms)
+	}
+}
+
+func TestRunDeploy_ValidatesMergedInput(t *testing.T) {
+	stdin := strings.NewReader(`{"name":"Not_DNS","description":"internal app","app_dir":"/tmp/my-app"}`)
+
+	service := &stubDeployService{}
+	err := runDeploy(context.Background(), []string{"--input-file", "-"}, stdin, &bytes.Buffer{}, service)
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeInvalidInput, got, err)
+	}
+	if service.in.Name != "" {
+		t.Fatal("expected service not to be called for invalid input")
+	}
+
+	stdin = strings.NewReader(`{"name":"my-app","nmae":"typo"}`)
+	err = runDeploy(context.Background(), []string{"--input-file", "-"}, stdin, &bytes.Buffer{}, service)
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected unknown fields to be rejected, got %v", err)
+	}
+}
+
+func TestRunDeploy_TargetsFanOut(t *testing.T) {
+	service := &stubDeployService{
+		batchOuts: []contracts.DeployAppOutput{{AppID: "app_a"}, {AppID: "app_b"}},
+	}
+
+	var stdout bytes.Buffer
+	err := runDeploy(context.Background(), []string{
+		"--name", "my-app",
+		"--target", "https://a.internal?token=a",
+		"--target", "https://b.internal?token=b,registry=registry.prod",
+		"--fail-fast",
+	}, nil, &stdout, service)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(service.targets) != 2 || service.targets[1] != "https://b.internal?token=b" {
+		t.Fatalf("unexpected targets: %v", service.targets)
+	}
+	if !reflect.DeepEqual(service.fanOutOpts.Registries, []string{"", "registry.prod"}) {
+		t.Fatalf("unexpected target registries: %q", service.fanOutOpts.Registries)
+	}
+	if !service.fanOutOpts.FailFast || service.in.Name != "my-app" {
+		t.Fatalf("unexpected fan-out call: in=%+v opts=%+v", service.in, service.fanOutOpts)
+	}
+
+	var outputs []contracts.DeployAppOutput
+	if err := json.Unmarshal(stdout.Bytes(), &outputs); err != nil || len(outputs) != 2 {
+		t.Fatalf("expected JSON array of two outputs, got %q (%v)", stdout.String(), err)
+	}
+}
+
+func TestLoadManifest_RequiresApps(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "apps.yaml")
+	if err := os.WriteFile(manifestPath, []byte("apps: []\n"), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	_, err := loadManifest(manifestPath, "")
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected code %q, got %q", apperrors.CodeInvalidInput, got)
+	}
+}
+
+type stubDeployService struct {
+	in     contracts.DeployAppInput
+	events []tool.ProgressEvent
+	out    contracts.DeployAppOutput
+	err    error
+
+	batchIn   []contracts.DeployAppInput
+	batchOpts tool.BatchOptions
+	batchOuts []contracts.DeployAppOutput
+	batchErr  error
+
+	targets    []string
+	fanOutOpts tool.FanOutOptions
+}
+
+func (s *stubDeployService) DeployAppToTargets(_ context.Context, in contracts.DeployAppInput, targets []string, opts tool.FanOutOptions) ([]contracts.DeployAppOutput, error) {
+	s.in = in
+	s.targets = targets
+	s.fanOutOpts = opts
+	return s.batchOuts, s.batchErr
+}
+
+func (s *stubDeployService) DeployApps(_ context.Context, inputs []contracts.DeployAppInput, opts tool.BatchOptions) ([]contracts.DeployAppOutput, error) {
+	s.batchIn = inputs
+	s.batchOpts = opts
+	return s.batchOuts, s.batchErr
+}
+
+func (s *stubDeployService) DeployAppWithProgress(_ context.Context, in contracts.DeployAppInput, progress tool.ProgressFunc) (contracts.DeployAppOutput, error) {
+	s.in = in
+	for _, event := range s.events {
+		if progress != nil {
+			progress(event)
+		}
+	}
+	return s.out, s.err
+}
+
+func TestRunDeploy_DryRun(t *testing.T) {
+	service := &stubDeployService{}
+
+	err := runDeploy(context.Background(), []string{
+		"--name", "my-app",
+		"--description", "internal app",
+		"--app-dir", "/tmp/my-app",
+		"--dry-run",
+	}, nil, &bytes.Buffer{}, service)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !service.in.DryRun {
+		t.Fatal("expected --dry-run to set DryRun on the deploy input")
+	}
+
+	err = runDeploy(context.Background(), []string{
+		"--name", "my-app",
+		"--dry-run",
+		"--target", "https://a.internal?token=a",
+	}, nil, &bytes.Buffer{}, service)
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected --dry-run with --target to be rejected, got %v", err)
+	}
+}
+
+func TestRunDeploy_ValidateOnly(t *testing.T) {
+	service := &stubDeployService{}
+
+	err := runDeploy(context.Background(), []string{
+		"--name", "my-app",
+		"--description", "internal app",
+		"--app-dir", "/tmp/my-app",
+		"--validate-only",
+	}, nil, &bytes.Buffer{}, service)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !service.in.ValidateOnly {
+		t.Fatal("expected --validate-only to set ValidateOnly on the deploy input")
+	}
+
+	err = runDeploy(context.Background(), []string{
+		"--name", "my-app",
+		"--validate-only",
+		"--target", "https://a.internal?token=a",
+	}, nil, &bytes.Buffer{}, service)
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected --validate-only with --target to be rejected, got %v", err)
+	}
+}
+
+func TestRunDeploy_BuildArgFiles(t *testing.T) {
+	service := &stubDeployService{}
+
+	err := runDeploy(context.Background(), []string{
+		"--name", "my-app",
+		"--description", "internal app",
+		"--app-dir", "/tmp/my-app",
+		"--build-arg-file", "NPM_TOKEN=/run/secrets/npm",
+		"--build-arg-file", "CA_BUNDLE=certs/ca.pem",
+	}, nil, &bytes.Buffer{}, service)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	want := map[string]string{"NPM_TOKEN": "/run/secrets/npm", "CA_BUNDLE": "certs/ca.pem"}
+	if !maps.Equal(service.in.BuildArgFiles, want) {
+		t.Fatalf("expected build arg files %v, got %v", want, service.in.BuildArgFiles)
+	}
+
+	for _, args := range [][]string{
+		{"--build-arg-file", "NPM_TOKEN"},
+		{"--build-arg-file", "NPM_TOKEN=a", "--build-arg-file", "NPM_TOKEN=b"},
+	} {
+		err := runDeploy(context.Background(), append([]string{"--name", "my-app"}, args...), nil, &bytes.Buffer{}, service)
+		if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+			t.Fatalf("%v: expected invalid_input, got %v", args, err)
+		}
+	}
+}
+
+func TestRunDeploy_StateFileAndResume(t *testing.T) {
+	service := &stubDeployService{}
+
+	err := runDeploy(context.Background(), []string{
+		"--name", "my-app",
+		"--description", "internal app",
+		"--app-dir", "/tmp/my-app",
+		"--state-file", "/tmp/my-app.state.json",
+		"--resume",
+	}, nil, &bytes.Buffer{}, service)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if service.in.StateFile != "/tmp/my-app.state.json" || !service.in.Resume {
+		t.Fatalf("expected --state-file and --resume on the deploy input, got %+v", service.in)
+	}
+
+	err = runDeploy(context.Background(), []string{
+		"--name", "my-app",
+		"--state-file", "/tmp/my-app.state.json",
+		"--target", "https://a.internal?token=a",
+	}, nil, &bytes.Buffer{}, service)
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected --state-file with --target to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const doctorCheckTimeout = 10 * time.Second
+
+// doctorCheck is a single environment check run by `saki-tools doctor`.
+// Failing critical checks make the command exit non-zero.
+type doctorCheck struct {
+	name     string
+	critical bool
+	run      func(ctx context.Context) error
+}
+
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// doctorDeps holds the environment probes used by the default doctor checks.
+type doctorDeps struct {
+	lookPath        func(file string) (string, error)
+	runCommand      func(ctx context.Context, name string, args ...string) error
+	controlPlaneURL string
+	newControlPlane func(controlPlaneURL string) (pinger, error)
+	registry        string
+	httpClient      httpDoer
+}
+
+func defaultDoctorDeps(controlPlaneURL, registry string) doctorDeps {
+	return doctorDeps{
+		lookPath: exec.LookPath,
+		runCommand: func(ctx context.Context, name string, args ...string) error {
+			output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+			if err != nil {
+				return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+			}
+			return nil
+		},
+		controlPlaneURL: controlPlaneURL,
+		newControlPlane: func(controlPlaneURL string) (pinger, error) {
+			return controlplane.NewClient(controlPlaneURL)
+		},
+		registry:   registry,
+		httpClient: &http.Client{Timeout: doctorCheckTimeout},
+	}
+}
+
+func doctorChecks(deps doctorDeps) []doctorCheck {
+	return []doctorCheck{
+		{
+			name:     "docker",
+			critical: true,
+			run: func(ctx context.Context) error {
+				if _, err := deps.lookPath("docker"); err != nil {
+					return fmt.Errorf("docker CLI not found on PATH: %w", err)
+				}
+				if err := deps.runCommand(ctx, "docker", "info", "--format", "{{.ServerVersion}}"); err != nil {
+					return fmt.Errorf("docker daemon not responding (is it running?): %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name:     "git",
+			critical: true,
+			run: func(ctx context.Context) error {
+				if _, err := deps.lookPath("git"); err != nil {
+					return fmt.Errorf("git not found on PATH: %w", err)
+				}
+				return deps.runCommand(ctx, "git", "--version")
+			},
+		},
+		{
+			name:     "control plane",
+			critical: true,
+			run: func(ctx context.Context) error {
+				if strings.TrimSpace(deps.controlPlaneURL) == "" {
+					return fmt.Errorf("SAKI_CONTROL_PLANE_URL is not set and SAKI_CONTROL_PLANE_URL_FILE is unset or unreadable")
+				}
+				client, err := deps.newControlPlane(deps.controlPlaneURL)
+				if err != nil {
+					return err
+				}
+				return client.Ping(ctx)
+			},
+		},
+		{
+			name: "registry",
+			run: func(ctx context.Context) error {
+				return checkRegistry(ctx, deps.httpClient, deps.registry)
+			},
+		},
+	}
+}
+
+// checkRegistry requests the registry API root. Any response below 500
+// (including 401 for anonymous access) counts as reachable.
+func checkRegistry(ctx context.Context, client httpDoer, registry string) error {
+	endpoint := strings.TrimSpace(registry)
+	if endpoint == "" {
+		return fmt.Errorf("no registry configured")
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + strings.TrimRight(endpoint, "/") + "/v2/"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("registry %s returned status %d", endpoint, resp.StatusCode)
+	}
+	return nil
+}
+
+// runDoctor runs every check, printing one PASS/FAIL/WARN line per check.
+func runDoctor(ctx context.Context, stdout io.Writer, checks []doctorCheck) error {
+	failed := 0
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, doctorCheckTimeout)
+		err := check.run(checkCtx)
+		cancel()
+
+		switch {
+		case err == nil:
+			fmt.Fprintf(stdout, "PASS %s\n", check.name)
+		case check.critical:
+			failed++
+			fmt.Fprintf(stdout, "FAIL %s: %v\n", check.name, err)
+		default:
+			fmt.Fprintf(stdout, "WARN %s: %v\n", check.name, err)
+		}
+	}
+
+	if failed > 0 {
+		return apperrors.New(apperrors.CodeConfig, "doctor", fmt.Sprintf("%d critical check(s) failed", failed))
+	}
+	return nil
+}
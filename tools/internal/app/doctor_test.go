@@ -0,0 +1,123 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestRunDoctor_AllChecksPass(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer registry.Close()
+
+	deps := fakeDoctorDeps()
+	deps.registry = registry.URL + "/v2/"
+	deps.httpClient = registry.Client()
+
+	var stdout bytes.Buffer
+	if err := runDoctor(context.Background(), &stdout, doctorChecks(deps)); err != nil {
+		t.Fatalf("expected no error, got %v\n%s", err, stdout.String())
+	}
+
+	for _, line := range []string{"PASS docker", "PASS git", "PASS control plane", "PASS registry"} {
+		if !strings.Contains(stdout.String(), line) {
+			t.Fatalf("expected %q in output, got:\n%s", line, stdout.String())
+		}
+	}
+}
+
+func TestRunDoctor_ReportsMixedResults(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer registry.Close()
+
+	deps := fakeDoctorDeps()
+	deps.runCommand = func(_ context.Context, name string, _ ...string) error {
+		if name == "docker" {
+			return errors.New("Cannot connect to the Docker daemon")
+		}
+		return nil
+	}
+	deps.controlPlaneURL = ""
+	deps.registry = registry.URL + "/v2/"
+	deps.httpClient = registry.Client()
+
+	var stdout bytes.Buffer
+	err := runDoctor(context.Background(), &stdout, doctorChecks(deps))
+	if got := apperrors.CodeOf(err); got != apperrors.CodeConfig {
+		t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeConfig, got, err)
+	}
+	if !strings.Contains(err.Error(), "2 critical check(s) failed") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := stdout.String()
+	for _, line := range []string{
+		"FAIL docker: docker daemon not responding",
+		"PASS git",
+		"FAIL control plane: SAKI_CONTROL_PLANE_URL is not set",
+		"WARN registry:",
+	} {
+		if !strings.Contains(output, line) {
+			t.Fatalf("expected %q in output, got:\n%s", line, output)
+		}
+	}
+}
+
+func TestRunDoctor_ControlPlanePingFailure(t *testing.T) {
+	deps := fakeDoctorDeps()
+	deps.newControlPlane = func(string) (pinger, error) {
+		return stubPinger{err: errors.New("connection refused")}, nil
+	}
+
+	var stdout bytes.Buffer
+	checks := doctorChecks(deps)[2:3]
+	if err := runDoctor(context.Background(), &stdout, checks); err == nil {
+		t.Fatal("expected ping failure to fail the doctor run")
+	}
+	if !strings.Contains(stdout.String(), "FAIL control plane: connection refused") {
+		t.Fatalf("unexpected output: %s", stdout.String())
+	}
+}
+
+func TestRunDoctor_MissingBinary(t *testing.T) {
+	deps := fakeDoctorDeps()
+	deps.lookPath = func(file string) (string, error) {
+		if file == "git" {
+			return "", errors.New("executable file not found in $PATH")
+		}
+		return "/usr/bin/" + file, nil
+	}
+
+	var stdout bytes.Buffer
+	_ = runDoctor(context.Background(), &stdout, doctorChecks(deps)[:2])
+	if !strings.Contains(stdout.String(), "FAIL git: git not found on PATH") {
+		t.Fatalf("unexpected output: %s", stdout.String())
+	}
+}
+
+func fakeDoctorDeps() doctorDeps {
+	return doctorDeps{
+		lookPath:        func(file string) (string, error) { return "/usr/bin/" + file, nil },
+		runCommand:      func(context.Context, string, ...string) error { return nil },
+		controlPlaneURL: "https://cp.internal?token=test-token",
+		newControlPlane: func(string) (pinger, error) { return stubPinger{}, nil },
+	}
+}
+
+type stubPinger struct {
+	err error
+}
+
+func (s stubPinger) Ping(context.Context) error {
+	return s.err
+}
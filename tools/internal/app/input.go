@@ -0,0 +1,90 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// stdinInputPath selects stdin for --input-file.
+const stdinInputPath = "-"
+
+// readDeployInput decodes a JSON DeployAppInput from path, or from stdin when
+// path is "-". Unknown fields are rejected, matching the MCP tool schema.
+func readDeployInput(path string, stdin io.Reader) (contracts.DeployAppInput, error) {
+	var in contracts.DeployAppInput
+
+	var data []byte
+	var err error
+	if path == stdinInputPath {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return in, apperrors.Wrap(apperrors.CodeInvalidInput, "read deploy input", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&in); err != nil {
+		return in, apperrors.Wrap(apperrors.CodeInvalidInput, "parse deploy input", fmt.Errorf("%s: %w", path, err))
+	}
+	return in, nil
+}
+
+// mergeDeployInput overlays the explicitly set flags onto base. set holds the
+// flag names passed on the command line.
+func mergeDeployInput(base, flags contracts.DeployAppInput, set map[string]bool) contracts.DeployAppInput {
+	if set["control-plane-url"] {
+		base.SakiControlPlaneURL = flags.SakiControlPlaneURL
+	}
+	if set["name"] {
+		base.Name = flags.Name
+	}
+	if set["description"] {
+		base.Description = flags.Description
+	}
+	if set["org"] {
+		base.Org = flags.Org
+	}
+	if set["note"] {
+		base.Note = flags.Note
+	}
+	if set["app-dir"] {
+		base.AppDir = flags.AppDir
+	}
+	if set["platform"] {
+		base.Platforms = flags.Platforms
+	}
+	if set["image-repository"] {
+		base.ImageRepository = flags.ImageRepository
+	}
+	if set["commit"] {
+		base.GitCommit = flags.GitCommit
+	}
+	if set["validate-only"] {
+		base.ValidateOnly = flags.ValidateOnly
+	}
+	if set["dry-run"] {
+		base.DryRun = flags.DryRun
+	}
+	if set["force"] {
+		base.Force = flags.Force
+	}
+	if set["state-file"] {
+		base.StateFile = flags.StateFile
+	}
+	if set["resume"] {
+		base.Resume = flags.Resume
+	}
+	if set["build-arg-file"] {
+		base.BuildArgFiles = flags.BuildArgFiles
+	}
+	return base
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+	"gopkg.in/yaml.v3"
+)
+
+// manifest lists the apps deployed by `saki-tools deploy --manifest`.
+type manifest struct {
+	Apps []manifestApp `yaml:"apps"`
+}
+
+type manifestApp struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	AppDir      string `yaml:"app_dir"`
+}
+
+// loadManifest reads a YAML (or JSON) manifest. Relative app_dir values are
+// resolved against the manifest's directory.
+func loadManifest(path, controlPlaneURL string) ([]contracts.DeployAppInput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidInput, "read deploy manifest", err)
+	}
+
+	var m manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidInput, "parse deploy manifest", fmt.Errorf("%s: %w", path, err))
+	}
+	if len(m.Apps) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "parse deploy manifest", "manifest must list at least one app under apps")
+	}
+
+	baseDir := filepath.Dir(path)
+	inputs := make([]contracts.DeployAppInput, 0, len(m.Apps))
+	for _, app := range m.Apps {
+		appDir := strings.TrimSpace(app.AppDir)
+		if appDir != "" && !filepath.IsAbs(appDir) {
+			appDir = filepath.Join(baseDir, appDir)
+		}
+
+		inputs = append(inputs, contracts.DeployAppInput{
+			SakiControlPlaneURL: controlPlaneURL,
+			Name:                strings.TrimSpace(app.Name),
+			Description:         strings.TrimSpace(app.Description),
+			AppDir:              appDir,
+		})
+	}
+
+	return inputs, nil
+}
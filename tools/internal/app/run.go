@@ -3,11 +3,14 @@ package app
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/1800agents/saki/tools/internal/apperrors"
 	"github.com/1800agents/saki/tools/internal/config"
 	"github.com/1800agents/saki/tools/internal/logging"
+	"github.com/1800agents/saki/tools/internal/template"
 	"github.com/1800agents/saki/tools/internal/tool"
+	"github.com/1800agents/saki/tools/internal/version"
 )
 
 func Run(ctx context.Context, args []string) error {
@@ -16,7 +19,45 @@ func Run(ctx context.Context, args []string) error {
 	service := tool.NewService()
 
 	if len(args) > 0 && args[0] == "version" {
-		fmt.Println("saki-tools dev")
+		fmt.Println("saki-tools " + version.Version)
+		return nil
+	}
+
+	if len(args) > 0 && args[0] == "doctor" {
+		return runDoctor(ctx, os.Stdout, doctorChecks(defaultDoctorDeps(tool.ControlPlaneURL(), tool.DockerRegistry())))
+	}
+
+	if len(args) > 0 && args[0] == "workflow" {
+		return runWorkflow(args[1:], os.Stdout)
+	}
+
+	if len(args) > 0 && args[0] == "template" {
+		return runTemplate(ctx, args[1:], os.Stdout, template.Validate)
+	}
+
+	if len(args) > 0 && args[0] == "config" {
+		return runConfig(args[1:], os.Stdout, cfg, service)
+	}
+
+	if len(args) > 0 && args[0] == "cancel" {
+		if err := runCancel(ctx, args[1:], os.Stdout, service); err != nil {
+			logger.Error("cancel failed", map[string]any{
+				"code":  apperrors.CodeOf(err),
+				"error": err.Error(),
+			})
+			return err
+		}
+		return nil
+	}
+
+	if len(args) > 0 && args[0] == "deploy" {
+		if err := runDeploy(ctx, args[1:], os.Stdin, os.Stdout, service); err != nil {
+			logger.Error("deploy failed", map[string]any{
+				"code":  apperrors.CodeOf(err),
+				"error": err.Error(),
+			})
+			return err
+		}
 		return nil
 	}
 
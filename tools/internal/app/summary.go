@@ -0,0 +1,68 @@
+package app
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// deploySummary is the --summary-file artifact: the deploy output plus the
+// context CI needs without parsing stdout.
+type deploySummary struct {
+	contracts.DeployAppOutput
+	Registry string `json:"registry"`
+	// DurationsMS maps each completed stage, plus "total", to milliseconds.
+	DurationsMS map[string]int64 `json:"durations_ms"`
+}
+
+// newDeploySummary builds the summary for out. Durations come from the
+// deploy's own stage timings, so they match timings_ms.
+func newDeploySummary(out contracts.DeployAppOutput, registry string) deploySummary {
+	durations := make(map[string]int64, len(out.Timings))
+	maps.Copy(durations, out.Timings)
+	return deploySummary{
+		DeployAppOutput: out,
+		Registry:        registry,
+		DurationsMS:     durations,
+	}
+}
+
+// writeSummaryFile writes summary as JSON to path, creating parent
+// directories. The file is written to a temp file and renamed into place so
+// readers never observe a partial summary.
+func writeSummaryFile(path string, summary deploySummary) error {
+	payload, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return apperrors.Wrap(apperrors.CodeInternal, "marshal deploy summary", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return apperrors.Wrap(apperrors.CodeConfig, "create summary directory", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return apperrors.Wrap(apperrors.CodeConfig, "create summary file", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(payload, '\n')); err != nil {
+		tmp.Close()
+		return apperrors.Wrap(apperrors.CodeInternal, "write summary file", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return apperrors.Wrap(apperrors.CodeInternal, "write summary file", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return apperrors.Wrap(apperrors.CodeInternal, "write summary file", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return apperrors.Wrap(apperrors.CodeInternal, "write summary file", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/template"
+)
+
+const templateValidateUsage = "usage: saki-tools template validate <repo-url> [--ref <ref>] [--lint-env]"
+
+type templateValidator func(ctx context.Context, repository, ref string, opts template.ValidateOptions) (template.Validation, error)
+
+// runTemplate implements `saki-tools template validate <repo-url> [--ref <ref>] [--lint-env]`.
+// It prints one PASS/FAIL/WARN/SKIP line per check, like doctor, and fails
+// with CodeTemplate when any check fails.
+func runTemplate(ctx context.Context, args []string, stdout io.Writer, validate templateValidator) error {
+	if len(args) == 0 || args[0] != "validate" {
+		return apperrors.New(apperrors.CodeInvalidInput, "parse template command", templateValidateUsage)
+	}
+
+	fs := flag.NewFlagSet("template validate", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	ref := fs.String("ref", "", "branch, tag, or commit to check out after cloning")
+	lintEnv := fs.Bool("lint-env", false, "warn about .env keys that deploys drop")
+
+	// The repository comes before the flags in the usage line, so keep
+	// parsing after each positional argument.
+	var positional []string
+	rest := args[1:]
+	for {
+		if err := fs.Parse(rest); err != nil {
+			return apperrors.Wrap(apperrors.CodeInvalidInput, "parse template flags", err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		rest = fs.Args()[1:]
+	}
+	if len(positional) != 1 || strings.TrimSpace(positional[0]) == "" {
+		return apperrors.New(apperrors.CodeInvalidInput, "parse template command", templateValidateUsage)
+	}
+
+	report, err := validate(ctx, strings.TrimSpace(positional[0]), strings.TrimSpace(*ref), template.ValidateOptions{LintEnv: *lintEnv})
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, check := range report.Checks {
+		line := strings.ToUpper(string(check.Status)) + " " + check.Name
+		if check.Detail != "" {
+			line += ": " + check.Detail
+		}
+		fmt.Fprintln(stdout, line)
+		if check.Status == template.CheckFail {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return apperrors.New(apperrors.CodeTemplate, "validate template", fmt.Sprintf("%d check(s) failed", failed))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/template"
+)
+
+func TestRunTemplate_ValidatesRepository(t *testing.T) {
+	var gotRepo, gotRef string
+	var gotOpts template.ValidateOptions
+	validate := func(_ context.Context, repository, ref string, opts template.ValidateOptions) (template.Validation, error) {
+		gotRepo, gotRef, gotOpts = repository, ref, opts
+		return template.Validation{Checks: []template.Check{
+			{Name: "clone", Status: template.CheckPass},
+			{Name: "dockerfile", Status: template.CheckPass},
+			{Name: "env", Status: template.CheckWarn, Detail: "API_KEY would be dropped"},
+		}}, nil
+	}
+
+	var stdout bytes.Buffer
+	err := runTemplate(context.Background(), []string{"validate", "https://github.com/acme/template", "--ref", "v2", "--lint-env"}, &stdout, validate)
+	if err != nil {
+		t.Fatalf("run template: %v", err)
+	}
+	if gotRepo != "https://github.com/acme/template" || gotRef != "v2" || !gotOpts.LintEnv {
+		t.Fatalf("unexpected validate call: repo=%q ref=%q opts=%+v", gotRepo, gotRef, gotOpts)
+	}
+	for _, line := range []string{"PASS clone", "PASS dockerfile", "WARN env: API_KEY would be dropped"} {
+		if !strings.Contains(stdout.String(), line) {
+			t.Fatalf("expected %q in output, got:\n%s", line, stdout.String())
+		}
+	}
+}
+
+func TestRunTemplate_FailsOnFailedCheck(t *testing.T) {
+	validate := func(context.Context, string, string, template.ValidateOptions) (template.Validation, error) {
+		return template.Validation{Checks: []template.Check{
+			{Name: "clone", Status: template.CheckPass},
+			{Name: "dockerfile", Status: template.CheckFail, Detail: "no Dockerfile at the repository root"},
+		}}, nil
+	}
+
+	var stdout bytes.Buffer
+	err := runTemplate(context.Background(), []string{"validate", "https://github.com/acme/template"}, &stdout, validate)
+	if got := apperrors.CodeOf(err); got != apperrors.CodeTemplate {
+		t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeTemplate, got, err)
+	}
+	if !strings.Contains(stdout.String(), "FAIL dockerfile: no Dockerfile") {
+		t.Fatalf("expected the failed check in output, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunTemplate_RequiresRepository(t *testing.T) {
+	for _, args := range [][]string{nil, {"validate"}, {"lint", "repo"}, {"validate", "a", "b"}} {
+		err := runTemplate(context.Background(), args, &bytes.Buffer{}, template.Validate)
+		if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+			t.Fatalf("%v: expected code %q, got %q", args, apperrors.CodeInvalidInput, got)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package app
+
+import (
+	"io"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/mcp"
+)
+
+// runWorkflow implements `saki-tools workflow`: it prints the deploy workflow
+// document that MCP clients read as saki://deploy-workflow.
+func runWorkflow(args []string, stdout io.Writer) error {
+	if len(args) > 0 {
+		return apperrors.New(apperrors.CodeInvalidInput, "parse workflow command", "usage: saki-tools workflow")
+	}
+	if _, err := io.WriteString(stdout, mcp.DeployWorkflowDocument()+"\n"); err != nil {
+		return apperrors.Wrap(apperrors.CodeInternal, "write workflow document", err)
+	}
+	return nil
+}
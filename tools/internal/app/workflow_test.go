@@ -0,0 +1,40 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestRunWorkflow_PrintsDeployWorkflowDocument(t *testing.T) {
+	var stdout bytes.Buffer
+	if err := runWorkflow(nil, &stdout); err != nil {
+		t.Fatalf("runWorkflow returned error: %v", err)
+	}
+
+	doc := stdout.String()
+	for _, phrase := range []string{
+		"Clone the template repository",
+		"Agent-side preparation steps",
+		"Tool-side execution steps",
+		"app_dir",
+		"docker build",
+		"docker push",
+		"POST /apps/prepare",
+		"POST /apps",
+		"https://github.com/1800agents/saki-app-template",
+	} {
+		if !strings.Contains(doc, phrase) {
+			t.Fatalf("expected workflow doc to include %q, got %s", phrase, doc)
+		}
+	}
+}
+
+func TestRunWorkflow_RejectsArguments(t *testing.T) {
+	err := runWorkflow([]string{"extra"}, &bytes.Buffer{})
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidInput {
+		t.Fatalf("expected %s, got %v", apperrors.CodeInvalidInput, err)
+	}
+}
@@ -3,6 +3,7 @@ package apperrors
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // Code identifies broad classes of internal tool failures.
@@ -13,9 +14,13 @@ const (
 	CodeConfig          Code = "config_error"
 	CodeTemplate        Code = "template_error"
 	CodeDocker          Code = "docker_error"
+	CodeDiskFull        Code = "disk_full"
+	CodeConflict        Code = "conflict"
 	CodeControlPlane    Code = "control_plane_error"
 	CodeControlPlaneAPI Code = "control_plane_api_error"
+	CodeRateLimited     Code = "rate_limited"
 	CodeTimeout         Code = "timeout"
+	CodeUnhealthy       Code = "unhealthy"
 	CodeInternal        Code = "internal_error"
 )
 
@@ -86,6 +91,63 @@ func Wrap(code Code, op string, err error) error {
 	}
 }
 
+// Multi aggregates independent failures, such as per-app errors in a batch deploy.
+type Multi struct {
+	Errors []error
+}
+
+func (m *Multi) Error() string {
+	if m == nil || len(m.Errors) == 0 {
+		return ""
+	}
+	if len(m.Errors) == 1 {
+		return m.Errors[0].Error()
+	}
+
+	messages := make([]string, 0, len(m.Errors))
+	for _, err := range m.Errors {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("%d errors: %s", len(m.Errors), strings.Join(messages, "; "))
+}
+
+func (m *Multi) Unwrap() []error {
+	if m == nil {
+		return nil
+	}
+	return m.Errors
+}
+
+// ErrorCode returns the code shared by every aggregated error, or CodeInternal
+// when they disagree.
+func (m *Multi) ErrorCode() Code {
+	if m == nil || len(m.Errors) == 0 {
+		return CodeInternal
+	}
+
+	code := CodeOf(m.Errors[0])
+	for _, err := range m.Errors[1:] {
+		if CodeOf(err) != code {
+			return CodeInternal
+		}
+	}
+	return code
+}
+
+// NewMulti aggregates the non-nil errors, returning nil when there are none.
+func NewMulti(errs ...error) error {
+	nonNil := make([]error, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			nonNil = append(nonNil, err)
+		}
+	}
+	if len(nonNil) == 0 {
+		return nil
+	}
+	return &Multi{Errors: nonNil}
+}
+
 // CodeOf returns the shared internal code if present.
 func CodeOf(err error) Code {
 	if err == nil {
@@ -27,3 +27,29 @@ func TestCodeOfUnknownErrorDefaultsInternal(t *testing.T) {
 		t.Fatalf("expected %q, got %q", CodeInternal, got)
 	}
 }
+
+func TestNewMulti(t *testing.T) {
+	if err := NewMulti(nil, nil); err != nil {
+		t.Fatalf("expected nil for no errors, got %v", err)
+	}
+
+	first := Wrap(CodeDocker, "docker build", errors.New("boom"))
+	second := New(CodeDocker, "docker push", "denied")
+	err := NewMulti(first, nil, second)
+
+	var multi *Multi
+	if !errors.As(err, &multi) || len(multi.Errors) != 2 {
+		t.Fatalf("expected two aggregated errors, got %v", err)
+	}
+	if !errors.Is(err, first) || !errors.Is(err, second) {
+		t.Fatal("expected aggregated errors to be reachable via errors.Is")
+	}
+	if got := CodeOf(err); got != CodeDocker {
+		t.Fatalf("expected shared code %q, got %q", CodeDocker, got)
+	}
+
+	mixed := NewMulti(first, New(CodeTimeout, "deploy", "too slow"))
+	if got := CodeOf(mixed); got != CodeInternal {
+		t.Fatalf("expected %q for mixed codes, got %q", CodeInternal, got)
+	}
+}
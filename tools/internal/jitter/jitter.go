@@ -0,0 +1,29 @@
+// Package jitter randomizes retry waits so many agents retrying the same
+// failure do not hit the control plane or registry in lockstep.
+package jitter
+
+import (
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Func maps a computed retry delay to the delay actually waited.
+type Func func(d time.Duration) time.Duration
+
+// Full returns a Func that picks a delay uniformly in [0, d]. A nil src uses
+// the process-wide random source; tests pass a seeded one.
+func Full(src *rand.Rand) Func {
+	var mu sync.Mutex
+	return func(d time.Duration) time.Duration {
+		if d <= 0 {
+			return 0
+		}
+		if src == nil {
+			return time.Duration(rand.Int64N(int64(d) + 1))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return time.Duration(src.Int64N(int64(d) + 1))
+	}
+}
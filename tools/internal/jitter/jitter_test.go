@@ -0,0 +1,41 @@
+package jitter
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestFull_StaysWithinDelay(t *testing.T) {
+	const delay = time.Second
+	jitter := Full(rand.New(rand.NewPCG(1, 2)))
+	replay := Full(rand.New(rand.NewPCG(1, 2)))
+
+	distinct := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		got := jitter(delay)
+		if got < 0 || got > delay {
+			t.Fatalf("jittered delay %s outside [0, %s]", got, delay)
+		}
+		if again := replay(delay); again != got {
+			t.Fatalf("expected seeded source to be deterministic, got %s then %s", got, again)
+		}
+		distinct[got] = true
+	}
+	if len(distinct) < 50 {
+		t.Fatalf("expected jittered delays to vary, got %d distinct values", len(distinct))
+	}
+
+	if got := jitter(0); got != 0 {
+		t.Fatalf("expected zero delay to stay zero, got %s", got)
+	}
+}
+
+func TestFull_DefaultSourceStaysWithinDelay(t *testing.T) {
+	jitter := Full(nil)
+	for i := 0; i < 100; i++ {
+		if got := jitter(time.Second); got < 0 || got > time.Second {
+			t.Fatalf("jittered delay %s outside [0, 1s]", got)
+		}
+	}
+}
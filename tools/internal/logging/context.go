@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"maps"
+)
+
+const (
+	deployNameField = "deploy_name"
+	deployIDField   = "deploy_id"
+)
+
+type deployContextKey struct{}
+
+type deployContext struct {
+	name string
+	id   string
+}
+
+// WithDeployContext attaches the app name and deploy ID of the deploy in
+// progress to ctx, so component loggers can tag their log lines without the
+// values being threaded through every call.
+func WithDeployContext(ctx context.Context, name, id string) context.Context {
+	return context.WithValue(ctx, deployContextKey{}, deployContext{name: name, id: id})
+}
+
+// DeployName returns the app name attached by WithDeployContext, or "".
+func DeployName(ctx context.Context) string {
+	dc, _ := ctx.Value(deployContextKey{}).(deployContext)
+	return dc.name
+}
+
+// DeployID returns the deploy ID attached by WithDeployContext, or "".
+func DeployID(ctx context.Context) string {
+	dc, _ := ctx.Value(deployContextKey{}).(deployContext)
+	return dc.id
+}
+
+// DeployFields returns fields with deploy_name and deploy_id from ctx added.
+// fields is not modified; without a deploy context it is returned as is.
+func DeployFields(ctx context.Context, fields map[string]any) map[string]any {
+	dc, ok := ctx.Value(deployContextKey{}).(deployContext)
+	if !ok {
+		return fields
+	}
+
+	out := make(map[string]any, len(fields)+2)
+	maps.Copy(out, fields)
+	if dc.name != "" {
+		out[deployNameField] = dc.name
+	}
+	if dc.id != "" {
+		out[deployIDField] = dc.id
+	}
+	return out
+}
+
+// NewDeployID returns a random 16-character hex ID for one deploy.
+func NewDeployID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,27 @@
+package logging
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDeployFields_AddsDeployContext(t *testing.T) {
+	fields := map[string]any{"op": "build"}
+
+	if got := DeployFields(context.Background(), fields); len(got) != 1 {
+		t.Fatalf("expected fields unchanged without deploy context, got %v", got)
+	}
+
+	ctx := WithDeployContext(context.Background(), "my-app", "d1")
+	if DeployName(ctx) != "my-app" || DeployID(ctx) != "d1" {
+		t.Fatalf("unexpected deploy context: name=%q id=%q", DeployName(ctx), DeployID(ctx))
+	}
+
+	got := DeployFields(ctx, fields)
+	if got["deploy_name"] != "my-app" || got["deploy_id"] != "d1" || got["op"] != "build" {
+		t.Fatalf("unexpected fields: %v", got)
+	}
+	if _, ok := fields["deploy_id"]; ok {
+		t.Fatal("expected input fields to be left unmodified")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -33,7 +34,7 @@ func NewWithWriter(w io.Writer) *Logger {
 			if a.Value.Kind() != slog.KindString {
 				return a
 			}
-			return slog.String(a.Key, redactSecrets(a.Value.String()))
+			return slog.String(a.Key, RedactSecrets(a.Value.String()))
 		},
 	})
 
@@ -72,7 +73,7 @@ func attrs(fields map[string]any) []any {
 	for _, key := range keys {
 		value := fields[key]
 		if s, ok := value.(string); ok {
-			value = redactSecrets(s)
+			value = RedactSecrets(s)
 		}
 		out = append(out, key, value)
 	}
@@ -80,7 +81,23 @@ func attrs(fields map[string]any) []any {
 	return out
 }
 
-func redactSecrets(s string) string {
+// credentialPatterns match credentials that are not a key=value pair: user
+// info in URLs, JWTs, and quoted secret fields in JSON (as in docker config
+// or registry error bodies).
+var credentialPatterns = []struct {
+	pattern *regexp.Regexp
+	replace string
+}{
+	{pattern: regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/\s:@]+:[^/\s@]+@`), replace: "${1}<redacted>@"},
+	{pattern: regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), replace: "<redacted>"},
+	{pattern: regexp.MustCompile(`(?i)("(?:auth|identitytoken|password|secret|token)"\s*:\s*")[^"]*`), replace: "${1}<redacted>"},
+}
+
+// RedactSecrets replaces credential-looking substrings of s with
+// "<redacted>": values after token=, password=, passwd=, secret=, and
+// "bearer ", URL user info, JWTs, and quoted JSON secret fields. It is
+// applied to every logged string and to command output embedded in errors.
+func RedactSecrets(s string) string {
 	replacements := []struct {
 		key string
 	}{
@@ -88,12 +105,16 @@ func redactSecrets(s string) string {
 		{key: "password="},
 		{key: "passwd="},
 		{key: "secret="},
+		{key: "bearer "},
 	}
 
 	redacted := s
 	for _, item := range replacements {
 		redacted = redactValue(redacted, item.key)
 	}
+	for _, item := range credentialPatterns {
+		redacted = item.pattern.ReplaceAllString(redacted, item.replace)
+	}
 
 	return redacted
 }
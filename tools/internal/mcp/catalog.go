@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	resourceURITools         = "saki://tools"
+	resourceNameTools        = "saki_tools"
+	resourceDescriptionTools = "JSON catalog of every tool this server registers, with names, descriptions, and input schemas."
+)
+
+// toolCatalogEntry describes one registered tool in the saki://tools resource.
+type toolCatalogEntry struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	InputSchema any    `json:"input_schema"`
+}
+
+// addTool registers a tool with the SDK server and records its definition for
+// the saki://tools catalog, so the catalog cannot drift from registration.
+func addTool[In, Out any](s *Server, tool *sdkmcp.Tool, handler sdkmcp.ToolHandlerFor[In, Out]) {
+	sdkmcp.AddTool(s.sdkServer, tool, handler)
+	s.tools = append(s.tools, tool)
+}
+
+func toolCatalogResourceDefinition() *sdkmcp.Resource {
+	return &sdkmcp.Resource{
+		URI:         resourceURITools,
+		Name:        resourceNameTools,
+		Title:       "Saki Tool Catalog",
+		Description: resourceDescriptionTools,
+		MIMEType:    "application/json",
+	}
+}
+
+func (s *Server) toolCatalogResourceHandler(_ context.Context, req *sdkmcp.ReadResourceRequest) (*sdkmcp.ReadResourceResult, error) {
+	if req == nil || req.Params == nil || req.Params.URI != resourceURITools {
+		uri := ""
+		if req != nil && req.Params != nil {
+			uri = req.Params.URI
+		}
+		return nil, sdkmcp.ResourceNotFoundError(uri)
+	}
+
+	entries := make([]toolCatalogEntry, 0, len(s.tools))
+	for _, tool := range s.tools {
+		entries = append(entries, toolCatalogEntry{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.InputSchema,
+		})
+	}
+
+	payload, err := json.MarshalIndent(map[string]any{"tools": entries}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return &sdkmcp.ReadResourceResult{
+		Contents: []*sdkmcp.ResourceContents{
+			{
+				URI:      resourceURITools,
+				MIMEType: "application/json",
+				Text:     string(payload),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,71 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/1800agents/saki/tools/contracts"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const toolNameSakiCheckName = "saki_check_name"
+
+// nameChecker is implemented by services that can look up app name
+// availability. The saki_check_name tool is only registered for them.
+type nameChecker interface {
+	CheckName(ctx context.Context, in contracts.CheckNameInput) (contracts.CheckNameOutput, error)
+}
+
+func checkNameToolDefinition() *sdkmcp.Tool {
+	return &sdkmcp.Tool{
+		Name:        toolNameSakiCheckName,
+		Description: "Check whether an app name can be deployed before building: available is true when nobody uses the name, owned_by_you is true when the caller already owns it (deploying updates it). When both are false the name belongs to someone else; ask the user for a different name.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"saki_control_plane_url": map[string]any{
+					"type":        "string",
+					"description": "Tokenized Saki control plane URL. Example: https://saki.internal/api?token=<uuid>.",
+					"minLength":   1,
+				},
+				"name": map[string]any{
+					"type":        "string",
+					"description": "DNS-safe app name to check. Example: team-dashboard.",
+					"pattern":     "^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$",
+					"maxLength":   63,
+				},
+			},
+			"required":             []string{"name"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+func (s *Server) handleCheckName(checker nameChecker) sdkmcp.ToolHandlerFor[contracts.CheckNameInput, contracts.CheckNameOutput] {
+	return func(ctx context.Context, _ *sdkmcp.CallToolRequest, in contracts.CheckNameInput) (*sdkmcp.CallToolResult, contracts.CheckNameOutput, error) {
+		in.SakiControlPlaneURL = strings.TrimSpace(in.SakiControlPlaneURL)
+		in.Name = strings.TrimSpace(in.Name)
+		s.logger.Info("tool call requested", map[string]any{
+			"tool": toolNameSakiCheckName,
+			"name": in.Name,
+		})
+
+		output, err := checker.CheckName(ctx, in)
+		if err != nil {
+			s.logger.Error("name check failed", map[string]any{
+				"name":  in.Name,
+				"error": err.Error(),
+			})
+			return nil, contracts.CheckNameOutput{}, err
+		}
+
+		payload, err := json.Marshal(output)
+		if err != nil {
+			return nil, contracts.CheckNameOutput{}, err
+		}
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: string(payload)}},
+		}, output, nil
+	}
+}
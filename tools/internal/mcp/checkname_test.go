@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/1800agents/saki/tools/contracts"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCheckNameTool_ReportsAvailability(t *testing.T) {
+	service := &nameCheckingDeployService{
+		output: contracts.CheckNameOutput{Name: "my-app", OwnedByYou: true},
+	}
+	_, session, _, _ := serveTestServer(t, service, time.Second, nil)
+
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	if !slices.Contains(names, toolNameSakiCheckName) || !slices.Contains(names, toolNameSakiDeployApp) {
+		t.Fatalf("expected %s to be registered, got %v", toolNameSakiCheckName, names)
+	}
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      toolNameSakiCheckName,
+		Arguments: map[string]any{"name": "my-app"},
+	})
+	if err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected success, got %+v", result.Content)
+	}
+	if service.input.Name != "my-app" {
+		t.Fatalf("expected name to reach the service, got %q", service.input.Name)
+	}
+
+	var out contracts.CheckNameOutput
+	text := result.Content[0].(*sdkmcp.TextContent).Text
+	if err := json.Unmarshal([]byte(text), &out); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if out != service.output {
+		t.Fatalf("unexpected output: got %+v want %+v", out, service.output)
+	}
+}
+
+type nameCheckingDeployService struct {
+	recordingDeployService
+	input  contracts.CheckNameInput
+	output contracts.CheckNameOutput
+}
+
+func (s *nameCheckingDeployService) CheckName(_ context.Context, in contracts.CheckNameInput) (contracts.CheckNameOutput, error) {
+	s.input = in
+	return s.output, nil
+}
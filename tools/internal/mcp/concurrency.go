@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const deployQueuedMessage = "deploy queued: waiting for a free deploy slot"
+
+// acquireDeploySlot waits for one of the server's deploy slots. When none is
+// free, the call is queued and the client is notified through MCP progress if
+// it asked for progress. Queued calls give up when ctx ends or the server
+// starts shutting down. The returned func releases the slot.
+func (s *Server) acquireDeploySlot(ctx context.Context, req *sdkmcp.CallToolRequest) (func(), error) {
+	release := func() { <-s.slots }
+
+	select {
+	case s.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	s.logger.Info("deploy queued", map[string]any{
+		"max_concurrency": cap(s.slots),
+	})
+	s.notifyProgress(ctx, req, deployQueuedMessage)
+
+	select {
+	case s.slots <- struct{}{}:
+		s.logger.Info("deploy dequeued", nil)
+		return release, nil
+	case <-s.closed:
+		return nil, errShuttingDown
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// notifyProgress sends an MCP progress notification when the tool call
+// carries a progress token. Delivery failures are logged and ignored.
+func (s *Server) notifyProgress(ctx context.Context, req *sdkmcp.CallToolRequest, message string) {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return
+	}
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return
+	}
+
+	err := req.Session.NotifyProgress(ctx, &sdkmcp.ProgressNotificationParams{
+		ProgressToken: token,
+		Message:       message,
+	})
+	if err != nil {
+		s.logger.Error("progress notification failed", map[string]any{"error": err.Error()})
+	}
+}
+
+// envPositiveInt parses key as a positive integer, falling back to
+// defaultValue (and logging why) when it is unset or invalid.
+func envPositiveInt(logger Logger, key string, defaultValue int) int {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		logger.Error("invalid positive integer; using default", map[string]any{
+			"env":     key,
+			"value":   v,
+			"default": defaultValue,
+		})
+		return defaultValue
+	}
+	return n
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/1800agents/saki/tools/contracts"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const toolNameSakiDeleteApp = "saki_delete_app"
+
+// appDeleter is implemented by services that can tear down apps. The
+// saki_delete_app tool is only registered for them.
+type appDeleter interface {
+	DeleteApp(ctx context.Context, in contracts.DeleteAppInput) (contracts.DeleteAppOutput, error)
+}
+
+func deleteAppToolDefinition() *sdkmcp.Tool {
+	return &sdkmcp.Tool{
+		Name:        toolNameSakiDeleteApp,
+		Description: "Delete a deployed app, for example a throwaway demo, by the app_id saki_deploy_app returned. This removes the app and its URL; confirm with the user first. An invalid_input error means the app does not exist.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"saki_control_plane_url": map[string]any{
+					"type":        "string",
+					"description": "Tokenized Saki control plane URL. Example: https://saki.internal/api?token=<uuid>.",
+					"minLength":   1,
+				},
+				"app_id": map[string]any{
+					"type":        "string",
+					"description": "app_id of the app to delete, as returned by saki_deploy_app.",
+					"minLength":   1,
+				},
+			},
+			"required":             []string{"app_id"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+func (s *Server) handleDeleteApp(deleter appDeleter) sdkmcp.ToolHandlerFor[contracts.DeleteAppInput, contracts.DeleteAppOutput] {
+	return func(ctx context.Context, _ *sdkmcp.CallToolRequest, in contracts.DeleteAppInput) (*sdkmcp.CallToolResult, contracts.DeleteAppOutput, error) {
+		in.SakiControlPlaneURL = strings.TrimSpace(in.SakiControlPlaneURL)
+		in.AppID = strings.TrimSpace(in.AppID)
+		s.logger.Info("tool call requested", map[string]any{
+			"tool":   toolNameSakiDeleteApp,
+			"app_id": in.AppID,
+		})
+
+		output, err := deleter.DeleteApp(ctx, in)
+		if err != nil {
+			s.logger.Error("app delete failed", map[string]any{
+				"app_id": in.AppID,
+				"error":  err.Error(),
+			})
+			return nil, contracts.DeleteAppOutput{}, err
+		}
+
+		payload, err := json.Marshal(output)
+		if err != nil {
+			return nil, contracts.DeleteAppOutput{}, err
+		}
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: string(payload)}},
+		}, output, nil
+	}
+}
@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/1800agents/saki/tools/contracts"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestDeleteAppTool_CallsService(t *testing.T) {
+	service := &appDeletingDeployService{}
+	_, session, _, _ := serveTestServer(t, service, time.Second, nil)
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      toolNameSakiDeleteApp,
+		Arguments: map[string]any{"app_id": " app_1 "},
+	})
+	if err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected success, got %+v", result.Content)
+	}
+	if service.input.AppID != "app_1" {
+		t.Fatalf("expected trimmed app_id to reach the service, got %q", service.input.AppID)
+	}
+
+	var out contracts.DeleteAppOutput
+	text := result.Content[0].(*sdkmcp.TextContent).Text
+	if err := json.Unmarshal([]byte(text), &out); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if out != (contracts.DeleteAppOutput{AppID: "app_1", Status: "deleted"}) {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+}
+
+type appDeletingDeployService struct {
+	recordingDeployService
+	input contracts.DeleteAppInput
+}
+
+func (s *appDeletingDeployService) DeleteApp(_ context.Context, in contracts.DeleteAppInput) (contracts.DeleteAppOutput, error) {
+	s.input = in
+	return contracts.DeleteAppOutput{AppID: in.AppID, Status: "deleted"}, nil
+}
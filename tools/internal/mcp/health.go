@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/logging"
+	"github.com/1800agents/saki/tools/internal/tool"
+)
+
+const healthCheckTimeout = 5 * time.Second
+
+// healthCheck is one shallow dependency probe behind /healthz.
+type healthCheck struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// defaultHealthChecks probes the docker daemon and, when a control plane URL
+// is configured (SAKI_CONTROL_PLANE_URL or SAKI_CONTROL_PLANE_URL_FILE, as for
+// deploys), the control plane's /healthz.
+func defaultHealthChecks() []healthCheck {
+	checks := []healthCheck{{
+		name: "docker",
+		run: func(ctx context.Context) error {
+			output, err := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Version}}").CombinedOutput()
+			if err != nil && len(strings.TrimSpace(string(output))) > 0 {
+				return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+			}
+			return err
+		},
+	}}
+
+	if controlPlaneURL := tool.ControlPlaneURL(); controlPlaneURL != "" {
+		checks = append(checks, healthCheck{
+			name: "control_plane",
+			run: func(ctx context.Context) error {
+				client, err := controlplane.NewClient(controlPlaneURL)
+				if err != nil {
+					return errors.New("invalid control plane URL")
+				}
+				return controlPlaneHealthError(client.Ping(ctx))
+			},
+		})
+	}
+	return checks
+}
+
+// controlPlaneHealthError reduces a ping failure to "status N" or
+// "unreachable". /healthz is unauthenticated, and transport errors quote the
+// request URL with its token.
+func controlPlaneHealthError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *controlplane.APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Errorf("status %d", apiErr.StatusCode)
+	}
+	return errors.New("unreachable")
+}
+
+// healthResponse is the /healthz body. Checks maps each probe to "ok" or its
+// error message, with secrets redacted.
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// handleHealth answers 200 when the server is accepting work and every check
+// passes, and 503 otherwise.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	res := healthResponse{Status: "ok", Checks: map[string]string{}}
+	if s.isClosing() {
+		res.Status = "shutting_down"
+	}
+
+	for _, check := range s.healthChecks {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		err := check.run(ctx)
+		cancel()
+
+		if err != nil {
+			res.Checks[check.name] = logging.RedactSecrets(err.Error())
+			if res.Status == "ok" {
+				res.Status = "unhealthy"
+			}
+			continue
+		}
+		res.Checks[check.name] = "ok"
+	}
+
+	code := http.StatusOK
+	if res.Status != "ok" {
+		code = http.StatusServiceUnavailable
+		s.logger.Error("health check failed", map[string]any{
+			"status": res.Status,
+			"checks": res.Checks,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(res)
+}
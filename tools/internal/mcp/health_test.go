@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHealthEndpoint_ReportsBackendStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerErr  error
+		wantCode   int
+		wantStatus string
+	}{
+		{name: "healthy backends", wantCode: http.StatusOK, wantStatus: "ok"},
+		{name: "docker daemon down", dockerErr: errors.New("cannot connect to the docker daemon"), wantCode: http.StatusServiceUnavailable, wantStatus: "unhealthy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(&recordingDeployService{}, noopLogger{})
+			server.healthChecks = []healthCheck{
+				{name: "docker", run: func(context.Context) error { return tt.dockerErr }},
+				{name: "control_plane", run: func(context.Context) error { return nil }},
+			}
+
+			httpServer := httptest.NewServer(server.httpHandler())
+			defer httpServer.Close()
+
+			resp, err := http.Get(httpServer.URL + "/healthz")
+			if err != nil {
+				t.Fatalf("GET /healthz: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Fatalf("expected status code %d, got %d", tt.wantCode, resp.StatusCode)
+			}
+			var body healthResponse
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("decode health response: %v", err)
+			}
+			if body.Status != tt.wantStatus || body.Checks["control_plane"] != "ok" {
+				t.Fatalf("unexpected health response: %+v", body)
+			}
+			if tt.dockerErr != nil && body.Checks["docker"] != tt.dockerErr.Error() {
+				t.Fatalf("expected docker failure to be reported, got %+v", body.Checks)
+			}
+		})
+	}
+}
+
+func TestHealthEndpoint_UnhealthyWhileShuttingDown(t *testing.T) {
+	server := NewServer(&recordingDeployService{}, noopLogger{})
+	server.healthChecks = nil
+	server.shutdown()
+
+	rec := httptest.NewRecorder()
+	server.httpHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 during shutdown, got %d", rec.Code)
+	}
+}
+
+func TestHealthEndpoint_ControlPlaneFailureHidesToken(t *testing.T) {
+	urlFile := filepath.Join(t.TempDir(), "control-plane-url")
+	if err := os.WriteFile(urlFile, []byte("http://127.0.0.1:1?token=health-secret\n"), 0o600); err != nil {
+		t.Fatalf("write url file: %v", err)
+	}
+	t.Setenv("SAKI_CONTROL_PLANE_URL", "")
+	t.Setenv("SAKI_CONTROL_PLANE_URL_FILE", urlFile)
+
+	server := NewServer(&recordingDeployService{}, noopLogger{})
+	var checks []healthCheck
+	for _, check := range defaultHealthChecks() {
+		if check.name == "control_plane" {
+			checks = append(checks, check)
+		}
+	}
+	if len(checks) != 1 {
+		t.Fatalf("expected a control plane check from SAKI_CONTROL_PLANE_URL_FILE, got %d", len(checks))
+	}
+	server.healthChecks = checks
+
+	rec := httptest.NewRecorder()
+	server.httpHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for an unreachable control plane, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "health-secret") {
+		t.Fatalf("expected the token to stay out of the response, got %s", rec.Body.String())
+	}
+	var body healthResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode health response: %v", err)
+	}
+	if body.Checks["control_plane"] != "unreachable" {
+		t.Fatalf("expected control_plane to be reported unreachable, got %+v", body.Checks)
+	}
+}
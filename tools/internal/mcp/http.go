@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	httpAddrEnv = "SAKI_TOOLS_MCP_HTTP_ADDR"
+
+	mcpHTTPPath    = "/mcp"
+	healthHTTPPath = "/healthz"
+
+	httpShutdownTimeout = 5 * time.Second
+)
+
+// httpHandler routes MCP streamable HTTP traffic on /mcp, the liveness probe
+// on /healthz, and Prometheus metrics on /metrics.
+func (s *Server) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(mcpHTTPPath, sdkmcp.NewStreamableHTTPHandler(func(*http.Request) *sdkmcp.Server {
+		return s.sdkServer
+	}, nil))
+	mux.HandleFunc("GET "+healthHTTPPath, s.handleHealth)
+	mux.HandleFunc("GET "+metricsHTTPPath, s.handleMetrics)
+	return mux
+}
+
+// serveHTTP serves MCP over HTTP on addr until ctx ends, then drains
+// in-flight deploys before closing the listener.
+func (s *Server) serveHTTP(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{
+		Handler:           s.httpHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	s.logger.Info("mcp http server listening", map[string]any{
+		"addr":    listener.Addr().String(),
+		"mcp":     mcpHTTPPath,
+		"health":  healthHTTPPath,
+		"metrics": metricsHTTPPath,
+	})
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- httpServer.Serve(listener) }()
+
+	select {
+	case err := <-serveErr:
+		s.shutdown()
+		return err
+	case <-ctx.Done():
+	}
+
+	s.shutdown()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		s.logger.Error("mcp http server shutdown incomplete", map[string]any{"error": err.Error()})
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	s.logger.Info("mcp server stopped", nil)
+	return nil
+}
+
+func httpAddrFromEnv() string {
+	return strings.TrimSpace(os.Getenv(httpAddrEnv))
+}
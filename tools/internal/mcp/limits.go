@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Hard caps applied before any deploy work. They sit well above the schema
+// limits (which clients may ignore) and only guard against abusive payloads;
+// contracts.DeployAppInput.Validate still enforces the precise field rules.
+const (
+	maxDeployInputBytes = 16 << 10
+	maxInputFieldBytes  = 4 << 10
+	maxInputPlatforms   = 16
+)
+
+// checkDeployInputSize rejects tool calls whose raw arguments or individual
+// string fields (including each platforms and build_arg_files entry) exceed
+// the hard caps.
+func checkDeployInputSize(req *sdkmcp.CallToolRequest, in contracts.DeployAppInput) error {
+	if req != nil && req.Params != nil {
+		if size := len(req.Params.Arguments); size > maxDeployInputBytes {
+			return inputTooLarge(fmt.Sprintf("tool arguments are %d bytes; the limit is %d bytes", size, maxDeployInputBytes))
+		}
+	}
+
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"saki_control_plane_url", in.SakiControlPlaneURL},
+		{"name", in.Name},
+		{"description", in.Description},
+		{"org", in.Org},
+		{"note", in.Note},
+		{"app_dir", in.AppDir},
+		{"image_repository", in.ImageRepository},
+		{"git_commit", in.GitCommit},
+		{"state_file", in.StateFile},
+	}
+	for _, field := range fields {
+		if len(field.value) > maxInputFieldBytes {
+			return inputTooLarge(fmt.Sprintf("%s is %d bytes; the limit is %d bytes", field.name, len(field.value), maxInputFieldBytes))
+		}
+	}
+
+	if len(in.Platforms) > maxInputPlatforms {
+		return inputTooLarge(fmt.Sprintf("platforms lists %d entries; the limit is %d", len(in.Platforms), maxInputPlatforms))
+	}
+	for _, platform := range in.Platforms {
+		if len(platform) > maxInputFieldBytes {
+			return inputTooLarge(fmt.Sprintf("a platforms entry is %d bytes; the limit is %d bytes", len(platform), maxInputFieldBytes))
+		}
+	}
+	for name, path := range in.BuildArgFiles {
+		if size := max(len(name), len(path)); size > maxInputFieldBytes {
+			return inputTooLarge(fmt.Sprintf("a build_arg_files entry is %d bytes; the limit is %d bytes", size, maxInputFieldBytes))
+		}
+	}
+
+	return nil
+}
+
+func inputTooLarge(detail string) error {
+	return apperrors.New(apperrors.CodeInvalidInput, "validate deploy input", detail+". Shorten the input and retry saki_deploy_app.")
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/logging"
+	"github.com/1800agents/saki/tools/internal/tool"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const logNotificationLogger = "saki-tools"
+
+// progressDeployer is implemented by services that report deploy stage
+// transitions; the server forwards them to the client as log notifications.
+type progressDeployer interface {
+	DeployAppWithProgress(ctx context.Context, in contracts.DeployAppInput, progress tool.ProgressFunc) (contracts.DeployAppOutput, error)
+}
+
+// sessionLogger logs to the server logger and also sends each event to one
+// MCP client as a notifications/message log notification. The SDK drops
+// events below the level the client set with logging/setLevel, and sends
+// none until it sets one.
+type sessionLogger struct {
+	base    Logger
+	ctx     context.Context
+	session *sdkmcp.ServerSession
+}
+
+// callLogger returns the logger for one tool call: the server logger, teed
+// to the calling session when there is one.
+func (s *Server) callLogger(ctx context.Context, req *sdkmcp.CallToolRequest) Logger {
+	if req == nil || req.Session == nil {
+		return s.logger
+	}
+	return sessionLogger{base: s.logger, ctx: ctx, session: req.Session}
+}
+
+func (l sessionLogger) Info(msg string, fields map[string]any) {
+	l.base.Info(msg, fields)
+	l.notify("info", msg, fields)
+}
+
+func (l sessionLogger) Error(msg string, fields map[string]any) {
+	l.base.Error(msg, fields)
+	l.notify("error", msg, fields)
+}
+
+// notify sends msg and fields as the notification data, with string values
+// redacted like the server log. Delivery failures go to the server log only.
+func (l sessionLogger) notify(level sdkmcp.LoggingLevel, msg string, fields map[string]any) {
+	data := make(map[string]any, len(fields)+1)
+	for key, value := range fields {
+		if s, ok := value.(string); ok {
+			value = logging.RedactSecrets(s)
+		}
+		data[key] = value
+	}
+	data["message"] = msg
+
+	err := l.session.Log(l.ctx, &sdkmcp.LoggingMessageParams{
+		Level:  level,
+		Logger: logNotificationLogger,
+		Data:   data,
+	})
+	if err != nil {
+		l.base.Error("log notification failed", map[string]any{"error": err.Error()})
+	}
+}
+
+// deployWithLogs runs the deploy, logging each stage transition to logger
+// when the service reports them. Push percentage updates are left out.
+func (s *Server) deployWithLogs(ctx context.Context, in contracts.DeployAppInput, logger Logger) (contracts.DeployAppOutput, error) {
+	deployer, ok := s.service.(progressDeployer)
+	if !ok {
+		return s.service.DeployApp(ctx, in)
+	}
+
+	return deployer.DeployAppWithProgress(ctx, in, func(event tool.ProgressEvent) {
+		fields := map[string]any{"stage": event.Stage}
+		switch event.Status {
+		case tool.ProgressUpdated:
+			return
+		case tool.ProgressFailed:
+			fields["error"] = event.Error
+			logger.Error(fmt.Sprintf("deploy stage %s failed", event.Stage), fields)
+		default:
+			logger.Info(fmt.Sprintf("deploy stage %s %s", event.Stage, event.Status), fields)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/tool"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progressDeployService reports a build and push, failing the push when
+// pushErr is set.
+type progressDeployService struct {
+	recordingDeployService
+	pushErr error
+}
+
+func (s *progressDeployService) DeployAppWithProgress(_ context.Context, _ contracts.DeployAppInput, progress tool.ProgressFunc) (contracts.DeployAppOutput, error) {
+	progress(tool.ProgressEvent{Stage: tool.StageBuild, Status: tool.ProgressStarted})
+	progress(tool.ProgressEvent{Stage: tool.StageBuild, Status: tool.ProgressCompleted})
+	progress(tool.ProgressEvent{Stage: tool.StagePush, Status: tool.ProgressStarted})
+	progress(tool.ProgressEvent{Stage: tool.StagePush, Status: tool.ProgressUpdated, Percent: 50})
+	if s.pushErr != nil {
+		progress(tool.ProgressEvent{Stage: tool.StagePush, Status: tool.ProgressFailed, Error: s.pushErr.Error()})
+		return contracts.DeployAppOutput{}, s.pushErr
+	}
+	progress(tool.ProgressEvent{Stage: tool.StagePush, Status: tool.ProgressCompleted})
+	return contracts.DeployAppOutput{Status: "deploying"}, nil
+}
+
+// logNotifications collects the messages of the log notifications a client
+// receives.
+type logNotifications struct {
+	mu       sync.Mutex
+	messages []string
+	levels   []sdkmcp.LoggingLevel
+}
+
+func (n *logNotifications) handle(_ context.Context, req *sdkmcp.LoggingMessageRequest) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	data, _ := req.Params.Data.(map[string]any)
+	message, _ := data["message"].(string)
+	n.messages = append(n.messages, message)
+	n.levels = append(n.levels, req.Params.Level)
+}
+
+func (n *logNotifications) snapshot() ([]string, []sdkmcp.LoggingLevel) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return slices.Clone(n.messages), slices.Clone(n.levels)
+}
+
+func TestHandleDeploy_SendsLogNotifications(t *testing.T) {
+	notifications := &logNotifications{}
+	_, session, _, _ := serveTestServer(t, &progressDeployService{}, time.Minute, &sdkmcp.ClientOptions{
+		LoggingMessageHandler: notifications.handle,
+	})
+	if err := session.SetLoggingLevel(context.Background(), &sdkmcp.SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatalf("set logging level: %v", err)
+	}
+
+	res, err := callDeployTool(session)
+	if err != nil || res.IsError {
+		t.Fatalf("expected the deploy to succeed, got %v %+v", err, res)
+	}
+
+	want := []string{
+		"tool call requested",
+		"deploy input parsed",
+		"deploy stage build started",
+		"deploy stage build completed",
+		"deploy stage push started",
+		"deploy stage push completed",
+		"deploy completed",
+	}
+	waitFor(t, func() bool {
+		messages, _ := notifications.snapshot()
+		return len(messages) >= len(want)
+	})
+	messages, _ := notifications.snapshot()
+	if !slices.Equal(messages, want) {
+		t.Fatalf("expected notifications %q, got %q", want, messages)
+	}
+}
+
+func TestHandleDeploy_LogNotificationsRespectClientLevel(t *testing.T) {
+	notifications := &logNotifications{}
+	service := &progressDeployService{pushErr: errors.New("push denied")}
+	_, session, _, _ := serveTestServer(t, service, time.Minute, &sdkmcp.ClientOptions{
+		LoggingMessageHandler: notifications.handle,
+	})
+	if err := session.SetLoggingLevel(context.Background(), &sdkmcp.SetLoggingLevelParams{Level: "error"}); err != nil {
+		t.Fatalf("set logging level: %v", err)
+	}
+
+	if res, err := callDeployTool(session); err != nil || !res.IsError {
+		t.Fatalf("expected a failed deploy result, got %v %+v", err, res)
+	}
+
+	want := []string{"deploy stage push failed", "deploy failed"}
+	waitFor(t, func() bool {
+		messages, _ := notifications.snapshot()
+		return len(messages) >= len(want)
+	})
+	messages, levels := notifications.snapshot()
+	if !slices.Equal(messages, want) {
+		t.Fatalf("expected only error notifications %q, got %q", want, messages)
+	}
+	for _, level := range levels {
+		if level != "error" {
+			t.Fatalf("expected error level notifications, got %q", levels)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package mcp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const metricsHTTPPath = "/metrics"
+
+// deployDurationBuckets are the upper bounds, in seconds, of the
+// saki_deploy_duration_seconds histogram. Deploys run from seconds (skipped
+// or dry runs) to the 20 minute default timeout.
+var deployDurationBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200}
+
+// deployMetrics is a minimal in-process registry rendered in the Prometheus
+// text exposition format, so the server needs no metrics dependency.
+type deployMetrics struct {
+	mu sync.Mutex
+	// totals counts finished deploys by outcome label ("success" or an
+	// apperrors code).
+	totals       map[string]uint64
+	bucketCounts []uint64
+	durationSum  float64
+	durationN    uint64
+}
+
+func newDeployMetrics() *deployMetrics {
+	return &deployMetrics{
+		totals:       map[string]uint64{},
+		bucketCounts: make([]uint64, len(deployDurationBuckets)),
+	}
+}
+
+// observe records one finished deploy.
+func (m *deployMetrics) observe(err error, duration time.Duration) {
+	outcome := "success"
+	if err != nil {
+		outcome = string(apperrors.CodeOf(err))
+	}
+	seconds := duration.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.totals[outcome]++
+	for i, bound := range deployDurationBuckets {
+		if seconds <= bound {
+			m.bucketCounts[i]++
+		}
+	}
+	m.durationSum += seconds
+	m.durationN++
+}
+
+func (m *deployMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP saki_deploys_total Finished saki_deploy_app calls by status and error code.")
+	fmt.Fprintln(w, "# TYPE saki_deploys_total counter")
+	outcomes := make([]string, 0, len(m.totals))
+	for outcome := range m.totals {
+		outcomes = append(outcomes, outcome)
+	}
+	slices.Sort(outcomes)
+	for _, outcome := range outcomes {
+		if outcome == "success" {
+			fmt.Fprintf(w, "saki_deploys_total{status=\"success\"} %d\n", m.totals[outcome])
+			continue
+		}
+		fmt.Fprintf(w, "saki_deploys_total{status=\"failure\",code=%q} %d\n", outcome, m.totals[outcome])
+	}
+
+	fmt.Fprintln(w, "# HELP saki_deploy_duration_seconds Duration of finished saki_deploy_app calls.")
+	fmt.Fprintln(w, "# TYPE saki_deploy_duration_seconds histogram")
+	for i, bound := range deployDurationBuckets {
+		fmt.Fprintf(w, "saki_deploy_duration_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(bound, 'g', -1, 64), m.bucketCounts[i])
+	}
+	fmt.Fprintf(w, "saki_deploy_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.durationN)
+	fmt.Fprintf(w, "saki_deploy_duration_seconds_sum %s\n", strconv.FormatFloat(m.durationSum, 'g', -1, 64))
+	fmt.Fprintf(w, "saki_deploy_duration_seconds_count %d\n", m.durationN)
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.metrics.write(w)
+}
@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsEndpoint_CountsCompletedDeploys(t *testing.T) {
+	server, session, _, _ := serveTestServer(t, &recordingDeployService{}, time.Second, nil)
+
+	result, err := callDeployTool(session)
+	if err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected deploy to succeed, got %+v", result.Content)
+	}
+
+	httpServer := httptest.NewServer(server.httpHandler())
+	defer httpServer.Close()
+
+	resp, err := http.Get(httpServer.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read metrics: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	for _, want := range []string{
+		"# TYPE saki_deploys_total counter",
+		`saki_deploys_total{status="success"} 1`,
+		"# TYPE saki_deploy_duration_seconds histogram",
+		`saki_deploy_duration_seconds_bucket{le="+Inf"} 1`,
+		"saki_deploy_duration_seconds_count 1",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("expected metrics to include %q, got:\n%s", want, body)
+		}
+	}
+}
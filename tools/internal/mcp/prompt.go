@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	promptNameDeploy        = "saki_deploy_prompt"
+	promptDescriptionDeploy = "Conversation starter for deploying an app with saki_deploy_app. Asks the user, in plain language, for any of name, description, and app location that are not already known."
+)
+
+// deployPromptFields lists the prompt arguments in the order they are asked
+// for, with the plain-language question used when a value is missing.
+var deployPromptFields = []struct {
+	arg      string
+	label    string
+	question string
+}{
+	{"name", "App name", "What should the app be called? Use lowercase letters, numbers, and hyphens (for example team-dashboard)."},
+	{"description", "Description", "In one sentence, what does the app do?"},
+	{"app_dir", "App location", "Where is the app's source code on this machine?"},
+}
+
+func deployPromptDefinition() *sdkmcp.Prompt {
+	args := make([]*sdkmcp.PromptArgument, 0, len(deployPromptFields))
+	for _, field := range deployPromptFields {
+		args = append(args, &sdkmcp.PromptArgument{
+			Name:        field.arg,
+			Description: field.label + " if already known; the prompt asks the user otherwise.",
+		})
+	}
+	return &sdkmcp.Prompt{
+		Name:        promptNameDeploy,
+		Title:       "Deploy an app with Saki",
+		Description: promptDescriptionDeploy,
+		Arguments:   args,
+	}
+}
+
+func deployPromptHandler(_ context.Context, req *sdkmcp.GetPromptRequest) (*sdkmcp.GetPromptResult, error) {
+	var known map[string]string
+	if req != nil && req.Params != nil {
+		known = req.Params.Arguments
+	}
+
+	return &sdkmcp.GetPromptResult{
+		Description: promptDescriptionDeploy,
+		Messages: []*sdkmcp.PromptMessage{
+			{
+				Role:    "user",
+				Content: &sdkmcp.TextContent{Text: renderDeployPrompt(known)},
+			},
+		},
+	}, nil
+}
+
+// renderDeployPrompt states the known values and asks for the rest.
+func renderDeployPrompt(known map[string]string) string {
+	lines := []string{
+		"I'd like to deploy an app with Saki using the saki_deploy_app tool.",
+	}
+
+	var given, questions []string
+	for _, field := range deployPromptFields {
+		if value := strings.TrimSpace(known[field.arg]); value != "" {
+			given = append(given, fmt.Sprintf("- %s: %s", field.label, value))
+			continue
+		}
+		questions = append(questions, "- "+field.question)
+	}
+
+	if len(given) > 0 {
+		lines = append(lines, "", "Here is what I already know:")
+		lines = append(lines, given...)
+	}
+	if len(questions) > 0 {
+		lines = append(lines, "", "Before deploying, ask me in plain language (not as JSON):")
+		lines = append(lines, questions...)
+	} else {
+		lines = append(lines, "", "That is everything needed; go ahead and call saki_deploy_app.")
+	}
+
+	return strings.Join(lines, "\n")
+}
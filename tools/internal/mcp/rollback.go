@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/1800agents/saki/tools/contracts"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const toolNameSakiRollback = "saki_rollback"
+
+// appRollbacker is implemented by services that can roll an app back to an
+// earlier deployment. The saki_rollback tool is only registered for them.
+type appRollbacker interface {
+	RollbackApp(ctx context.Context, in contracts.RollbackAppInput) (contracts.RollbackAppOutput, error)
+}
+
+func rollbackToolDefinition() *sdkmcp.Tool {
+	return &sdkmcp.Tool{
+		Name:        toolNameSakiRollback,
+		Description: "Roll a deployed app back to an earlier deployment by redeploying that deployment's image. Nothing is rebuilt, so this works without the app's source or docker. Returns the new deployment_id and the app URL.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"saki_control_plane_url": map[string]any{
+					"type":        "string",
+					"description": "Tokenized Saki control plane URL. Example: https://saki.internal/api?token=<uuid>.",
+					"minLength":   1,
+				},
+				"app_id": map[string]any{
+					"type":        "string",
+					"description": "app_id of the app to roll back, as returned by saki_deploy_app.",
+					"minLength":   1,
+				},
+				"deployment_id": map[string]any{
+					"type":        "string",
+					"description": "deployment_id of the earlier deployment to roll back to.",
+					"minLength":   1,
+				},
+			},
+			"required":             []string{"app_id", "deployment_id"},
+			"additionalProperties": false,
+		},
+	}
+}
+
+func (s *Server) handleRollback(rollbacker appRollbacker) sdkmcp.ToolHandlerFor[contracts.RollbackAppInput, contracts.RollbackAppOutput] {
+	return func(ctx context.Context, _ *sdkmcp.CallToolRequest, in contracts.RollbackAppInput) (*sdkmcp.CallToolResult, contracts.RollbackAppOutput, error) {
+		in.SakiControlPlaneURL = strings.TrimSpace(in.SakiControlPlaneURL)
+		in.AppID = strings.TrimSpace(in.AppID)
+		in.DeploymentID = strings.TrimSpace(in.DeploymentID)
+		s.logger.Info("tool call requested", map[string]any{
+			"tool":          toolNameSakiRollback,
+			"app_id":        in.AppID,
+			"deployment_id": in.DeploymentID,
+		})
+
+		output, err := rollbacker.RollbackApp(ctx, in)
+		if err != nil {
+			s.logger.Error("app rollback failed", map[string]any{
+				"app_id":        in.AppID,
+				"deployment_id": in.DeploymentID,
+				"error":         err.Error(),
+			})
+			return nil, contracts.RollbackAppOutput{}, err
+		}
+
+		payload, err := json.Marshal(output)
+		if err != nil {
+			return nil, contracts.RollbackAppOutput{}, err
+		}
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: string(payload)}},
+		}, output, nil
+	}
+}
@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/1800agents/saki/tools/contracts"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRollbackTool_CallsService(t *testing.T) {
+	service := &rollbackDeployService{}
+	_, session, _, _ := serveTestServer(t, service, time.Second, nil)
+
+	result, err := session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      toolNameSakiRollback,
+		Arguments: map[string]any{"app_id": " app_1 ", "deployment_id": " dep_1 "},
+	})
+	if err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("expected success, got %+v", result.Content)
+	}
+	if service.input.AppID != "app_1" || service.input.DeploymentID != "dep_1" {
+		t.Fatalf("expected trimmed ids to reach the service, got %+v", service.input)
+	}
+
+	var out contracts.RollbackAppOutput
+	text := result.Content[0].(*sdkmcp.TextContent).Text
+	if err := json.Unmarshal([]byte(text), &out); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if out.DeploymentID != "dep_3" || out.URL != "https://my-app.saki.internal" {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+}
+
+type rollbackDeployService struct {
+	recordingDeployService
+	input contracts.RollbackAppInput
+}
+
+func (s *rollbackDeployService) RollbackApp(_ context.Context, in contracts.RollbackAppInput) (contracts.RollbackAppOutput, error) {
+	s.input = in
+	return contracts.RollbackAppOutput{
+		AppID:        in.AppID,
+		DeploymentID: "dep_3",
+		URL:          "https://my-app.saki.internal",
+		Status:       "deploying",
+	}, nil
+}
@@ -6,22 +6,32 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
 	"github.com/1800agents/saki/tools/docker"
 	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/version"
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 const (
-	toolNameSakiDeployApp        = "saki_deploy_app"
-	toolDescriptionSakiDeployApp = "Build and deploy a prepared local app directory. The calling agent must clone/customize the app first, then call this tool for prepare, docker build/push, and control-plane deploy. If any required field is missing, ask follow-up questions in plain language instead of asking for JSON."
-	resourceURIWorkflow          = "saki://deploy-workflow"
-	resourceNameWorkflow         = "saki_deploy_workflow"
-	resourceDescriptionWorkflow  = "Authoritative workflow for saki_deploy_app with clear agent/tool boundaries: agent prepares app source; tool performs build/push/deploy."
+	toolNameSakiDeployApp       = "saki_deploy_app"
+	resourceURIWorkflow         = "saki://deploy-workflow"
+	resourceNameWorkflow        = "saki_deploy_workflow"
+	resourceDescriptionWorkflow = "Authoritative workflow for saki_deploy_app with clear agent/tool boundaries: agent prepares app source; tool performs build/push/deploy."
+
+	shutdownGraceEnv     = "SAKI_TOOLS_MCP_SHUTDOWN_GRACE"
+	defaultShutdownGrace = 30 * time.Second
+
+	maxConcurrencyEnv     = "SAKI_TOOLS_MCP_MAX_CONCURRENCY"
+	defaultMaxConcurrency = 2
 )
 
 type Logger interface {
@@ -37,9 +47,26 @@ type Server struct {
 	service   deployService
 	logger    Logger
 	sdkServer *sdkmcp.Server
+	tools     []*sdkmcp.Tool
 	transport sdkmcp.Transport
 	debug     bool
 	rawLog    bool
+
+	// httpAddr switches Serve from stdio to streamable HTTP with /healthz.
+	httpAddr     string
+	healthChecks []healthCheck
+	metrics      *deployMetrics
+
+	shutdownGrace time.Duration
+	// slots bounds concurrent deploys; excess calls queue for a slot.
+	slots         chan struct{}
+	closed        chan struct{}
+	deployCtx     context.Context
+	cancelDeploys context.CancelFunc
+	inflight      sync.WaitGroup
+	mu            sync.Mutex
+	closing       bool
+	shutdownOnce  sync.Once
 }
 
 func NewServer(service deployService, logger Logger) *Server {
@@ -48,76 +75,138 @@ func NewServer(service deployService, logger Logger) *Server {
 
 	sdkServer := sdkmcp.NewServer(&sdkmcp.Implementation{
 		Name:    "saki-tools",
-		Version: "dev",
+		Version: version.Version,
 	}, nil)
 
-	sdkmcp.AddTool(sdkServer, deployToolDefinition(), func(ctx context.Context, _ *sdkmcp.CallToolRequest, in contracts.DeployAppInput) (*sdkmcp.CallToolResult, contracts.DeployAppOutput, error) {
-		in = normalizeDeployInput(in)
-		logger.Info("tool call requested", map[string]any{
-			"tool": toolNameSakiDeployApp,
-		})
-		logger.Info("deploy input parsed", map[string]any{
-			"name":        in.Name,
-			"description": in.Description,
-			"app_dir":     in.AppDir,
-			"has_url":     strings.TrimSpace(in.SakiControlPlaneURL) != "",
-		})
+	var transport sdkmcp.Transport = &sdkmcp.StdioTransport{}
+	if rawLog {
+		transport = &sdkmcp.LoggingTransport{Transport: transport, Writer: os.Stderr}
+	}
 
-		if missing := missingDeployFields(in, strings.TrimSpace(os.Getenv("SAKI_CONTROL_PLANE_URL")) != ""); len(missing) > 0 {
-			missingMessage := missingFieldsMessage(missing)
-			logger.Info("deploy input incomplete", map[string]any{
-				"missing_fields": missing,
-			})
-			return nil, contracts.DeployAppOutput{}, fmt.Errorf("%s", missingMessage)
-		}
+	deployCtx, cancelDeploys := context.WithCancel(context.Background())
+	s := &Server{
+		service:       service,
+		logger:        logger,
+		sdkServer:     sdkServer,
+		transport:     transport,
+		debug:         debug,
+		rawLog:        rawLog,
+		httpAddr:      httpAddrFromEnv(),
+		healthChecks:  defaultHealthChecks(),
+		metrics:       newDeployMetrics(),
+		shutdownGrace: envDuration(logger, shutdownGraceEnv, defaultShutdownGrace),
+		slots:         make(chan struct{}, envPositiveInt(logger, maxConcurrencyEnv, defaultMaxConcurrency)),
+		closed:        make(chan struct{}),
+		deployCtx:     deployCtx,
+		cancelDeploys: cancelDeploys,
+	}
 
-		output, err := service.DeployApp(ctx, in)
-		if err != nil {
-			logger.Error("deploy failed", deployErrorFields(in, err))
-			return nil, contracts.DeployAppOutput{}, formatDeployErrorForMCP(in, err)
-		}
+	addTool(s, deployToolDefinition(), s.handleDeploy)
+	if checker, ok := service.(nameChecker); ok {
+		addTool(s, checkNameToolDefinition(), s.handleCheckName(checker))
+	}
+	if deleter, ok := service.(appDeleter); ok {
+		addTool(s, deleteAppToolDefinition(), s.handleDeleteApp(deleter))
+	}
+	if rollbacker, ok := service.(appRollbacker); ok {
+		addTool(s, rollbackToolDefinition(), s.handleRollback(rollbacker))
+	}
+	sdkServer.AddResource(deployWorkflowResourceDefinition(), deployWorkflowResourceHandler)
+	sdkServer.AddResource(toolCatalogResourceDefinition(), s.toolCatalogResourceHandler)
+	sdkServer.AddPrompt(deployPromptDefinition(), deployPromptHandler)
+
+	return s
+}
+
+func (s *Server) handleDeploy(ctx context.Context, req *sdkmcp.CallToolRequest, in contracts.DeployAppInput) (*sdkmcp.CallToolResult, contracts.DeployAppOutput, error) {
+	logger := s.callLogger(ctx, req)
+	in = normalizeDeployInput(in)
+	logger.Info("tool call requested", map[string]any{
+		"tool": toolNameSakiDeployApp,
+	})
+	if err := checkDeployInputSize(req, in); err != nil {
+		logger.Info("deploy input rejected", map[string]any{"error": err.Error()})
+		return nil, contracts.DeployAppOutput{}, err
+	}
+	logger.Info("deploy input parsed", map[string]any{
+		"name":        in.Name,
+		"description": in.Description,
+		"app_dir":     in.AppDir,
+		"has_url":     strings.TrimSpace(in.SakiControlPlaneURL) != "",
+	})
 
-		logger.Info("deploy completed", map[string]any{
-			"app_id":        output.AppID,
-			"deployment_id": output.DeploymentID,
-			"status":        output.Status,
-			"url":           output.URL,
+	// SAKI_LOCAL_TAG deploys skip the control plane, so no URL is needed.
+	hasControlPlane := strings.TrimSpace(os.Getenv("SAKI_CONTROL_PLANE_URL")) != "" ||
+		strings.TrimSpace(os.Getenv("SAKI_CONTROL_PLANE_URL_FILE")) != "" ||
+		envEnabled("SAKI_LOCAL_TAG")
+	if missing := missingDeployFields(in, hasControlPlane); len(missing) > 0 {
+		missingMessage := missingFieldsMessage(missing)
+		logger.Info("deploy input incomplete", map[string]any{
+			"missing_fields": missing,
 		})
+		return nil, contracts.DeployAppOutput{}, fmt.Errorf("%s", missingMessage)
+	}
 
-		payload, err := json.Marshal(output)
-		if err != nil {
-			logger.Error("failed to marshal deploy output", map[string]any{"error": err.Error()})
-			return nil, contracts.DeployAppOutput{}, err
-		}
+	callCtx, done, err := s.beginDeploy(ctx)
+	if err != nil {
+		logger.Info("deploy rejected", map[string]any{"error": err.Error()})
+		return nil, contracts.DeployAppOutput{}, err
+	}
+	defer done()
 
-		return &sdkmcp.CallToolResult{
-			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: string(payload)}},
-		}, output, nil
+	release, err := s.acquireDeploySlot(callCtx, req)
+	if err != nil {
+		logger.Info("deploy rejected", map[string]any{"error": err.Error()})
+		return nil, contracts.DeployAppOutput{}, err
+	}
+	defer release()
+
+	started := time.Now()
+	output, err := s.deployWithLogs(callCtx, in, logger)
+	s.metrics.observe(err, time.Since(started))
+	if err != nil {
+		logger.Error("deploy failed", deployErrorFields(in, err))
+		return nil, contracts.DeployAppOutput{}, formatDeployErrorForMCP(in, err)
+	}
+
+	logger.Info("deploy completed", map[string]any{
+		"app_id":        output.AppID,
+		"deployment_id": output.DeploymentID,
+		"status":        output.Status,
+		"url":           output.URL,
 	})
-	sdkServer.AddResource(deployWorkflowResourceDefinition(), deployWorkflowResourceHandler)
 
-	var transport sdkmcp.Transport = &sdkmcp.StdioTransport{}
-	if rawLog {
-		transport = &sdkmcp.LoggingTransport{Transport: transport, Writer: os.Stderr}
+	payload, err := json.Marshal(output)
+	if err != nil {
+		logger.Error("failed to marshal deploy output", map[string]any{"error": err.Error()})
+		return nil, contracts.DeployAppOutput{}, err
 	}
 
-	return &Server{
-		service:   service,
-		logger:    logger,
-		sdkServer: sdkServer,
-		transport: transport,
-		debug:     debug,
-		rawLog:    rawLog,
-	}
+	return &sdkmcp.CallToolResult{
+		Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: string(payload)}},
+	}, output, nil
 }
 
 func (s *Server) Serve(ctx context.Context) error {
+	transport := "stdio"
+	if s.httpAddr != "" {
+		transport = "http"
+	}
 	s.logger.Info("mcp server started", map[string]any{
-		"debug":   s.debug,
-		"raw_log": s.rawLog,
+		"debug":     s.debug,
+		"raw_log":   s.rawLog,
+		"transport": transport,
 	})
+	if s.httpAddr != "" {
+		return s.serveHTTP(ctx, s.httpAddr)
+	}
 
+	// Shut down as soon as ctx ends, even if the transport is still draining.
+	stopWatch := context.AfterFunc(ctx, s.shutdown)
 	err := s.sdkServer.Run(ctx, s.transport)
+	stopWatch()
+	s.shutdown()
+
 	if err == nil {
 		s.logger.Info("mcp server stopped", nil)
 		return nil
@@ -133,7 +222,7 @@ func (s *Server) Serve(ctx context.Context) error {
 func deployToolDefinition() *sdkmcp.Tool {
 	return &sdkmcp.Tool{
 		Name:        toolNameSakiDeployApp,
-		Description: toolDescriptionSakiDeployApp,
+		Description: deployToolDescription(),
 		InputSchema: map[string]any{
 			"type": "object",
 			"properties": map[string]any{
@@ -154,11 +243,42 @@ func deployToolDefinition() *sdkmcp.Tool {
 					"minLength":   1,
 					"maxLength":   300,
 				},
+				"org": map[string]any{
+					"type":        "string",
+					"description": "Optional control plane org (namespace) the app belongs to, for multi-tenant control planes. DNS-safe slug like name. Example: platform-team.",
+					"pattern":     "^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$",
+					"maxLength":   63,
+				},
 				"app_dir": map[string]any{
 					"type":        "string",
 					"description": "Local directory containing the app source to build (prepared by the calling agent). Example: /workspace/my-app.",
 					"minLength":   1,
 				},
+				"image_repository": map[string]any{
+					"type":        "string",
+					"description": "Optional image repository to push to instead of the one returned by prepare, for registries not managed by the control plane. The prepare tag is kept. Example: ghcr.io/team/my-app.",
+				},
+				"git_commit": map[string]any{
+					"type":        "string",
+					"description": "Optional git commit hash (7-40 hex characters) to build from instead of running git, for an app_dir exported without a .git directory.",
+				},
+				"note": map[string]any{
+					"type":        "string",
+					"description": "Optional free-text note recorded with the deployment for audit (max 500 chars; line breaks become spaces). Pass it when the user gives a reason for the deploy. Example: hotfix for incident 123.",
+					"maxLength":   500,
+				},
+				"validate_only": map[string]any{
+					"type":        "boolean",
+					"description": "When true, only check the inputs, configuration, app_dir, and git commit, then return status \"validated\" without calling the control plane, docker, or the registry.",
+				},
+				"force": map[string]any{
+					"type":        "boolean",
+					"description": "When true, push even if SAKI_IMMUTABLE_TAGS finds the image tag already in the registry with different content. Only set it when the user asked to overwrite the tag.",
+				},
+				"dry_run": map[string]any{
+					"type":        "boolean",
+					"description": "When true, only call prepare and check whether the app exists, then return a plan (status \"planned\") without building, pushing, or deploying. Use it to validate the control plane URL and app name.",
+				},
 			},
 			"required":             []string{"name", "description", "app_dir"},
 			"additionalProperties": false,
@@ -171,6 +291,10 @@ func normalizeDeployInput(in contracts.DeployAppInput) contracts.DeployAppInput
 	in.Name = strings.TrimSpace(in.Name)
 	in.Description = strings.TrimSpace(in.Description)
 	in.AppDir = strings.TrimSpace(in.AppDir)
+	in.ImageRepository = strings.TrimSpace(in.ImageRepository)
+	in.GitCommit = strings.TrimSpace(in.GitCommit)
+	in.Org = strings.TrimSpace(in.Org)
+	in.Note = contracts.CleanNote(in.Note)
 	return in
 }
 
@@ -237,52 +361,12 @@ func deployWorkflowResourceHandler(_ context.Context, req *sdkmcp.ReadResourceRe
 			{
 				URI:      resourceURIWorkflow,
 				MIMEType: "text/markdown",
-				Text:     deployWorkflowDocument(),
+				Text:     DeployWorkflowDocument(),
 			},
 		},
 	}, nil
 }
 
-func deployWorkflowDocument() string {
-	lines := []string{
-		"# Saki Deploy Workflow (for agents calling MCP)",
-		"",
-		"Use this workflow when handling app deployment requests with saki_deploy_app.",
-		"",
-		"## Required inputs",
-		"- name: DNS-safe app name (lowercase letters, numbers, hyphens; max 63 chars).",
-		"- description: short purpose text (max 300 chars).",
-		"- app_dir: local app directory that was prepared by the calling agent.",
-		"- saki_control_plane_url: tokenized URL; may be omitted only if SAKI_CONTROL_PLANE_URL is set in the tool environment.",
-		"",
-		"If any required field is missing, ask follow-up questions in plain language and then retry the tool call.",
-		"",
-		"## Agent-side preparation steps (before tool call)",
-		"1. Clone the template repository URL: https://github.com/1800agents/saki-app-template.",
-		"2. Customize the app with the user (files, dependencies, behavior).",
-		"3. Choose the local directory to build, then call saki_deploy_app with app_dir set to that path.",
-		"",
-		"## Tool-side execution steps (inside saki_deploy_app)",
-		"1. Validate inputs.",
-		"2. Resolve current git commit (git rev-parse HEAD).",
-		"3. Call control plane prepare endpoint (POST /apps/prepare) with app name and git commit.",
-		"4. Build image name from prepare repository + required_tag (with SAKI_DOCKER_REGISTRY override support).",
-		"5. Run docker build -t <repository>:<required_tag> . in app_dir.",
-		"6. Run docker push <repository>:<required_tag>.",
-		"7. Create/update deployment via control plane (POST /apps with {name, description, image}), unless registry-only mode is enabled.",
-		"8. Return deployment output (app_id, deployment_id, image, url, status).",
-		"",
-		"## Responsibility boundary",
-		"- Agent responsibility: clone template and prepare app source.",
-		"- Tool responsibility: build, push, and deploy from app_dir.",
-		"",
-		"## Debugging notes",
-		"- On docker failures, MCP error responses include app_dir, image, command, exit code, and stderr when available.",
-	}
-
-	return strings.Join(lines, "\n")
-}
-
 func deployErrorFields(in contracts.DeployAppInput, err error) map[string]any {
 	fields := map[string]any{
 		"error":   err.Error(),
@@ -305,16 +389,74 @@ func deployErrorFields(in contracts.DeployAppInput, err error) map[string]any {
 func formatDeployErrorForMCP(in contracts.DeployAppInput, err error) error {
 	var dockerErr *docker.CommandError
 	if errors.As(err, &dockerErr) {
+		// Recognized environment failures already carry their advice in the
+		// wrapped error text; only app build failures get the generic hint.
+		next := "fix the app source/build context and retry saki_deploy_app"
+		if dockerErr.Advice() != "" {
+			next = "this is not a problem with the app source"
+		}
 		return fmt.Errorf(
-			"docker %s failed for app_dir=%q (app=%q). command=%q exit_code=%d stderr=%q. fix the app source/build context and retry saki_deploy_app: %w",
+			"docker %s failed for app_dir=%q (app=%q). command=%q exit_code=%d stderr=%q. %s: %w",
 			dockerErr.Op,
 			in.AppDir,
 			in.Name,
 			dockerErr.Command,
 			dockerErr.ExitCode,
 			dockerErr.Stderr,
+			next,
 			err,
 		)
 	}
+
+	var apiErr *controlplane.APIError
+	if errors.As(err, &apiErr) {
+		return formatAPIErrorForMCP(in, apiErr, err)
+	}
 	return err
 }
+
+// controlPlaneAdvice maps control-plane error codes to the follow-up the
+// calling agent should take.
+var controlPlaneAdvice = map[string]string{
+	"name_taken":              "the app name is already taken; ask the user for a different name and retry saki_deploy_app",
+	"validation_error":        "fix the fields listed above (asking the user in plain language if needed) and retry saki_deploy_app",
+	"invalid_session":         "the session token in saki_control_plane_url is invalid or revoked; ask the user for a fresh control plane URL",
+	"invalid_image_namespace": "the pushed image is outside the app's namespace; check SAKI_DOCKER_REGISTRY and retry saki_deploy_app",
+	"forbidden":               "the session is not allowed to perform this action; ask the user to check their access",
+}
+
+func formatAPIErrorForMCP(in contracts.DeployAppInput, apiErr *controlplane.APIError, err error) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "control plane rejected app %q (status=%d", in.Name, apiErr.StatusCode)
+	if apiErr.RemoteCode != "" {
+		fmt.Fprintf(&b, " code=%s", apiErr.RemoteCode)
+	}
+	fmt.Fprintf(&b, "): %s.", apiErr.Message)
+
+	fieldErrors, hasFieldErrors := apiErr.FieldErrors()
+	if hasFieldErrors {
+		b.WriteString(" field errors:")
+		for _, fieldErr := range fieldErrors {
+			fmt.Fprintf(&b, " %s: %s;", fieldErr.Field, fieldErr.Reason)
+		}
+	}
+
+	advice, ok := controlPlaneAdvice[apiErr.RemoteCode]
+	switch {
+	case ok:
+	case hasFieldErrors:
+		advice = controlPlaneAdvice["validation_error"]
+	case apiErr.StatusCode == http.StatusTooManyRequests:
+		advice = "the control plane is rate limiting requests; wait before retrying saki_deploy_app"
+		if apiErr.RetryAfter > 0 {
+			advice = fmt.Sprintf("the control plane is rate limiting requests; wait %s before retrying saki_deploy_app", apiErr.RetryAfter)
+		}
+	case apiErr.StatusCode >= 500:
+		advice = "the control plane failed internally; retry saki_deploy_app later"
+	default:
+		advice = "resolve the error above before retrying saki_deploy_app"
+	}
+	fmt.Fprintf(&b, " %s", advice)
+
+	return fmt.Errorf("%s: %w", b.String(), err)
+}
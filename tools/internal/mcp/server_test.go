@@ -2,12 +2,18 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
 	"github.com/1800agents/saki/tools/docker"
+	"github.com/1800agents/saki/tools/internal/apperrors"
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -28,6 +34,29 @@ func TestDeployToolDefinition_DescribesFullWorkflow(t *testing.T) {
 	}
 }
 
+func TestDeployToolDescriptionAndWorkflowShareSteps(t *testing.T) {
+	description := deployToolDefinition().Description
+	doc := DeployWorkflowDocument()
+
+	for _, steps := range [][]workflowStep{agentWorkflowSteps, toolWorkflowSteps} {
+		if !strings.Contains(description, stepSummaries(steps)) {
+			t.Fatalf("expected tool description to list steps %q, got %q", stepSummaries(steps), description)
+		}
+		for i, step := range steps {
+			line := fmt.Sprintf("%d. %s", i+1, step.Detail)
+			if !strings.Contains(doc, line) {
+				t.Fatalf("expected workflow doc to include step %q", line)
+			}
+		}
+	}
+
+	for _, shared := range []string{agentResponsibility, toolResponsibility, missingFieldsGuidance} {
+		if !strings.Contains(description, shared) || !strings.Contains(doc, shared) {
+			t.Fatalf("expected both tool description and workflow doc to include %q", shared)
+		}
+	}
+}
+
 func TestDeployToolDefinition_RequiresAppDir(t *testing.T) {
 	tool := deployToolDefinition()
 	schema, ok := tool.InputSchema.(map[string]any)
@@ -92,6 +121,94 @@ func TestDeployWorkflowResourceHandler_ReturnsDocument(t *testing.T) {
 	}
 }
 
+func TestToolCatalogResourceHandler_ListsRegisteredTools(t *testing.T) {
+	server := NewServer(&recordingDeployService{}, noopLogger{})
+
+	result, err := server.toolCatalogResourceHandler(context.Background(), &sdkmcp.ReadResourceRequest{
+		Params: &sdkmcp.ReadResourceParams{URI: resourceURITools},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Contents) != 1 || result.Contents[0].MIMEType != "application/json" {
+		t.Fatalf("expected one JSON content item, got %+v", result.Contents)
+	}
+
+	var catalog struct {
+		Tools []struct {
+			Name        string         `json:"name"`
+			Description string         `json:"description"`
+			InputSchema map[string]any `json:"input_schema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &catalog); err != nil {
+		t.Fatalf("catalog is not valid JSON: %v", err)
+	}
+	if len(catalog.Tools) != 1 || catalog.Tools[0].Name != toolNameSakiDeployApp {
+		t.Fatalf("expected %s in catalog, got %+v", toolNameSakiDeployApp, catalog.Tools)
+	}
+	if catalog.Tools[0].Description != deployToolDescription() {
+		t.Fatalf("unexpected description: %q", catalog.Tools[0].Description)
+	}
+	if _, ok := catalog.Tools[0].InputSchema["properties"].(map[string]any)["app_dir"]; !ok {
+		t.Fatalf("expected input schema to describe app_dir, got %v", catalog.Tools[0].InputSchema)
+	}
+
+	if _, err := server.toolCatalogResourceHandler(context.Background(), &sdkmcp.ReadResourceRequest{
+		Params: &sdkmcp.ReadResourceParams{URI: "saki://unknown"},
+	}); err == nil {
+		t.Fatal("expected unknown URI to be rejected")
+	}
+}
+
+func TestDeployPromptHandler_AsksForMissingValues(t *testing.T) {
+	result, err := deployPromptHandler(context.Background(), &sdkmcp.GetPromptRequest{
+		Params: &sdkmcp.GetPromptParams{Name: promptNameDeploy},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(result.Messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(result.Messages))
+	}
+	text := result.Messages[0].Content.(*sdkmcp.TextContent).Text
+	for _, field := range deployPromptFields {
+		if !strings.Contains(text, field.question) {
+			t.Fatalf("expected prompt to ask %q, got %q", field.question, text)
+		}
+	}
+	if strings.Contains(text, "already know") {
+		t.Fatalf("expected no known values section, got %q", text)
+	}
+}
+
+func TestDeployPromptHandler_UsesPrefilledValues(t *testing.T) {
+	result, err := deployPromptHandler(context.Background(), &sdkmcp.GetPromptRequest{
+		Params: &sdkmcp.GetPromptParams{
+			Name:      promptNameDeploy,
+			Arguments: map[string]string{"name": "team-dashboard", "app_dir": "/workspace/dashboard"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	text := result.Messages[0].Content.(*sdkmcp.TextContent).Text
+
+	for _, want := range []string{"App name: team-dashboard", "App location: /workspace/dashboard", "what does the app do?"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected prompt to include %q, got %q", want, text)
+		}
+	}
+	if strings.Contains(text, "What should the app be called?") {
+		t.Fatalf("expected known name not to be asked again, got %q", text)
+	}
+
+	full := renderDeployPrompt(map[string]string{"name": "a", "description": "b", "app_dir": "c"})
+	if !strings.Contains(full, "go ahead and call saki_deploy_app") {
+		t.Fatalf("expected fully pre-filled prompt to proceed, got %q", full)
+	}
+}
+
 func TestFormatDeployErrorForMCP_DockerError(t *testing.T) {
 	in := contracts.DeployAppInput{
 		Name:   "my-app",
@@ -122,6 +239,78 @@ func TestFormatDeployErrorForMCP_DockerError(t *testing.T) {
 	}
 }
 
+func TestFormatDeployErrorForMCP_RedactsDockerStderr(t *testing.T) {
+	in := contracts.DeployAppInput{Name: "my-app", AppDir: "/tmp/my-app"}
+	runner := failingRunner{stderr: "Error response from daemon: Get \"https://registry.internal/v2/?token=secret\": unauthorized"}
+	adapter := docker.NewAdapter(nil, runner)
+
+	pushErr := adapter.Push(context.Background(), "registry.internal/me/app:123", docker.PushOptions{})
+	msg := formatDeployErrorForMCP(in, pushErr).Error()
+	if strings.Contains(msg, "token=secret") || !strings.Contains(msg, "token=<redacted>") {
+		t.Fatalf("expected the token to be redacted, got %q", msg)
+	}
+}
+
+// failingRunner fails every docker command with stderr.
+type failingRunner struct {
+	stderr string
+}
+
+func (r failingRunner) Run(context.Context, docker.CommandRequest) (docker.CommandResult, error) {
+	return docker.CommandResult{ExitCode: 1, Stderr: r.stderr}, errors.New("exit status 1")
+}
+
+func TestFormatDeployErrorForMCP_APIError(t *testing.T) {
+	in := contracts.DeployAppInput{Name: "my-app", AppDir: "/tmp/my-app"}
+	baseErr := &controlplane.APIError{
+		StatusCode: 409,
+		RemoteCode: "name_taken",
+		Message:    "An app named my-app already exists",
+		Details:    json.RawMessage(`{"fieldErrors":{"name":["already in use"]}}`),
+	}
+
+	err := formatDeployErrorForMCP(in, baseErr)
+	msg := err.Error()
+	required := []string{
+		`app "my-app"`,
+		`status=409`,
+		`code=name_taken`,
+		`An app named my-app already exists`,
+		`name: already in use`,
+		`the app name is already taken; ask the user for a different name`,
+	}
+	for _, part := range required {
+		if !strings.Contains(msg, part) {
+			t.Fatalf("expected formatted error to include %q, got %q", part, msg)
+		}
+	}
+
+	var apiErr *controlplane.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatal("expected formatted error to wrap the APIError")
+	}
+}
+
+func TestFormatDeployErrorForMCP_ListsFieldErrors(t *testing.T) {
+	in := contracts.DeployAppInput{Name: "my-app", AppDir: "/tmp/my-app"}
+	baseErr := &controlplane.APIError{
+		StatusCode: 422,
+		Message:    "invalid app",
+		Details:    json.RawMessage(`[{"field":"description","reason":"must be at most 200 characters"},{"field":"org","reason":"unknown org"}]`),
+	}
+
+	msg := formatDeployErrorForMCP(in, baseErr).Error()
+	required := []string{
+		`field errors: description: must be at most 200 characters; org: unknown org;`,
+		`fix the fields listed above`,
+	}
+	for _, part := range required {
+		if !strings.Contains(msg, part) {
+			t.Fatalf("expected formatted error to include %q, got %q", part, msg)
+		}
+	}
+}
+
 func TestDeployErrorFields_IncludeDockerDetails(t *testing.T) {
 	in := contracts.DeployAppInput{Name: "my-app", AppDir: "/tmp/my-app"}
 	baseErr := &docker.CommandError{
@@ -163,3 +352,322 @@ func TestEnvEnabledOrDefault(t *testing.T) {
 		t.Fatal("expected true when env is true")
 	}
 }
+
+func TestServe_WaitsForInFlightDeployOnShutdown(t *testing.T) {
+	service := newBlockingDeployService()
+	server, session, cancel, served := serveTestServer(t, service, time.Minute, nil)
+
+	go callDeployTool(session)
+	<-service.started
+
+	cancel()
+	select {
+	case <-served:
+		t.Fatal("expected Serve to wait for the in-flight deploy")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, _, err := server.beginDeploy(context.Background()); !errors.Is(err, errShuttingDown) {
+		t.Fatalf("expected new deploys to be rejected during shutdown, got %v", err)
+	}
+
+	close(service.release)
+	if err := <-served; err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+	if err := <-service.ctxErr; err != nil {
+		t.Fatalf("expected in-flight deploy to finish uncancelled, got %v", err)
+	}
+}
+
+func TestServe_BoundsConcurrentDeploys(t *testing.T) {
+	t.Setenv(maxConcurrencyEnv, "2")
+	service := &gatedDeployService{release: make(chan struct{})}
+
+	var mu sync.Mutex
+	var queued []string
+	_, session, _, _ := serveTestServer(t, service, time.Minute, &sdkmcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, req *sdkmcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			queued = append(queued, req.Params.Message)
+		},
+	})
+
+	const calls = 5
+	var wg sync.WaitGroup
+	errs := make(chan error, calls)
+	for range calls {
+		wg.Go(func() {
+			res, err := callDeployTool(session)
+			if err == nil && res.IsError {
+				err = errors.New("tool call returned an error result")
+			}
+			errs <- err
+		})
+	}
+
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(queued) == calls-2
+	})
+	if active, _ := service.counts(); active != 2 {
+		t.Fatalf("expected exactly 2 deploys running while others queue, got %d", active)
+	}
+	for _, message := range queued {
+		if message != deployQueuedMessage {
+			t.Fatalf("unexpected progress message %q", message)
+		}
+	}
+
+	close(service.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("expected queued calls to complete, got %v", err)
+		}
+	}
+	if _, peak := service.counts(); peak != 2 {
+		t.Fatalf("expected peak concurrency 2, got %d", peak)
+	}
+}
+
+func TestServe_CancelsInFlightDeployAfterGracePeriod(t *testing.T) {
+	service := newBlockingDeployService()
+	_, session, cancel, served := serveTestServer(t, service, 20*time.Millisecond, nil)
+
+	go callDeployTool(session)
+	<-service.started
+
+	cancel()
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Serve to return after the grace period")
+	}
+	if err := <-service.ctxErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected in-flight deploy to be cancelled, got %v", err)
+	}
+}
+
+// serveTestServer runs a Server over in-memory transports and connects a
+// client to it. Cancelling the returned func shuts the server down; Serve's
+// result is delivered on the returned channel.
+func serveTestServer(t *testing.T, service deployService, grace time.Duration, clientOpts *sdkmcp.ClientOptions) (*Server, *sdkmcp.ClientSession, context.CancelFunc, <-chan error) {
+	t.Helper()
+	t.Setenv("SAKI_CONTROL_PLANE_URL", "https://cp.internal?token=test-token")
+
+	server := NewServer(service, noopLogger{})
+	server.shutdownGrace = grace
+	serverTransport, clientTransport := sdkmcp.NewInMemoryTransports()
+	server.transport = serverTransport
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(ctx) }()
+
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "test-client", Version: "dev"}, clientOpts)
+	session, err := client.Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("connect client: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return server, session, cancel, served
+}
+
+func callDeployTool(session *sdkmcp.ClientSession) (*sdkmcp.CallToolResult, error) {
+	params := &sdkmcp.CallToolParams{
+		// Set the token via Meta: SetProgressToken is a no-op on nil Meta.
+		Meta: sdkmcp.Meta{"progressToken": "deploy"},
+		Name: toolNameSakiDeployApp,
+		Arguments: map[string]any{
+			"name":        "my-app",
+			"description": "internal app",
+			"app_dir":     "/tmp/my-app",
+		},
+	}
+	return session.CallTool(context.Background(), params)
+}
+
+// blockingDeployService blocks DeployApp until released or cancelled and
+// reports the context error it observed.
+type blockingDeployService struct {
+	started chan struct{}
+	release chan struct{}
+	ctxErr  chan error
+}
+
+func newBlockingDeployService() *blockingDeployService {
+	return &blockingDeployService{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		ctxErr:  make(chan error, 1),
+	}
+}
+
+func (b *blockingDeployService) DeployApp(ctx context.Context, _ contracts.DeployAppInput) (contracts.DeployAppOutput, error) {
+	close(b.started)
+	select {
+	case <-b.release:
+		b.ctxErr <- ctx.Err()
+		return contracts.DeployAppOutput{Status: "deploying"}, nil
+	case <-ctx.Done():
+		b.ctxErr <- ctx.Err()
+		return contracts.DeployAppOutput{}, ctx.Err()
+	}
+}
+
+// gatedDeployService blocks every DeployApp until release is closed and
+// tracks how many run at once.
+type gatedDeployService struct {
+	release chan struct{}
+
+	mu     sync.Mutex
+	active int
+	peak   int
+}
+
+func (g *gatedDeployService) DeployApp(ctx context.Context, _ contracts.DeployAppInput) (contracts.DeployAppOutput, error) {
+	g.mu.Lock()
+	g.active++
+	g.peak = max(g.peak, g.active)
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		g.active--
+		g.mu.Unlock()
+	}()
+
+	select {
+	case <-g.release:
+		return contracts.DeployAppOutput{Status: "deploying"}, nil
+	case <-ctx.Done():
+		return contracts.DeployAppOutput{}, ctx.Err()
+	}
+}
+
+func (g *gatedDeployService) counts() (active, peak int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.active, g.peak
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Info(string, map[string]any)  {}
+func (noopLogger) Error(string, map[string]any) {}
+
+func TestHandleDeploy_RejectsOversizedFields(t *testing.T) {
+	oversized := strings.Repeat("a", maxInputFieldBytes+1)
+	tests := []struct {
+		field  string
+		mutate func(in *contracts.DeployAppInput)
+	}{
+		{"description", func(in *contracts.DeployAppInput) { in.Description = oversized }},
+		{"org", func(in *contracts.DeployAppInput) { in.Org = oversized }},
+		{"note", func(in *contracts.DeployAppInput) { in.Note = oversized }},
+		{"image_repository", func(in *contracts.DeployAppInput) { in.ImageRepository = oversized }},
+		{"git_commit", func(in *contracts.DeployAppInput) { in.GitCommit = oversized }},
+		{"build_arg_files", func(in *contracts.DeployAppInput) { in.BuildArgFiles = map[string]string{"TOKEN": oversized} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			service := &recordingDeployService{}
+			server := NewServer(service, noopLogger{})
+
+			in := contracts.DeployAppInput{
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				Name:                "my-app",
+				Description:         "internal app",
+				AppDir:              "/tmp/my-app",
+			}
+			tt.mutate(&in)
+			_, _, err := server.handleDeploy(context.Background(), toolRequest(t, in), in)
+			if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+				t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeInvalidInput, got, err)
+			}
+			if !strings.Contains(err.Error(), tt.field) {
+				t.Fatalf("expected error to name the oversized field, got %v", err)
+			}
+			if service.calls != 0 {
+				t.Fatal("expected no deploy for oversized input")
+			}
+		})
+	}
+}
+
+func TestHandleDeploy_RejectsOversizedArguments(t *testing.T) {
+	service := &recordingDeployService{}
+	server := NewServer(service, noopLogger{})
+
+	env := map[string]string{}
+	for i := range 512 {
+		env[fmt.Sprintf("VAR_%d", i)] = strings.Repeat("x", 64)
+	}
+	args := map[string]any{
+		"saki_control_plane_url": "https://cp.internal?token=test-token",
+		"name":                   "my-app",
+		"description":            "internal app",
+		"app_dir":                "/tmp/my-app",
+		"env":                    env,
+	}
+	raw, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("marshal args: %v", err)
+	}
+	req := &sdkmcp.CallToolRequest{Params: &sdkmcp.CallToolParamsRaw{Name: toolNameSakiDeployApp, Arguments: raw}}
+
+	in := contracts.DeployAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		Name:                "my-app",
+		Description:         "internal app",
+		AppDir:              "/tmp/my-app",
+	}
+	_, _, err = server.handleDeploy(context.Background(), req, in)
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeInvalidInput, got, err)
+	}
+	if !strings.Contains(err.Error(), "tool arguments") {
+		t.Fatalf("expected total size error, got %v", err)
+	}
+	if service.calls != 0 {
+		t.Fatal("expected no deploy for oversized input")
+	}
+}
+
+func toolRequest(t *testing.T, in contracts.DeployAppInput) *sdkmcp.CallToolRequest {
+	t.Helper()
+	raw, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("marshal input: %v", err)
+	}
+	return &sdkmcp.CallToolRequest{Params: &sdkmcp.CallToolParamsRaw{Name: toolNameSakiDeployApp, Arguments: raw}}
+}
+
+type recordingDeployService struct {
+	calls int
+}
+
+func (r *recordingDeployService) DeployApp(context.Context, contracts.DeployAppInput) (contracts.DeployAppOutput, error) {
+	r.calls++
+	return contracts.DeployAppOutput{Status: "deploying"}, nil
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+var errShuttingDown = errors.New("saki-tools MCP server is shutting down; retry saki_deploy_app once it restarts")
+
+// beginDeploy registers an in-flight deploy and returns the context it should
+// run under. The context survives cancellation of the tool call while the
+// server is shutting down, so the deploy can finish within the grace period;
+// it is cancelled once the grace period expires. done must be called when the
+// deploy returns.
+func (s *Server) beginDeploy(ctx context.Context) (context.Context, func(), error) {
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		return nil, nil, errShuttingDown
+	}
+	s.inflight.Add(1)
+	s.mu.Unlock()
+
+	callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stopServer := context.AfterFunc(s.deployCtx, cancel)
+	stopCall := context.AfterFunc(ctx, func() {
+		if !s.isClosing() {
+			cancel()
+		}
+	})
+
+	return callCtx, func() {
+		stopCall()
+		stopServer()
+		cancel()
+		s.inflight.Done()
+	}, nil
+}
+
+func (s *Server) isClosing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closing
+}
+
+// shutdown stops accepting deploys, waits up to the grace period for in-flight
+// deploys, then cancels any that remain and waits for them to return. It is
+// safe to call more than once; later calls block until the first completes.
+func (s *Server) shutdown() {
+	s.shutdownOnce.Do(func() {
+		s.mu.Lock()
+		s.closing = true
+		s.mu.Unlock()
+		close(s.closed)
+
+		s.logger.Info("mcp server shutting down", map[string]any{
+			"grace_period": s.shutdownGrace.String(),
+		})
+
+		finished := make(chan struct{})
+		go func() {
+			s.inflight.Wait()
+			close(finished)
+		}()
+
+		timer := time.NewTimer(s.shutdownGrace)
+		defer timer.Stop()
+
+		select {
+		case <-finished:
+			s.logger.Info("in-flight deploys finished", nil)
+		case <-timer.C:
+			s.logger.Info("shutdown grace period expired; cancelling in-flight deploys", nil)
+			s.cancelDeploys()
+			<-finished
+		}
+
+		s.cancelDeploys()
+		s.logger.Info("mcp server shutdown complete", nil)
+	})
+}
+
+// envDuration parses key as a positive time.Duration, falling back to
+// defaultValue (and logging why) when it is unset or invalid.
+func envDuration(logger Logger, key string, defaultValue time.Duration) time.Duration {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		logger.Error("invalid duration; using default", map[string]any{
+			"env":     key,
+			"value":   v,
+			"default": defaultValue.String(),
+		})
+		return defaultValue
+	}
+	return d
+}
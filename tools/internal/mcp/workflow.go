@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The deploy workflow is described in two places: the saki_deploy_app tool
+// description (tools/list) and the saki://deploy-workflow resource. Both are
+// rendered from the definitions below so they cannot drift.
+
+const (
+	agentResponsibility   = "the calling agent must clone/customize the app first and choose app_dir"
+	toolResponsibility    = "this tool runs prepare, docker build/push, and control-plane deploy from app_dir"
+	missingFieldsGuidance = "If any required field is missing, ask follow-up questions in plain language instead of asking for JSON."
+)
+
+// workflowStep is one canonical step. Summary appears in the tool
+// description; Detail is the numbered line in the workflow document.
+type workflowStep struct {
+	Summary string
+	Detail  string
+}
+
+var agentWorkflowSteps = []workflowStep{
+	{"clone template", "Clone the template repository URL: https://github.com/1800agents/saki-app-template."},
+	{"customize app", "Customize the app with the user (files, dependencies, behavior)."},
+	{"choose app_dir", "Choose the local directory to build, then call saki_deploy_app with app_dir set to that path."},
+}
+
+var toolWorkflowSteps = []workflowStep{
+	{"validate inputs", "Validate inputs."},
+	{"resolve git commit", "Resolve current git commit (git rev-parse HEAD)."},
+	{"prepare", "Call control plane prepare endpoint (POST /apps/prepare) with app name and git commit."},
+	{"resolve image", "Build image name from prepare repository + required_tag (with SAKI_DOCKER_REGISTRY override support)."},
+	{"docker login", "Run docker login for the image registry (static credentials or the prepare push token)."},
+	{"docker build", "Run docker build -t <repository>:<required_tag> . in app_dir."},
+	{"docker push", "Run docker push <repository>:<required_tag>."},
+	{"deploy", "Create/update deployment via control plane (POST /apps with {name, description, image}), unless registry-only mode is enabled."},
+	{"return output", "Return deployment output (app_id, deployment_id, image, url, status)."},
+}
+
+func deployToolDescription() string {
+	return fmt.Sprintf(
+		"Build and deploy a prepared local app directory. Responsibility boundary: %s; %s. Agent steps: %s. Tool steps: %s. %s",
+		agentResponsibility,
+		toolResponsibility,
+		stepSummaries(agentWorkflowSteps),
+		stepSummaries(toolWorkflowSteps),
+		missingFieldsGuidance,
+	)
+}
+
+// DeployWorkflowDocument renders the saki://deploy-workflow resource. The
+// saki-tools workflow command prints the same document.
+func DeployWorkflowDocument() string {
+	lines := []string{
+		"# Saki Deploy Workflow (for agents calling MCP)",
+		"",
+		"Use this workflow when handling app deployment requests with saki_deploy_app.",
+		"",
+		"## Required inputs",
+		"- name: DNS-safe app name (lowercase letters, numbers, hyphens; max 63 chars).",
+		"- description: short purpose text (max 300 chars).",
+		"- app_dir: local app directory that was prepared by the calling agent.",
+		"- saki_control_plane_url: tokenized URL; may be omitted only if SAKI_CONTROL_PLANE_URL is set in the tool environment.",
+		"",
+		missingFieldsGuidance,
+		"",
+		"## Agent-side preparation steps (before tool call)",
+	}
+	lines = append(lines, numberedSteps(agentWorkflowSteps)...)
+	lines = append(lines,
+		"",
+		"## Tool-side execution steps (inside saki_deploy_app)",
+	)
+	lines = append(lines, numberedSteps(toolWorkflowSteps)...)
+	lines = append(lines,
+		"",
+		"## Responsibility boundary",
+		"- Agent responsibility: "+agentResponsibility+".",
+		"- Tool responsibility: "+toolResponsibility+".",
+		"",
+		"## Debugging notes",
+		"- On docker failures, MCP error responses include app_dir, image, command, exit code, and stderr when available.",
+		"- On control plane rejections, MCP error responses include the HTTP status, remote error code, message, field errors, and a suggested next step.",
+	)
+
+	return strings.Join(lines, "\n")
+}
+
+func stepSummaries(steps []workflowStep) string {
+	summaries := make([]string, 0, len(steps))
+	for _, step := range steps {
+		summaries = append(summaries, step.Summary)
+	}
+	return strings.Join(summaries, " -> ")
+}
+
+func numberedSteps(steps []workflowStep) []string {
+	lines := make([]string, 0, len(steps))
+	for i, step := range steps {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, step.Detail))
+	}
+	return lines
+}
@@ -0,0 +1,154 @@
+package template
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// CheckStatus is the outcome of one template validation check.
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+	CheckSkip CheckStatus = "skip"
+)
+
+// Check is the result of one template validation check. Detail explains a
+// warning, failure, or skip.
+type Check struct {
+	Name   string
+	Status CheckStatus
+	Detail string
+}
+
+// ValidateOptions selects the optional template checks.
+type ValidateOptions struct {
+	// LintEnv checks that a committed .env only sets the keys deploys keep.
+	LintEnv bool
+}
+
+// Validation is the report produced by Validate.
+type Validation struct {
+	Repository string
+	Ref        string
+	Checks     []Check
+}
+
+// Failed reports whether any check failed.
+func (v Validation) Failed() bool {
+	return slices.ContainsFunc(v.Checks, func(c Check) bool { return c.Status == CheckFail })
+}
+
+// Validate clones repository at ref into a temporary directory, the same way
+// deploys do, checks that it has a usable Dockerfile, and removes the clone.
+// Check failures are reported in the Validation; the error is only for input
+// or temp directory problems.
+func Validate(ctx context.Context, repository, ref string, opts ValidateOptions) (Validation, error) {
+	report := Validation{Repository: repository, Ref: ref}
+	if strings.TrimSpace(repository) == "" {
+		return report, apperrors.New(apperrors.CodeInvalidInput, "validate template", "template repository is required")
+	}
+
+	tmp, err := os.MkdirTemp("", "saki-template-*")
+	if err != nil {
+		return report, apperrors.Wrap(apperrors.CodeTemplate, "validate template", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	dir := filepath.Join(tmp, "template")
+	if err := CloneFromPrepare(ctx, PrepareResponse{TemplateRepository: repository, TemplateRef: ref}, dir); err != nil {
+		report.Checks = append(report.Checks, Check{Name: "clone", Status: CheckFail, Detail: err.Error()})
+		return report, nil
+	}
+	report.Checks = append(report.Checks, Check{Name: "clone", Status: CheckPass})
+	report.Checks = append(report.Checks, checkDockerfile(dir))
+
+	if opts.LintEnv {
+		report.Checks = append(report.Checks, lintEnv(dir))
+	}
+	return report, nil
+}
+
+// checkDockerfile requires a Dockerfile at the template root with a FROM
+// instruction, since deploys build from it with no other configuration.
+func checkDockerfile(dir string) Check {
+	check := Check{Name: "dockerfile", Status: CheckPass}
+
+	content, err := os.ReadFile(filepath.Join(dir, "Dockerfile"))
+	if errors.Is(err, fs.ErrNotExist) {
+		check.Status, check.Detail = CheckFail, "no Dockerfile at the repository root"
+		return check
+	}
+	if err != nil {
+		check.Status, check.Detail = CheckFail, err.Error()
+		return check
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 && strings.EqualFold(fields[0], "FROM") {
+			return check
+		}
+	}
+	check.Status, check.Detail = CheckFail, "Dockerfile has no FROM instruction"
+	return check
+}
+
+// lintEnv warns about a committed .env that sets keys other than NAME and
+// DESCRIPTION: WriteEnv replaces the file on deploy, so they never reach the
+// app.
+func lintEnv(dir string) Check {
+	check := Check{Name: "env", Status: CheckPass}
+
+	content, err := os.ReadFile(filepath.Join(dir, envFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		check.Status, check.Detail = CheckSkip, "no "+envFileName+" in the template"
+		return check
+	}
+	if err != nil {
+		check.Status, check.Detail = CheckFail, err.Error()
+		return check
+	}
+
+	var dropped, malformed []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, _, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		key = strings.TrimSpace(key)
+		switch {
+		case !ok || key == "":
+			malformed = append(malformed, line)
+		case key != "NAME" && key != "DESCRIPTION":
+			dropped = append(dropped, key)
+		}
+	}
+
+	var problems []string
+	if len(dropped) > 0 {
+		problems = append(problems, fmt.Sprintf("%s is rewritten with only NAME and DESCRIPTION on deploy, so %s would be dropped", envFileName, strings.Join(dropped, ", ")))
+	}
+	if len(malformed) > 0 {
+		problems = append(problems, fmt.Sprintf("%d line(s) are not KEY=value", len(malformed)))
+	}
+	if len(problems) > 0 {
+		check.Status, check.Detail = CheckWarn, strings.Join(problems, "; ")
+	}
+	return check
+}
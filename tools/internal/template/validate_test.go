@@ -0,0 +1,150 @@
+package template
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		opts       ValidateOptions
+		want       map[string]CheckStatus
+		wantFailed bool
+		wantDetail string
+	}{
+		{
+			name:  "valid template",
+			files: map[string]string{"Dockerfile": "# syntax=docker/dockerfile:1\nFROM node:22\n", ".env": "NAME=\nDESCRIPTION=\n"},
+			opts:  ValidateOptions{LintEnv: true},
+			want:  map[string]CheckStatus{"clone": CheckPass, "dockerfile": CheckPass, "env": CheckPass},
+		},
+		{
+			name:       "missing Dockerfile",
+			files:      map[string]string{"README.md": "# template\n"},
+			want:       map[string]CheckStatus{"clone": CheckPass, "dockerfile": CheckFail},
+			wantFailed: true,
+			wantDetail: "no Dockerfile",
+		},
+		{
+			name:       "Dockerfile without FROM",
+			files:      map[string]string{"Dockerfile": "RUN echo hi\n"},
+			want:       map[string]CheckStatus{"clone": CheckPass, "dockerfile": CheckFail},
+			wantFailed: true,
+			wantDetail: "no FROM instruction",
+		},
+		{
+			name:       "env keys that deploys drop",
+			files:      map[string]string{"Dockerfile": "FROM scratch\n", ".env": "NAME=\nAPI_KEY=secret\n"},
+			opts:       ValidateOptions{LintEnv: true},
+			want:       map[string]CheckStatus{"clone": CheckPass, "dockerfile": CheckPass, "env": CheckWarn},
+			wantDetail: "API_KEY would be dropped",
+		},
+		{
+			name:  "env lint without .env",
+			files: map[string]string{"Dockerfile": "FROM scratch\n"},
+			opts:  ValidateOptions{LintEnv: true},
+			want:  map[string]CheckStatus{"clone": CheckPass, "dockerfile": CheckPass, "env": CheckSkip},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := initRepo(t, tt.files)
+
+			report, err := Validate(context.Background(), repo, "", tt.opts)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if report.Failed() != tt.wantFailed {
+				t.Fatalf("expected failed=%v, got %+v", tt.wantFailed, report.Checks)
+			}
+			got := map[string]CheckStatus{}
+			details := ""
+			for _, check := range report.Checks {
+				got[check.Name] = check.Status
+				details += check.Detail + "\n"
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected checks %v, got %+v", tt.want, report.Checks)
+			}
+			for name, status := range tt.want {
+				if got[name] != status {
+					t.Fatalf("check %s: expected %s, got %+v", name, status, report.Checks)
+				}
+			}
+			if tt.wantDetail != "" && !strings.Contains(details, tt.wantDetail) {
+				t.Fatalf("expected a detail containing %q, got %+v", tt.wantDetail, report.Checks)
+			}
+		})
+	}
+}
+
+func TestValidate_ChecksOutRef(t *testing.T) {
+	repo := initRepo(t, map[string]string{"README.md": "# template\n"})
+	runCommand(t, "git", "-C", repo, "tag", "v1")
+	writeFile(t, filepath.Join(repo, "Dockerfile"), "FROM scratch\n")
+	runCommand(t, "git", "-C", repo, "add", ".")
+	runCommand(t, "git", "-C", repo, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-m", "add Dockerfile")
+
+	// v1 predates the Dockerfile, so validating it must fail where HEAD passes.
+	report, err := Validate(context.Background(), repo, "v1", ValidateOptions{})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if !report.Failed() || report.Checks[len(report.Checks)-1].Name != "dockerfile" {
+		t.Fatalf("expected the dockerfile check to fail at v1, got %+v", report.Checks)
+	}
+
+	report, err = Validate(context.Background(), repo, "does-not-exist", ValidateOptions{})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(report.Checks) != 1 || report.Checks[0].Name != "clone" || report.Checks[0].Status != CheckFail {
+		t.Fatalf("expected the clone check to fail for an unknown ref, got %+v", report.Checks)
+	}
+}
+
+func TestValidate_ReportsCloneFailure(t *testing.T) {
+	report, err := Validate(context.Background(), filepath.Join(t.TempDir(), "missing"), "", ValidateOptions{})
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if len(report.Checks) != 1 || report.Checks[0].Status != CheckFail {
+		t.Fatalf("expected a single failed clone check, got %+v", report.Checks)
+	}
+}
+
+func TestValidate_RemovesClone(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	repo := initRepo(t, map[string]string{"Dockerfile": "FROM scratch\n"})
+
+	if _, err := Validate(context.Background(), repo, "", ValidateOptions{}); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	entries, err := os.ReadDir(tmp)
+	if err != nil {
+		t.Fatalf("read temp dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected the clone to be removed, found %d entries", len(entries))
+	}
+}
+
+// initRepo commits files to a new local git repository and returns its path.
+func initRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	repo := t.TempDir()
+	for name, contents := range files {
+		writeFile(t, filepath.Join(repo, name), contents)
+	}
+	runCommand(t, "git", "-C", repo, "init")
+	runCommand(t, "git", "-C", repo, "add", ".")
+	runCommand(t, "git", "-C", repo, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-m", "init")
+	return repo
+}
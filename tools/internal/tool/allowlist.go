@@ -0,0 +1,46 @@
+package tool
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// checkRegistryAllowed fails with CodeConfig when the registry host of
+// repository is not in the comma-separated allowlist. An empty allowlist
+// allows every registry. Repositories without an explicit host resolve to
+// Docker Hub, matching how docker itself interprets them.
+func checkRegistryAllowed(repository, allowlist string) error {
+	allowed := parseRegistryAllowlist(allowlist)
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	host := strings.ToLower(imageRegistryHost(repository))
+	if slices.Contains(allowed, host) {
+		return nil
+	}
+
+	return apperrors.New(apperrors.CodeConfig, "check registry allowlist", fmt.Sprintf(
+		"registry %q for image repository %q is not in %s (allowed: %s)",
+		host, repository, allowedRegistriesEnv, strings.Join(allowed, ", "),
+	))
+}
+
+// parseRegistryAllowlist normalizes each entry to a lowercase host[:port],
+// accepting the same endpoint styles as SAKI_DOCKER_REGISTRY.
+func parseRegistryAllowlist(value string) []string {
+	var hosts []string
+	for _, entry := range strings.Split(value, ",") {
+		host := normalizeRegistryForImage(entry)
+		if slash := strings.IndexByte(host, '/'); slash >= 0 {
+			host = host[:slash]
+		}
+		if host = strings.ToLower(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
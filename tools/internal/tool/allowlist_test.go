@@ -0,0 +1,74 @@
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestDeployApp_RegistryAllowlist(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowlist string
+		wantErr   bool
+	}{
+		{name: "empty allowlist allows all", allowlist: ""},
+		{name: "allowed host", allowlist: "ghcr.io, https://registry.internal:5000/v2/"},
+		{name: "disallowed host", allowlist: "ghcr.io,docker.io", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &stubControlPlane{
+				prepareRes: controlplane.PrepareAppResponse{Repository: "owner/my-app", RequiredTag: "abc1234"},
+				deployRes:  controlplane.DeployAppResponse{AppID: "app-1", Status: "deploying"},
+			}
+			builder := &stubDockerClient{}
+
+			svc := &Service{
+				logger:                 &noopLogger{},
+				newControlPlane:        func(string) (controlPlaneClient, error) { return cp, nil },
+				newDockerClient:        func(Logger) dockerClient { return builder },
+				resolveGitCommit:       func(context.Context) (string, error) { return "abc", nil },
+				dockerRegistryValue:    func() string { return "registry.internal:5000" },
+				allowedRegistriesValue: func() string { return tt.allowlist },
+			}
+
+			_, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				Name:                "my-app",
+				Description:         "internal app",
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				AppDir:              t.TempDir(),
+			})
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("DeployApp returned error: %v", err)
+				}
+				if builder.image != "registry.internal:5000/owner/my-app:abc1234" {
+					t.Fatalf("unexpected built image %q", builder.image)
+				}
+				return
+			}
+
+			if got := apperrors.CodeOf(err); got != apperrors.CodeConfig {
+				t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeConfig, got, err)
+			}
+			if builder.image != "" {
+				t.Fatalf("expected no build for a disallowed registry, got %q", builder.image)
+			}
+		})
+	}
+}
+
+func TestCheckRegistryAllowed_DefaultsToDockerHub(t *testing.T) {
+	if err := checkRegistryAllowed("library/nginx", "docker.io"); err != nil {
+		t.Fatalf("expected hostless repository to match docker.io, got %v", err)
+	}
+	if err := checkRegistryAllowed("library/nginx", "ghcr.io"); err == nil {
+		t.Fatal("expected hostless repository to be rejected when docker.io is not allowed")
+	}
+}
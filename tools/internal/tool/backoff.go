@@ -0,0 +1,12 @@
+package tool
+
+import "time"
+
+// retryDelay applies the service jitter to d. Services built without one (as
+// in most tests) wait exactly d.
+func (s *Service) retryDelay(d time.Duration) time.Duration {
+	if s.jitter == nil {
+		return d
+	}
+	return s.jitter(d)
+}
@@ -0,0 +1,13 @@
+package tool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryDelay_WithoutJitterIsExact(t *testing.T) {
+	svc := &Service{}
+	if got := svc.retryDelay(1500 * time.Millisecond); got != 1500*time.Millisecond {
+		t.Fatalf("expected unjittered delay, got %s", got)
+	}
+}
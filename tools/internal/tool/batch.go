@@ -0,0 +1,74 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// BatchOptions controls DeployApps.
+type BatchOptions struct {
+	// Concurrency bounds how many apps deploy at once. Values below 1 mean 1.
+	Concurrency int
+	// FailFast cancels in-flight deploys and skips pending ones after the first failure.
+	FailFast bool
+}
+
+// DeployApps deploys each input independently and returns outputs aligned with
+// inputs. Failed or skipped apps have a zero output; their errors are
+// aggregated into an *apperrors.Multi.
+func (s *Service) DeployApps(ctx context.Context, inputs []contracts.DeployAppInput, opts BatchOptions) ([]contracts.DeployAppOutput, error) {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outputs := make([]contracts.DeployAppOutput, len(inputs))
+	errs := make([]error, len(inputs))
+	slots := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, in := range inputs {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = batchError(in, ctx.Err())
+			continue
+		}
+
+		if ctx.Err() != nil {
+			<-slots
+			errs[i] = batchError(in, ctx.Err())
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, in contracts.DeployAppInput) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			out, err := s.DeployApp(ctx, in)
+			if err != nil {
+				errs[i] = batchError(in, err)
+				if opts.FailFast {
+					cancel()
+				}
+				return
+			}
+			outputs[i] = out
+		}(i, in)
+	}
+	wg.Wait()
+
+	return outputs, apperrors.NewMulti(errs...)
+}
+
+func batchError(in contracts.DeployAppInput, err error) error {
+	return fmt.Errorf("app %q: %w", in.Name, err)
+}
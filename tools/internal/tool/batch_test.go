@@ -0,0 +1,164 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/docker"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestDeployApps_AggregatesPartialFailures(t *testing.T) {
+	buildErr := errors.New("docker build failed")
+	svc := newBatchTestService(map[string]error{"broken-app": buildErr})
+
+	inputs := []contracts.DeployAppInput{
+		batchInput(t, "first-app"),
+		batchInput(t, "broken-app"),
+		batchInput(t, "third-app"),
+	}
+
+	outputs, err := svc.DeployApps(context.Background(), inputs, BatchOptions{Concurrency: 2})
+	if err == nil {
+		t.Fatal("expected aggregated error")
+	}
+
+	var multi *apperrors.Multi
+	if !errors.As(err, &multi) {
+		t.Fatalf("expected *apperrors.Multi, got %T", err)
+	}
+	if len(multi.Errors) != 1 || !errors.Is(err, buildErr) {
+		t.Fatalf("expected only the broken app to fail, got %v", multi.Errors)
+	}
+
+	if len(outputs) != len(inputs) {
+		t.Fatalf("expected %d outputs, got %d", len(inputs), len(outputs))
+	}
+	if outputs[0].Image != "registry.corgi-teeth.ts.net/owner/first-app:abc1234" {
+		t.Fatalf("unexpected first output: %+v", outputs[0])
+	}
+	if !reflect.DeepEqual(outputs[1], contracts.DeployAppOutput{}) {
+		t.Fatalf("expected zero output for failed app, got %+v", outputs[1])
+	}
+	if outputs[2].Image != "registry.corgi-teeth.ts.net/owner/third-app:abc1234" {
+		t.Fatalf("unexpected third output: %+v", outputs[2])
+	}
+}
+
+func TestDeployApps_FailFastSkipsRemaining(t *testing.T) {
+	buildErr := errors.New("docker build failed")
+	svc := newBatchTestService(map[string]error{"broken-app": buildErr})
+
+	inputs := []contracts.DeployAppInput{
+		batchInput(t, "broken-app"),
+		batchInput(t, "second-app"),
+		batchInput(t, "third-app"),
+	}
+
+	outputs, err := svc.DeployApps(context.Background(), inputs, BatchOptions{Concurrency: 1, FailFast: true})
+	var multi *apperrors.Multi
+	if !errors.As(err, &multi) {
+		t.Fatalf("expected *apperrors.Multi, got %v", err)
+	}
+	if len(multi.Errors) != 3 {
+		t.Fatalf("expected failure plus two skipped apps, got %v", multi.Errors)
+	}
+	if !errors.Is(multi.Errors[0], buildErr) {
+		t.Fatalf("expected first error to be the build failure, got %v", multi.Errors[0])
+	}
+	for _, skipped := range multi.Errors[1:] {
+		if !errors.Is(skipped, context.Canceled) {
+			t.Fatalf("expected skipped apps to report cancellation, got %v", skipped)
+		}
+	}
+	for i, out := range outputs {
+		if !reflect.DeepEqual(out, contracts.DeployAppOutput{}) {
+			t.Fatalf("expected no output for app %d, got %+v", i, out)
+		}
+	}
+}
+
+func newBatchTestService(buildErrs map[string]error) *Service {
+	var mu sync.Mutex
+	return &Service{
+		newControlPlane: func(string) (controlPlaneClient, error) {
+			return &namedPrepareControlPlane{}, nil
+		},
+		newDockerClient: func(Logger) dockerClient {
+			return &batchDockerClient{mu: &mu, buildErrs: buildErrs}
+		},
+		resolveGitCommit: func(context.Context) (string, error) { return "abc", nil },
+		logger:           &noopLogger{},
+	}
+}
+
+func batchInput(t *testing.T, name string) contracts.DeployAppInput {
+	return contracts.DeployAppInput{
+		Name:                name,
+		Description:         "internal app",
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppDir:              t.TempDir(),
+	}
+}
+
+// namedPrepareControlPlane derives the repository from the requested name so
+// concurrent batch deploys stay independent.
+type namedPrepareControlPlane struct {
+	stubControlPlane
+}
+
+func (n *namedPrepareControlPlane) PrepareApp(_ context.Context, req controlplane.PrepareAppRequest) (controlplane.PrepareAppResponse, error) {
+	return controlplane.PrepareAppResponse{
+		Repository:  "registry.internal/owner/" + req.Name,
+		RequiredTag: "abc1234",
+	}, nil
+}
+
+type batchDockerClient struct {
+	mu        *sync.Mutex
+	buildErrs map[string]error
+}
+
+func (b *batchDockerClient) Build(_ context.Context, _ string, image string, _ docker.BuildOptions) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for name, err := range b.buildErrs {
+		if image == "registry.corgi-teeth.ts.net/owner/"+name+":abc1234" {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *batchDockerClient) Login(context.Context, string, string, string) error {
+	return nil
+}
+
+func (b *batchDockerClient) Push(context.Context, string, docker.PushOptions) error {
+	return nil
+}
+
+func (b *batchDockerClient) Tag(context.Context, string, string) error {
+	return nil
+}
+
+func (b *batchDockerClient) CopyImage(context.Context, string, string) error {
+	return nil
+}
+
+func (b *batchDockerClient) InspectManifest(context.Context, string) error {
+	return nil
+}
+
+func (b *batchDockerClient) LocalImageID(context.Context, string) (string, error) {
+	return "", nil
+}
+
+func (b *batchDockerClient) RemoteImageID(context.Context, string) (string, bool, error) {
+	return "", false, nil
+}
@@ -0,0 +1,59 @@
+package tool
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// maxBuildArgFileSize bounds a build arg file. Values reach docker through
+// its environment, where a single variable cannot exceed 128 KiB on Linux.
+const maxBuildArgFileSize = 64 << 10
+
+// readBuildArgFiles reads each build arg value from its file, trimming one
+// trailing newline. Missing, non-regular, and oversized files fail with
+// CodeInvalidInput.
+func readBuildArgFiles(files map[string]string) (map[string]string, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	const op = "read build arg file"
+	values := make(map[string]string, len(files))
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		path := strings.TrimSpace(files[name])
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, apperrors.New(apperrors.CodeInvalidInput, op, fmt.Sprintf("build arg %s: %s does not exist", name, path))
+		}
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.CodeInvalidInput, op, fmt.Errorf("build arg %s: %w", name, err))
+		}
+		if !info.Mode().IsRegular() {
+			return nil, apperrors.New(apperrors.CodeInvalidInput, op, fmt.Sprintf("build arg %s: %s is not a regular file", name, path))
+		}
+		if info.Size() > maxBuildArgFileSize {
+			return nil, apperrors.New(apperrors.CodeInvalidInput, op, fmt.Sprintf(
+				"build arg %s: %s is %d bytes; build arg files may be at most %d bytes",
+				name, path, info.Size(), maxBuildArgFileSize,
+			))
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.CodeInvalidInput, op, fmt.Errorf("build arg %s: %w", name, err))
+		}
+		value := string(content)
+		if trimmed, ok := strings.CutSuffix(value, "\n"); ok {
+			value = strings.TrimSuffix(trimmed, "\r")
+		}
+		values[name] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,81 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestDeployApp_ReadsBuildArgFromFile(t *testing.T) {
+	cp := &stubControlPlane{
+		prepareRes: controlplane.PrepareAppResponse{
+			Repository:  "registry.internal/owner/my-app",
+			RequiredTag: "abc1234",
+		},
+	}
+	dockerStub := &stubDockerClient{}
+	svc := NewTestService(TestDeps{ControlPlane: cp, Docker: dockerStub})
+
+	_, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		Name:                "my-app",
+		Description:         "internal app",
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppDir:              t.TempDir(),
+		BuildArgFiles:       map[string]string{"NPM_TOKEN": filepath.Join("testdata", "npm_token.txt")},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := dockerStub.buildOpts.BuildArgs["NPM_TOKEN"]; got != "npm-registry-token-0123456789" {
+		t.Fatalf("expected the file contents without the trailing newline, got %q", got)
+	}
+}
+
+func TestReadBuildArgFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr string
+	}{
+		{name: "crlf is trimmed", path: write("crlf", "value\r\n"), want: "value"},
+		{name: "only one newline is trimmed", path: write("multi", "line one\nline two\n\n"), want: "line one\nline two\n"},
+		{name: "no newline", path: write("bare", "value"), want: "value"},
+		{name: "missing", path: filepath.Join(dir, "missing"), wantErr: "does not exist"},
+		{name: "directory", path: dir, wantErr: "is not a regular file"},
+		{name: "too large", path: write("large", strings.Repeat("x", maxBuildArgFileSize+1)), wantErr: "at most 65536 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := readBuildArgFiles(map[string]string{"ARG": tt.path})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if code := apperrors.CodeOf(err); code != apperrors.CodeInvalidInput {
+					t.Fatalf("expected code %q, got %q", apperrors.CodeInvalidInput, code)
+				}
+				return
+			}
+			if err != nil || values["ARG"] != tt.want {
+				t.Fatalf("expected %q, got %q (err=%v)", tt.want, values["ARG"], err)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package tool
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const (
+	buildCPUQuotaEnv = "SAKI_BUILD_CPU_QUOTA"
+	buildMemoryEnv   = "SAKI_BUILD_MEMORY"
+
+	// minBuildMemory is the smallest --memory docker accepts (6 MiB).
+	minBuildMemory = 6 << 20
+)
+
+// memoryLimitPattern is docker's --memory format: a number with an optional
+// b, k, m, or g unit.
+var memoryLimitPattern = regexp.MustCompile(`^([0-9]+)([bkmg]?)$`)
+
+var memoryUnits = map[string]int64{"": 1, "b": 1, "k": 1 << 10, "m": 1 << 20, "g": 1 << 30}
+
+// buildLimits are the resource constraints applied to docker build.
+type buildLimits struct {
+	cpuQuota string
+	memory   string
+}
+
+func (s *Service) buildLimits() (buildLimits, error) {
+	return resolveBuildLimits(envValue(s.buildCPUQuotaValue), envValue(s.buildMemoryValue))
+}
+
+// resolveBuildLimits validates SAKI_BUILD_CPU_QUOTA (a positive number of
+// microseconds per 100ms period, at least 1000) and SAKI_BUILD_MEMORY (docker's
+// --memory format, at least 6m). Unset values leave the build unconstrained.
+func resolveBuildLimits(cpuQuota, memory string) (buildLimits, error) {
+	limits := buildLimits{
+		cpuQuota: strings.TrimSpace(cpuQuota),
+		memory:   strings.ToLower(strings.TrimSpace(memory)),
+	}
+
+	if limits.cpuQuota != "" {
+		quota, err := strconv.ParseInt(limits.cpuQuota, 10, 64)
+		if err != nil || quota < 1000 {
+			return buildLimits{}, apperrors.New(apperrors.CodeConfig, "resolve build limits", fmt.Sprintf("%s must be an integer of at least 1000 microseconds, got %q", buildCPUQuotaEnv, cpuQuota))
+		}
+	}
+
+	if limits.memory != "" {
+		m := memoryLimitPattern.FindStringSubmatch(limits.memory)
+		if m == nil {
+			return buildLimits{}, apperrors.New(apperrors.CodeConfig, "resolve build limits", fmt.Sprintf("%s must be a size like 512m or 2g, got %q", buildMemoryEnv, memory))
+		}
+		n, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || n > (1<<62)/memoryUnits[m[2]] || n*memoryUnits[m[2]] < minBuildMemory {
+			return buildLimits{}, apperrors.New(apperrors.CodeConfig, "resolve build limits", fmt.Sprintf("%s must be at least 6m, got %q", buildMemoryEnv, memory))
+		}
+	}
+
+	return limits, nil
+}
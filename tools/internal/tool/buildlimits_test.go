@@ -0,0 +1,44 @@
+package tool
+
+import (
+	"testing"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestResolveBuildLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		cpuQuota string
+		memory   string
+		want     buildLimits
+		wantErr  bool
+	}{
+		{name: "unset"},
+		{name: "valid values", cpuQuota: "50000", memory: " 2G ", want: buildLimits{cpuQuota: "50000", memory: "2g"}},
+		{name: "bytes without unit", memory: "1073741824", want: buildLimits{memory: "1073741824"}},
+		{name: "cpu quota below minimum", cpuQuota: "999", wantErr: true},
+		{name: "cpu quota not an integer", cpuQuota: "1.5", wantErr: true},
+		{name: "memory with unknown unit", memory: "2gb", wantErr: true},
+		{name: "memory below minimum", memory: "4m", wantErr: true},
+		{name: "memory overflow", memory: "99999999999999999g", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveBuildLimits(tt.cpuQuota, tt.memory)
+			if tt.wantErr {
+				if code := apperrors.CodeOf(err); code != apperrors.CodeConfig {
+					t.Fatalf("expected %s, got %s (%v)", apperrors.CodeConfig, code, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const cancelOnAbortEnv = "SAKI_CANCEL_ON_ABORT"
+
+// abortCancelTimeout bounds the best-effort cancel sent after the caller
+// aborted, since the caller's context can no longer be used for it.
+const abortCancelTimeout = 10 * time.Second
+
+// CancelDeployment asks the control plane to abort a running deployment. A
+// deployment that already reached a terminal state is left as is.
+func (s *Service) CancelDeployment(ctx context.Context, controlPlaneURL, deploymentID string) error {
+	deploymentID = strings.TrimSpace(deploymentID)
+	if deploymentID == "" {
+		return apperrors.New(apperrors.CodeInvalidInput, "cancel deployment", "deployment id is required")
+	}
+
+	controlPlaneURL, err := s.controlPlaneURL(controlPlaneURL)
+	if err != nil {
+		return err
+	}
+	cp, err := s.newControlPlane(controlPlaneURL)
+	if err != nil {
+		return err
+	}
+
+	if err := cp.CancelDeployment(ctx, deploymentID); err != nil {
+		return err
+	}
+	s.logger.Info("deployment cancel requested", map[string]any{
+		"deployment_id": deploymentID,
+	})
+	return nil
+}
+
+// cancelAbortedDeployment cancels deploymentID when SAKI_CANCEL_ON_ABORT is
+// enabled and ctx was cancelled by the caller (not by the deploy timeout)
+// after the control plane deploy was created. It reports whether the cancel
+// was attempted; failures are logged since the caller already reports the
+// abort.
+func (s *Service) cancelAbortedDeployment(ctx context.Context, cp controlPlaneClient, deploymentID string) bool {
+	if !errors.Is(ctx.Err(), context.Canceled) || !envEnabled(envValue(s.cancelOnAbortValue)) || strings.TrimSpace(deploymentID) == "" {
+		return false
+	}
+
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), abortCancelTimeout)
+	defer cancel()
+
+	if err := cp.CancelDeployment(cancelCtx, deploymentID); err != nil {
+		s.logger.Error("cancel of aborted deployment failed; continuing", map[string]any{
+			"deployment_id": deploymentID,
+			"error":         err.Error(),
+		})
+		return true
+	}
+	s.logger.Info("aborted deployment cancelled", map[string]any{
+		"deployment_id": deploymentID,
+	})
+	return true
+}
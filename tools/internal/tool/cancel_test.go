@@ -0,0 +1,105 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestCancelDeployment_UsesEnvControlPlaneURL(t *testing.T) {
+	cp := &stubControlPlane{}
+	var gotURL string
+	svc := &Service{
+		newControlPlane: func(controlPlaneURL string) (controlPlaneClient, error) {
+			gotURL = controlPlaneURL
+			return cp, nil
+		},
+		controlPlaneURLValue: func() string { return "https://cp.internal?token=env-token" },
+		logger:               &noopLogger{},
+	}
+
+	if err := svc.CancelDeployment(context.Background(), "", "dep_123"); err != nil {
+		t.Fatalf("cancel deployment: %v", err)
+	}
+	if gotURL != "https://cp.internal?token=env-token" {
+		t.Fatalf("expected env control plane URL, got %q", gotURL)
+	}
+	if len(cp.cancelReqs) != 1 || cp.cancelReqs[0] != "dep_123" {
+		t.Fatalf("expected cancel for dep_123, got %v", cp.cancelReqs)
+	}
+
+	err := svc.CancelDeployment(context.Background(), "", "  ")
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected code %q for empty deployment id, got %q", apperrors.CodeInvalidInput, got)
+	}
+}
+
+func TestDeployApp_CancelsDeploymentWhenAborted(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     string
+		wantCancels int
+	}{
+		{name: "enabled", enabled: "true", wantCancels: 1},
+		{name: "disabled by default", enabled: "", wantCancels: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, abort := context.WithCancel(context.Background())
+			defer abort()
+
+			// The caller aborts while the smoke check waits on the new rollout.
+			app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				abort()
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer app.Close()
+
+			cp := &stubControlPlane{
+				prepareRes: controlplane.PrepareAppResponse{
+					Repository:  "registry.internal/owner/my-app",
+					RequiredTag: "abc1234",
+				},
+				deployRes: controlplane.DeployAppResponse{
+					DeploymentID: "dep_1",
+					URL:          app.URL,
+					Status:       "deploying",
+				},
+			}
+			svc := &Service{
+				newControlPlane:        func(string) (controlPlaneClient, error) { return cp, nil },
+				newDockerClient:        func(Logger) dockerClient { return &stubDockerClient{} },
+				resolveGitCommit:       func(context.Context) (string, error) { return "abc", nil },
+				smokeCheckValue:        func() string { return "true" },
+				smokeCheckTimeoutValue: func() string { return "5s" },
+				cancelOnAbortValue:     func() string { return tt.enabled },
+				logger:                 &noopLogger{},
+			}
+
+			_, err := svc.DeployApp(ctx, contracts.DeployAppInput{
+				Name:                "my-app",
+				Description:         "internal app",
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				AppDir:              t.TempDir(),
+			})
+			if len(cp.cancelReqs) != tt.wantCancels {
+				t.Fatalf("expected %d cancel requests, got %v", tt.wantCancels, cp.cancelReqs)
+			}
+			if tt.wantCancels > 0 {
+				if !errors.Is(err, context.Canceled) {
+					t.Fatalf("expected aborted deploy error, got %v", err)
+				}
+				if cp.cancelReqs[0] != "dep_1" {
+					t.Fatalf("expected cancel for dep_1, got %v", cp.cancelReqs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const checkNameEnv = "SAKI_CHECK_NAME"
+
+// CheckName asks the control plane whether in.Name can be deployed: either
+// nobody uses it yet or the caller already owns it.
+func (s *Service) CheckName(ctx context.Context, in contracts.CheckNameInput) (contracts.CheckNameOutput, error) {
+	if err := in.Validate(); err != nil {
+		return contracts.CheckNameOutput{}, apperrors.Wrap(apperrors.CodeInvalidInput, "validate check name input", err)
+	}
+
+	controlPlaneURL, err := s.controlPlaneURL(in.SakiControlPlaneURL)
+	if err != nil {
+		return contracts.CheckNameOutput{}, err
+	}
+	cp, err := s.newControlPlane(controlPlaneURL)
+	if err != nil {
+		return contracts.CheckNameOutput{}, err
+	}
+
+	availability, err := cp.CheckName(ctx, in.Name)
+	if err != nil {
+		return contracts.CheckNameOutput{}, err
+	}
+	return contracts.CheckNameOutput{
+		Name:       in.Name,
+		Available:  availability.Available,
+		OwnedByYou: availability.OwnedByYou,
+	}, nil
+}
+
+// ensureNameAvailable fails fast, before anything is built, when
+// SAKI_CHECK_NAME is enabled and another owner already uses name. Lookup
+// failures are logged and the deploy continues; POST /apps still enforces
+// ownership.
+func (s *Service) ensureNameAvailable(ctx context.Context, cp controlPlaneClient, name string) error {
+	if !envEnabled(envValue(s.checkNameValue)) {
+		return nil
+	}
+
+	availability, err := cp.CheckName(ctx, name)
+	if err != nil {
+		s.logger.Error("app name check failed; continuing with deploy", map[string]any{
+			"name":  name,
+			"error": err.Error(),
+		})
+		return nil
+	}
+	if !availability.Available && !availability.OwnedByYou {
+		return apperrors.New(apperrors.CodeInvalidInput, "check app name", fmt.Sprintf(
+			"app name %q is already taken by another owner; choose a different name", name,
+		))
+	}
+	return nil
+}
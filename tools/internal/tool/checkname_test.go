@@ -0,0 +1,124 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestDeployApp_ChecksNameBeforeBuilding(t *testing.T) {
+	tests := []struct {
+		name         string
+		availability controlplane.NameAvailability
+		checkErr     error
+		wantCode     apperrors.Code
+	}{
+		{name: "available", availability: controlplane.NameAvailability{Available: true}},
+		{name: "owned by you", availability: controlplane.NameAvailability{OwnedByYou: true}},
+		{name: "taken by other", wantCode: apperrors.CodeInvalidInput},
+		{name: "lookup failure continues", checkErr: errors.New("connection refused")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &stubControlPlane{
+				prepareRes: controlplane.PrepareAppResponse{
+					Repository:  "registry.internal/owner/my-app",
+					RequiredTag: "abc1234",
+				},
+				deployRes:    controlplane.DeployAppResponse{Status: "deploying"},
+				checkNameRes: tt.availability,
+				checkNameErr: tt.checkErr,
+			}
+			dockerStub := &stubDockerClient{}
+			svc := &Service{
+				newControlPlane:  func(string) (controlPlaneClient, error) { return cp, nil },
+				newDockerClient:  func(Logger) dockerClient { return dockerStub },
+				resolveGitCommit: func(context.Context) (string, error) { return "abc", nil },
+				checkNameValue:   func() string { return "true" },
+				logger:           &noopLogger{},
+			}
+
+			_, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				Name:                "my-app",
+				Description:         "internal app",
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				AppDir:              t.TempDir(),
+			})
+			if len(cp.checkNameReqs) != 1 || cp.checkNameReqs[0] != "my-app" {
+				t.Fatalf("expected one name check for my-app, got %v", cp.checkNameReqs)
+			}
+			if tt.wantCode != "" {
+				if got := apperrors.CodeOf(err); got != tt.wantCode {
+					t.Fatalf("expected code %q, got %q (%v)", tt.wantCode, got, err)
+				}
+				if !strings.Contains(err.Error(), "already taken by another owner") {
+					t.Fatalf("expected taken-name message, got %q", err.Error())
+				}
+				if len(cp.prepareReqs) != 0 || dockerStub.image != "" {
+					t.Fatal("expected deploy to stop before prepare and build")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDeployApp_NameCheckDisabledByDefault(t *testing.T) {
+	cp := &stubControlPlane{
+		prepareRes: controlplane.PrepareAppResponse{
+			Repository:  "registry.internal/owner/my-app",
+			RequiredTag: "abc1234",
+		},
+	}
+	svc := &Service{
+		newControlPlane:  func(string) (controlPlaneClient, error) { return cp, nil },
+		newDockerClient:  func(Logger) dockerClient { return &stubDockerClient{} },
+		resolveGitCommit: func(context.Context) (string, error) { return "abc", nil },
+		logger:           &noopLogger{},
+	}
+
+	if _, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		Name:                "my-app",
+		Description:         "internal app",
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppDir:              t.TempDir(),
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(cp.checkNameReqs) != 0 {
+		t.Fatalf("expected no name check by default, got %v", cp.checkNameReqs)
+	}
+}
+
+func TestCheckName_ReportsOwnership(t *testing.T) {
+	cp := &stubControlPlane{checkNameRes: controlplane.NameAvailability{OwnedByYou: true}}
+	svc := &Service{
+		newControlPlane: func(string) (controlPlaneClient, error) { return cp, nil },
+		logger:          &noopLogger{},
+	}
+
+	out, err := svc.CheckName(context.Background(), contracts.CheckNameInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		Name:                "my-app",
+	})
+	if err != nil {
+		t.Fatalf("check name: %v", err)
+	}
+	if out != (contracts.CheckNameOutput{Name: "my-app", OwnedByYou: true}) {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+
+	_, err = svc.CheckName(context.Background(), contracts.CheckNameInput{Name: "Bad_Name"})
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected code %q for invalid name, got %q", apperrors.CodeInvalidInput, got)
+	}
+}
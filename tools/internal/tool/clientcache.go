@@ -0,0 +1,62 @@
+package tool
+
+import (
+	"container/list"
+	"sync"
+)
+
+const defaultControlPlaneCacheSize = 16
+
+// controlPlaneCache reuses control plane clients per resolved URL so their
+// HTTP connection pools survive across deploys. It evicts the least recently
+// used client once more than size URLs have been seen.
+type controlPlaneCache struct {
+	mu      sync.Mutex
+	size    int
+	factory controlPlaneFactory
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type controlPlaneCacheEntry struct {
+	url    string
+	client controlPlaneClient
+}
+
+func newControlPlaneCache(size int, factory controlPlaneFactory) *controlPlaneCache {
+	if size < 1 {
+		size = 1
+	}
+	return &controlPlaneCache{
+		size:    size,
+		factory: factory,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached client for controlPlaneURL, creating it on first use.
+// Factory errors are not cached.
+func (c *controlPlaneCache) get(controlPlaneURL string) (controlPlaneClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[controlPlaneURL]; ok {
+		c.order.MoveToFront(elem)
+		return elem.Value.(*controlPlaneCacheEntry).client, nil
+	}
+
+	client, err := c.factory(controlPlaneURL)
+	if err != nil {
+		return nil, err
+	}
+
+	c.entries[controlPlaneURL] = c.order.PushFront(&controlPlaneCacheEntry{url: controlPlaneURL, client: client})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*controlPlaneCacheEntry).url)
+	}
+
+	return client, nil
+}
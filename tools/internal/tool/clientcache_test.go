@@ -0,0 +1,74 @@
+package tool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestControlPlaneCache_ReusesClientPerURL(t *testing.T) {
+	created := 0
+	cache := newControlPlaneCache(2, func(string) (controlPlaneClient, error) {
+		created++
+		return &stubControlPlane{}, nil
+	})
+
+	first, err := cache.get("https://cp.internal?token=a")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	second, err := cache.get("https://cp.internal?token=a")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if first != second {
+		t.Fatal("expected the same client instance for identical URLs")
+	}
+
+	other, err := cache.get("https://cp.internal?token=b")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if other == first {
+		t.Fatal("expected a distinct client for a different URL")
+	}
+	if created != 2 {
+		t.Fatalf("expected two clients to be created, got %d", created)
+	}
+}
+
+func TestControlPlaneCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newControlPlaneCache(2, func(string) (controlPlaneClient, error) {
+		return &stubControlPlane{}, nil
+	})
+
+	a, _ := cache.get("a")
+	_, _ = cache.get("b")
+	_, _ = cache.get("a")
+	_, _ = cache.get("c")
+
+	if _, ok := cache.entries["b"]; ok {
+		t.Fatal("expected least recently used URL to be evicted")
+	}
+	again, _ := cache.get("a")
+	if again != a {
+		t.Fatal("expected recently used client to survive eviction")
+	}
+}
+
+func TestControlPlaneCache_DoesNotCacheErrors(t *testing.T) {
+	factoryErr := errors.New("bad url")
+	calls := 0
+	cache := newControlPlaneCache(2, func(string) (controlPlaneClient, error) {
+		calls++
+		return nil, factoryErr
+	})
+
+	for range 2 {
+		if _, err := cache.get("bad"); !errors.Is(err, factoryErr) {
+			t.Fatalf("expected factory error, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected factory to be retried, got %d calls", calls)
+	}
+}
@@ -0,0 +1,178 @@
+package tool
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/1800agents/saki/tools/docker"
+)
+
+// ResolvedConfig is the effective deploy configuration after applying
+// environment overrides to defaults. It never contains secrets: the control
+// plane token is redacted and registry credentials are reported only as
+// present or absent.
+type ResolvedConfig struct {
+	DockerRegistry      string   `json:"docker_registry"`
+	ImageRegistry       string   `json:"image_registry"`
+	ImageRepository     string   `json:"image_repository"`
+	AllowedRegistries   []string `json:"allowed_registries"`
+	ControlPlaneURL     string   `json:"control_plane_url"`
+	TokenInHeader       bool     `json:"token_in_header"`
+	RegistryOnly        bool     `json:"registry_only"`
+	DeployTimeout       string   `json:"deploy_timeout"`
+	RetryBudget         string   `json:"retry_budget"`
+	SkipUnchanged       bool     `json:"skip_unchanged"`
+	RegistryCredentials bool     `json:"registry_credentials"`
+	RegistryTokenUser   string   `json:"registry_token_username"`
+	DockerConfigAuth    bool     `json:"docker_config_auth"`
+	DockerStderrLines   int      `json:"docker_stderr_lines"`
+	DefaultDockerignore bool     `json:"default_dockerignore"`
+	CancelOnAbort       bool     `json:"cancel_on_abort"`
+	CheckName           bool     `json:"check_name"`
+	LocalTag            bool     `json:"local_tag"`
+	StagedPush          bool     `json:"staged_push"`
+	ImmutableTags       bool     `json:"immutable_tags"`
+	PathCommit          bool     `json:"path_commit"`
+	GitCommit           string   `json:"git_commit"`
+	GitUnshallow        bool     `json:"git_unshallow"`
+	ContextHash         bool     `json:"context_hash"`
+	DockerfileHash      bool     `json:"dockerfile_hash"`
+	DockerfileContent   bool     `json:"dockerfile_content"`
+	UpdateExisting      bool     `json:"update_existing"`
+	ImageTagTemplate    string   `json:"image_tag_template"`
+	BuildxBuilder       string   `json:"buildx_builder"`
+	BuildCPUQuota       string   `json:"build_cpu_quota"`
+	BuildMemory         string   `json:"build_memory"`
+	SmokeCheck          bool     `json:"smoke_check"`
+	SmokeCheckPath      string   `json:"smoke_check_path"`
+	SmokeCheckTimeout   string   `json:"smoke_check_timeout"`
+	SmokeCheckRequired  bool     `json:"smoke_check_required"`
+}
+
+// ResolvedConfig resolves every deploy setting the way DeployApp would.
+// Invalid values fail with the same CodeConfig errors a deploy would report.
+func (s *Service) ResolvedConfig() (ResolvedConfig, error) {
+	deployTimeout, err := resolveDeployTimeout(envValue(s.deployTimeoutValue))
+	if err != nil {
+		return ResolvedConfig{}, err
+	}
+	smokeTimeout, err := resolveSmokeCheckTimeout(envValue(s.smokeCheckTimeoutValue))
+	if err != nil {
+		return ResolvedConfig{}, err
+	}
+	retries, err := resolveRetryBudget(envValue(s.retryBudgetValue))
+	if err != nil {
+		return ResolvedConfig{}, err
+	}
+	retryBudget := "unbounded"
+	if retries >= 0 {
+		retryBudget = strconv.Itoa(retries)
+	}
+
+	imageRepository, err := resolveImageRepositoryOverride("", envValue(s.imageRepositoryValue))
+	if err != nil {
+		return ResolvedConfig{}, err
+	}
+
+	gitCommit, err := resolveGitCommitOverride("", envValue(s.gitCommitValue))
+	if err != nil {
+		return ResolvedConfig{}, err
+	}
+
+	limits, err := s.buildLimits()
+	if err != nil {
+		return ResolvedConfig{}, err
+	}
+
+	controlPlaneURL, err := envControlPlaneURL(envValue(s.controlPlaneURLValue), envValue(s.controlPlaneFileValue))
+	if err != nil {
+		return ResolvedConfig{}, err
+	}
+
+	registry := resolveDockerRegistry(envValue(s.dockerRegistryValue))
+	allowed := parseRegistryAllowlist(envValue(s.allowedRegistriesValue))
+	if allowed == nil {
+		allowed = []string{}
+	}
+	_, _, hasCredentials := s.registryCredentials("")
+
+	return ResolvedConfig{
+		DockerRegistry:      registry,
+		ImageRegistry:       normalizeRegistryForImage(registry),
+		ImageRepository:     imageRepository,
+		AllowedRegistries:   allowed,
+		ControlPlaneURL:     redactControlPlaneURL(controlPlaneURL),
+		TokenInHeader:       envEnabled(envValue(s.tokenHeaderValue)),
+		RegistryOnly:        envEnabled(envValue(s.registryOnlyValue)),
+		DeployTimeout:       deployTimeout.String(),
+		RetryBudget:         retryBudget,
+		SkipUnchanged:       envEnabled(envValue(s.skipUnchangedValue)),
+		RegistryCredentials: hasCredentials,
+		RegistryTokenUser:   s.pushTokenUsername(),
+		DockerConfigAuth:    s.dockerConfigAuthEnabled(),
+		DockerStderrLines:   resolveStderrTailLines(envValue(s.stderrTailLinesValue)),
+		DefaultDockerignore: !envEnabled(envValue(s.skipDockerignoreValue)),
+		CancelOnAbort:       envEnabled(envValue(s.cancelOnAbortValue)),
+		CheckName:           envEnabled(envValue(s.checkNameValue)),
+		LocalTag:            s.localTagEnabled(),
+		StagedPush:          envEnabled(envValue(s.stagedPushValue)),
+		ImmutableTags:       envEnabled(envValue(s.immutableTagsValue)),
+		PathCommit:          envEnabled(envValue(s.pathCommitValue)),
+		GitCommit:           gitCommit,
+		GitUnshallow:        envEnabled(envValue(s.gitUnshallowValue)),
+		ContextHash:         envEnabled(envValue(s.contextHashValue)),
+		DockerfileHash:      envEnabled(envValue(s.dockerfileHashValue)),
+		DockerfileContent:   envEnabled(envValue(s.dockerfileContentValue)),
+		UpdateExisting:      envEnabled(envValue(s.updateExistingValue)),
+		ImageTagTemplate:    strings.TrimSpace(envValue(s.imageTagTemplateValue)),
+		BuildxBuilder:       strings.TrimSpace(envValue(s.buildxBuilderValue)),
+		BuildCPUQuota:       limits.cpuQuota,
+		BuildMemory:         limits.memory,
+		SmokeCheck:          envEnabled(envValue(s.smokeCheckValue)),
+		SmokeCheckPath:      firstNonEmpty(envValue(s.smokeCheckPathValue), defaultSmokeCheckPath),
+		SmokeCheckTimeout:   smokeTimeout.String(),
+		SmokeCheckRequired:  envEnabled(envValue(s.smokeCheckRequiredValue)),
+	}, nil
+}
+
+// resolveStderrTailLines parses SAKI_DOCKER_STDERR_LINES, falling back to the
+// docker adapter default when it is unset or not a positive integer.
+func resolveStderrTailLines(value string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 1 {
+		return docker.DefaultStderrTailLines
+	}
+	return n
+}
+
+// redactControlPlaneURL replaces the session token in a control plane URL so
+// the URL can be displayed. Unparsable URLs are hidden entirely.
+func redactControlPlaneURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "<redacted>"
+	}
+	u.User = nil
+	u.Fragment = ""
+
+	query := u.Query()
+	hasToken := query.Has("token")
+	query.Del("token")
+	u.RawQuery = query.Encode()
+
+	redacted := u.String()
+	if hasToken {
+		sep := "?"
+		if u.RawQuery != "" {
+			sep = "&"
+		}
+		redacted += sep + "token=<redacted>"
+	}
+	return redacted
+}
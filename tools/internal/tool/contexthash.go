@@ -0,0 +1,249 @@
+package tool
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const contextHashEnv = "SAKI_CONTEXT_HASH"
+
+// contextHash returns the build context hash for appDir when
+// SAKI_CONTEXT_HASH is enabled, and "" otherwise. Two deploys of the same
+// commit with different hashes built from different inputs, for example
+// untracked or generated files that .dockerignore lets through. Hashing
+// failures are logged and do not stop the build.
+func (s *Service) contextHash(appDir string) string {
+	if !envEnabled(envValue(s.contextHashValue)) {
+		return ""
+	}
+
+	hash, files, err := hashBuildContext(appDir)
+	if err != nil {
+		s.logger.Error("hashing build context failed; continuing", map[string]any{
+			"app_dir": appDir,
+			"error":   err.Error(),
+		})
+		return ""
+	}
+	s.logger.Info("build context hashed", map[string]any{
+		"app_dir":      appDir,
+		"context_hash": hash,
+		"files":        files,
+	})
+	return hash
+}
+
+// hashBuildContext hashes the files docker would send as the build context
+// of dir: every file not excluded by dir/.dockerignore, in path order, by
+// relative path, type, and content. Symlinks contribute their target rather
+// than the file they point to. It returns "sha256:<hex>" and the number of
+// files hashed.
+func hashBuildContext(dir string) (string, int, error) {
+	ignore, err := readDockerignore(dir)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var paths []string
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		// Docker always sends the Dockerfile and .dockerignore, even when
+		// excluded.
+		if ignore.excludes(rel) && rel != dockerfileName && rel != dockerignoreFile {
+			if d.IsDir() && !ignore.hasExceptions() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			paths = append(paths, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("walk build context: %w", err)
+	}
+	slices.Sort(paths)
+
+	sum := sha256.New()
+	for _, rel := range paths {
+		if err := hashContextFile(sum, dir, rel); err != nil {
+			return "", 0, err
+		}
+	}
+	return "sha256:" + hex.EncodeToString(sum.Sum(nil)), len(paths), nil
+}
+
+// hashContextFile writes rel's entry to sum: its path, a type marker, and its
+// content digest (or symlink target), NUL-separated.
+func hashContextFile(sum io.Writer, dir, rel string) error {
+	full := filepath.Join(dir, filepath.FromSlash(rel))
+	info, err := os.Lstat(full)
+	if err != nil {
+		return fmt.Errorf("hash build context: %w", err)
+	}
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(full)
+		if err != nil {
+			return fmt.Errorf("hash build context: %w", err)
+		}
+		_, err = fmt.Fprintf(sum, "%s\x00link\x00%s\x00", rel, target)
+		return err
+	}
+
+	file, err := os.Open(full)
+	if err != nil {
+		return fmt.Errorf("hash build context: %w", err)
+	}
+	defer file.Close()
+
+	content := sha256.New()
+	if _, err := io.Copy(content, file); err != nil {
+		return fmt.Errorf("hash build context: %s: %w", rel, err)
+	}
+	mode := "file"
+	if info.Mode()&0o111 != 0 {
+		mode = "exec"
+	}
+	_, err = fmt.Fprintf(sum, "%s\x00%s\x00%x\x00", rel, mode, content.Sum(nil))
+	return err
+}
+
+// dockerignoreRule is one .dockerignore line compiled to a path regexp.
+type dockerignoreRule struct {
+	pattern *regexp.Regexp
+	// exception is true for "!pattern" lines, which re-include paths.
+	exception bool
+}
+
+type dockerignoreRules []dockerignoreRule
+
+// readDockerignore parses dir/.dockerignore. A missing file excludes nothing.
+func readDockerignore(dir string) (dockerignoreRules, error) {
+	file, err := os.Open(filepath.Join(dir, dockerignoreFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", dockerignoreFile, err)
+	}
+	defer file.Close()
+
+	var rules dockerignoreRules
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := dockerignoreRule{}
+		if strings.HasPrefix(line, "!") {
+			rule.exception = true
+			line = strings.TrimSpace(line[1:])
+		}
+		line = strings.Trim(path.Clean(filepath.ToSlash(line)), "/")
+		if line == "" || line == "." {
+			continue
+		}
+		pattern, err := dockerignorePattern(line)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s pattern %q: %w", dockerignoreFile, line, err)
+		}
+		rule.pattern = pattern
+		rules = append(rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", dockerignoreFile, err)
+	}
+	return rules, nil
+}
+
+// excludes reports whether rel (slash-separated, relative to the context)
+// is left out of the build context. As in docker, the last matching rule
+// wins, and a pattern matching a directory also matches everything under it.
+func (r dockerignoreRules) excludes(rel string) bool {
+	excluded := false
+	for _, rule := range r {
+		if rule.matches(rel) {
+			excluded = !rule.exception
+		}
+	}
+	return excluded
+}
+
+func (r dockerignoreRules) hasExceptions() bool {
+	return slices.ContainsFunc(r, func(rule dockerignoreRule) bool { return rule.exception })
+}
+
+func (rule dockerignoreRule) matches(rel string) bool {
+	for {
+		if rule.pattern.MatchString(rel) {
+			return true
+		}
+		parent := path.Dir(rel)
+		if parent == "." || parent == rel {
+			return false
+		}
+		rel = parent
+	}
+}
+
+// dockerignorePattern compiles a .dockerignore pattern: "*" and "?" match
+// within one path segment, "**" matches any number of segments, and [...]
+// classes and "\" escapes work as in filepath.Match.
+func dockerignorePattern(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
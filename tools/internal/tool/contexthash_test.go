@@ -0,0 +1,145 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+)
+
+func writeContextFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+func TestHashBuildContext(t *testing.T) {
+	files := map[string]string{
+		"Dockerfile":          "FROM scratch\n",
+		".dockerignore":       "node_modules\n*.log\ndocs/**\n!docs/keep.md\n",
+		"main.go":             "package main\n",
+		"pkg/util.go":         "package pkg\n",
+		"node_modules/a.js":   "ignored",
+		"debug.log":           "ignored",
+		"docs/guide/intro.md": "ignored",
+		"docs/keep.md":        "kept",
+	}
+	first, second := t.TempDir(), t.TempDir()
+	writeContextFiles(t, first, files)
+	writeContextFiles(t, second, files)
+
+	hash, count, err := hashBuildContext(first)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if count != 5 {
+		t.Fatalf("expected 5 files in the context, got %d", count)
+	}
+	same, _, err := hashBuildContext(second)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if hash != same {
+		t.Fatalf("expected identical contexts to hash the same, got %s and %s", hash, same)
+	}
+
+	// Excluded files do not affect the hash.
+	writeContextFiles(t, second, map[string]string{"node_modules/b.js": "new", "trace.log": "new"})
+	if got, _, _ := hashBuildContext(second); got != hash {
+		t.Fatalf("expected ignored files to leave the hash alone, got %s want %s", got, hash)
+	}
+
+	// An untracked file that is part of the context changes it.
+	writeContextFiles(t, second, map[string]string{"generated.go": "package main\n"})
+	if got, _, _ := hashBuildContext(second); got == hash {
+		t.Fatal("expected a new context file to change the hash")
+	}
+
+	writeContextFiles(t, first, map[string]string{"pkg/util.go": "package pkg // changed\n"})
+	if got, _, _ := hashBuildContext(first); got == hash {
+		t.Fatal("expected a changed file to change the hash")
+	}
+}
+
+func TestDockerignoreRules(t *testing.T) {
+	dir := t.TempDir()
+	writeContextFiles(t, dir, map[string]string{
+		".dockerignore": "# comment\n/build\n**/*.tmp\nsecret?.txt\n.env*\n!.env.example\n",
+	})
+	rules, err := readDockerignore(dir)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	tests := map[string]bool{
+		"build":         true,
+		"build/out.bin": true,
+		"src/build":     false,
+		"a/b/c.tmp":     true,
+		"c.tmp":         true,
+		"secret1.txt":   true,
+		"secret12.txt":  false,
+		".env":          true,
+		".env.local":    true,
+		".env.example":  false,
+		"cmd/main.go":   false,
+	}
+	for rel, want := range tests {
+		if got := rules.excludes(rel); got != want {
+			t.Errorf("%s: expected excluded=%v, got %v", rel, want, got)
+		}
+	}
+}
+
+func TestDeployApp_SendsContextHash(t *testing.T) {
+	appDir := t.TempDir()
+	writeContextFiles(t, appDir, map[string]string{"Dockerfile": "FROM scratch\n"})
+
+	for _, enabled := range []bool{true, false} {
+		cp := &stubControlPlane{
+			prepareRes: controlplane.PrepareAppResponse{Repository: "registry.internal/owner/my-app", RequiredTag: "abc1234"},
+			deployRes:  controlplane.DeployAppResponse{AppID: "app_1", DeploymentID: "dep_1", Status: "deploying"},
+		}
+		env := ""
+		if enabled {
+			env = "1"
+		}
+		svc := &Service{
+			logger:           &noopLogger{},
+			newControlPlane:  func(string) (controlPlaneClient, error) { return cp, nil },
+			newDockerClient:  func(Logger) dockerClient { return &stubDockerClient{} },
+			resolveGitCommit: func(context.Context) (string, error) { return "abc", nil },
+			contextHashValue: func() string { return env },
+		}
+
+		if _, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+			SakiControlPlaneURL: "https://cp.internal?token=test-token",
+			Name:                "my-app",
+			Description:         "internal app",
+			AppDir:              appDir,
+		}); err != nil {
+			t.Fatalf("deploy: %v", err)
+		}
+
+		want, _, err := hashBuildContext(appDir)
+		if err != nil {
+			t.Fatalf("hash: %v", err)
+		}
+		if !enabled {
+			want = ""
+		}
+		if got := cp.deployReqs[0].ContextHash; got != want {
+			t.Fatalf("enabled=%v: expected context_hash %q, got %q", enabled, want, got)
+		}
+	}
+}
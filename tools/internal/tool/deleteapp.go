@@ -0,0 +1,43 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const statusDeleted = "deleted"
+
+// DeleteApp tears down the app with in.AppID. An app the control plane does
+// not know fails with CodeInvalidInput, so the caller can tell the user it
+// does not exist rather than retrying.
+func (s *Service) DeleteApp(ctx context.Context, in contracts.DeleteAppInput) (contracts.DeleteAppOutput, error) {
+	if err := in.Validate(); err != nil {
+		return contracts.DeleteAppOutput{}, apperrors.Wrap(apperrors.CodeInvalidInput, "validate delete app input", err)
+	}
+
+	controlPlaneURL, err := s.controlPlaneURL(in.SakiControlPlaneURL)
+	if err != nil {
+		return contracts.DeleteAppOutput{}, err
+	}
+	cp, err := s.newControlPlane(controlPlaneURL)
+	if err != nil {
+		return contracts.DeleteAppOutput{}, err
+	}
+
+	if err := cp.DeleteApp(ctx, in.AppID); err != nil {
+		var apiErr *controlplane.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return contracts.DeleteAppOutput{}, apperrors.Wrap(apperrors.CodeInvalidInput, "delete app", fmt.Errorf("app %q does not exist: %w", in.AppID, err))
+		}
+		return contracts.DeleteAppOutput{}, err
+	}
+
+	s.logger.Info("app deleted", map[string]any{"app_id": in.AppID})
+	return contracts.DeleteAppOutput{AppID: in.AppID, Status: statusDeleted}, nil
+}
@@ -0,0 +1,65 @@
+package tool
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/tool/tooltest"
+)
+
+func TestDeleteApp_RemovesDeployedApp(t *testing.T) {
+	cp := &tooltest.ControlPlane{}
+	svc := NewTestService(TestDeps{
+		ControlPlane: cp,
+		Docker:       &tooltest.Docker{},
+		Env:          map[string]string{controlPlaneURLEnv: "https://cp.internal?token=test-token"},
+	})
+
+	deployed, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		Name:        "my-app",
+		Description: "internal app",
+		AppDir:      t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("deploy: %v", err)
+	}
+
+	out, err := svc.DeleteApp(context.Background(), contracts.DeleteAppInput{AppID: deployed.AppID})
+	if err != nil {
+		t.Fatalf("delete app: %v", err)
+	}
+	if out != (contracts.DeleteAppOutput{AppID: deployed.AppID, Status: statusDeleted}) {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+
+	// The app is gone now, so a second delete reports it as missing.
+	_, err = svc.DeleteApp(context.Background(), contracts.DeleteAppInput{AppID: deployed.AppID})
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected code %q for a missing app, got %q (%v)", apperrors.CodeInvalidInput, got, err)
+	}
+	if !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected a does-not-exist message, got %v", err)
+	}
+}
+
+func TestDeleteApp_RequiresAppID(t *testing.T) {
+	cp := &stubControlPlane{}
+	svc := &Service{
+		newControlPlane: func(string) (controlPlaneClient, error) { return cp, nil },
+		logger:          &noopLogger{},
+	}
+
+	_, err := svc.DeleteApp(context.Background(), contracts.DeleteAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppID:               " ",
+	})
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected code %q, got %q", apperrors.CodeInvalidInput, got)
+	}
+	if len(cp.deleteReqs) != 0 {
+		t.Fatalf("expected no delete request, got %v", cp.deleteReqs)
+	}
+}
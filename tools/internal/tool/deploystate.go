@@ -0,0 +1,188 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// deployStateVersion is bumped when deployState changes incompatibly. State
+// files with another version are not resumed.
+const deployStateVersion = 1
+
+// deployState records how far a deploy got. With DeployAppInput.StateFile
+// set it is written after prepare, build, and push, so a deploy re-run with
+// Resume after a crash can skip the stages that already completed. The file
+// is removed once the deploy succeeds.
+type deployState struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	GitCommit string `json:"git_commit"`
+	// Prepare holds the push and deployment tokens, so the file is written
+	// readable by its owner only.
+	Prepare     controlplane.PrepareAppResponse `json:"prepare"`
+	Image       string                          `json:"image"`
+	Built       bool                            `json:"built,omitempty"`
+	Pushed      bool                            `json:"pushed,omitempty"`
+	ContextHash string                          `json:"context_hash,omitempty"`
+	BuildCache  *contracts.BuildCacheStats      `json:"build_cache,omitempty"`
+
+	path string
+}
+
+// openDeployState returns the deploy state for in, or nil when in has no
+// state file. Without Resume, or when the file does not exist yet, the
+// state starts empty. A state file for another app is rejected.
+func (s *Service) openDeployState(in contracts.DeployAppInput) (*deployState, error) {
+	path := strings.TrimSpace(in.StateFile)
+	if path == "" {
+		return nil, nil
+	}
+	fresh := &deployState{path: path}
+	if !in.Resume {
+		return fresh, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.logger.Info("no deploy state to resume; starting from prepare", map[string]any{"state_file": path})
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidInput, "read deploy state", err)
+	}
+
+	state := &deployState{}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidInput, "read deploy state", fmt.Errorf("parse %s: %w", path, err))
+	}
+	state.path = path
+	if state.Version != deployStateVersion {
+		s.logger.Info("deploy state version differs; starting from prepare", map[string]any{
+			"state_file": path,
+			"version":    state.Version,
+		})
+		return fresh, nil
+	}
+	if state.Name != in.Name {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "resume deploy", fmt.Sprintf(
+			"state file %s is for app %q, not %q; use another --state-file or drop --resume", path, state.Name, in.Name,
+		))
+	}
+	return state, nil
+}
+
+// resumedPrepare returns the saved prepare response when the state is for
+// commit and its push token is still valid. A state saved for another commit
+// fails with CodeInvalidInput: its image was built from different source.
+func (st *deployState) resumedPrepare(commit string, now time.Time) (controlplane.PrepareAppResponse, bool, error) {
+	if st == nil || st.Image == "" {
+		return controlplane.PrepareAppResponse{}, false, nil
+	}
+	if st.GitCommit != commit {
+		return controlplane.PrepareAppResponse{}, false, apperrors.New(apperrors.CodeInvalidInput, "resume deploy", fmt.Sprintf(
+			"state file %s was written for commit %s, but the app is at %s; drop --resume to start over", st.path, st.GitCommit, commit,
+		))
+	}
+	if expires := st.Prepare.ExpiresAt; !expires.IsZero() && !now.Before(expires) {
+		return controlplane.PrepareAppResponse{}, false, nil
+	}
+	return st.Prepare, true, nil
+}
+
+// recordPrepare stores the prepare outcome. A prepare that yields another
+// image invalidates the saved build and push.
+func (st *deployState) recordPrepare(name string, prepared preparedImage) {
+	if st == nil {
+		return
+	}
+	if st.Image != prepared.image {
+		st.Built, st.Pushed, st.ContextHash, st.BuildCache = false, false, "", nil
+	}
+	st.Version = deployStateVersion
+	st.Name = name
+	st.GitCommit = prepared.commit
+	st.Prepare = prepared.prepare
+	st.Image = prepared.image
+}
+
+// recordBuild marks the image as built from a context with contextHash.
+func (st *deployState) recordBuild(contextHash string, cache *contracts.BuildCacheStats) {
+	if st != nil {
+		st.Built, st.ContextHash, st.BuildCache = true, contextHash, cache
+	}
+}
+
+// recordPush marks the image as pushed.
+func (st *deployState) recordPush() {
+	if st != nil {
+		st.Pushed = true
+	}
+}
+
+func (st *deployState) built() bool  { return st != nil && st.Built }
+func (st *deployState) pushed() bool { return st != nil && st.Pushed }
+
+// saveDeployState writes state, if any. A failed write is logged and does not
+// stop the deploy; it only means a later resume starts further back.
+func (s *Service) saveDeployState(state *deployState) {
+	if state == nil {
+		return
+	}
+	if err := state.write(); err != nil {
+		s.logger.Error("writing deploy state failed; continuing", map[string]any{
+			"state_file": state.path,
+			"error":      err.Error(),
+		})
+	}
+}
+
+// clearDeployState removes the state file after a successful deploy.
+func (s *Service) clearDeployState(state *deployState) {
+	if state == nil {
+		return
+	}
+	if err := os.Remove(state.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		s.logger.Error("removing deploy state failed", map[string]any{
+			"state_file": state.path,
+			"error":      err.Error(),
+		})
+	}
+}
+
+// write replaces the state file atomically, so a crash mid-write leaves the
+// previous checkpoint intact.
+func (st *deployState) write() error {
+	content, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := st.path + ".tmp"
+	if err := os.WriteFile(tmp, append(content, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, st.path)
+}
+
+// builtImageAvailable reports whether the state's build can be reused: the
+// build completed and the image is still in the local docker image store.
+func (s *Service) builtImageAvailable(ctx context.Context, dockerClient dockerClient, state *deployState) bool {
+	if !state.built() {
+		return false
+	}
+	id, err := dockerClient.LocalImageID(ctx, state.Image)
+	if err != nil || id == "" {
+		s.logger.Info("image from deploy state not found locally; rebuilding", map[string]any{"image": state.Image})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,240 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func newStateTestService(cp *stubControlPlane, dockerStub *stubDockerClient, commit string) *Service {
+	return &Service{
+		logger:              &noopLogger{},
+		newControlPlane:     func(string) (controlPlaneClient, error) { return cp, nil },
+		newDockerClient:     func(Logger) dockerClient { return dockerStub },
+		resolveGitCommit:    func(context.Context) (string, error) { return commit, nil },
+		now:                 func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) },
+		dockerRegistryValue: func() string { return "registry.internal" },
+	}
+}
+
+func stateTestInput(t *testing.T, stateFile string, resume bool) contracts.DeployAppInput {
+	t.Helper()
+	return contracts.DeployAppInput{
+		Name:                "my-app",
+		Description:         "internal app",
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppDir:              t.TempDir(),
+		StateFile:           stateFile,
+		Resume:              resume,
+	}
+}
+
+func readStateFile(t *testing.T, path string) deployState {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read state file: %v", err)
+	}
+	var state deployState
+	if err := json.Unmarshal(content, &state); err != nil {
+		t.Fatalf("decode state file: %v", err)
+	}
+	return state
+}
+
+func TestDeployApp_ResumeSkipsCompletedStages(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "deploy-state.json")
+	prepareRes := controlplane.PrepareAppResponse{
+		Repository:  "registry.internal/owner/my-app",
+		RequiredTag: "abc1234",
+		ExpiresAt:   time.Date(2026, 3, 1, 13, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name string
+		// failFirst makes the first run fail at push or deploy.
+		failFirst   func(cp *stubControlPlane, dockerStub *stubDockerClient)
+		localID     string
+		wantState   func(state deployState) bool
+		wantBuild   bool
+		wantPush    bool
+		wantSkipped []string
+	}{
+		{
+			name:        "crash after build resumes at push",
+			failFirst:   func(_ *stubControlPlane, d *stubDockerClient) { d.pushErr = errors.New("connection reset") },
+			localID:     "sha256:built",
+			wantState:   func(st deployState) bool { return st.Built && !st.Pushed },
+			wantPush:    true,
+			wantSkipped: []string{StageBuild},
+		},
+		{
+			name:      "built image gone from docker is rebuilt",
+			failFirst: func(_ *stubControlPlane, d *stubDockerClient) { d.pushErr = errors.New("connection reset") },
+			wantState: func(st deployState) bool { return st.Built && !st.Pushed },
+			wantBuild: true,
+			wantPush:  true,
+		},
+		{
+			name:        "crash after push resumes at deploy",
+			failFirst:   func(cp *stubControlPlane, _ *stubDockerClient) { cp.deployErr = errors.New("connection reset") },
+			wantState:   func(st deployState) bool { return st.Built && st.Pushed },
+			wantSkipped: []string{StageBuild, StagePush},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &stubControlPlane{
+				prepareRes: prepareRes,
+				deployRes:  controlplane.DeployAppResponse{AppID: "app_1", DeploymentID: "dep_1", Status: "deploying"},
+			}
+			first := &stubDockerClient{}
+			tt.failFirst(cp, first)
+			if _, err := newStateTestService(cp, first, "abc1234").DeployApp(context.Background(), stateTestInput(t, stateFile, false)); err == nil {
+				t.Fatal("expected the first deploy to fail")
+			}
+			if state := readStateFile(t, stateFile); !tt.wantState(state) || state.Image != "registry.internal/owner/my-app:abc1234" {
+				t.Fatalf("unexpected state after the failed deploy: %+v", state)
+			}
+
+			cp.deployErr = nil
+			second := &stubDockerClient{localID: tt.localID}
+			var skipped []string
+			progress := func(event ProgressEvent) {
+				if event.Status == ProgressSkipped {
+					skipped = append(skipped, event.Stage)
+				}
+			}
+			out, err := newStateTestService(cp, second, "abc1234").DeployAppWithProgress(context.Background(), stateTestInput(t, stateFile, true), progress)
+			if err != nil {
+				t.Fatalf("resumed deploy: %v", err)
+			}
+			if out.DeploymentID != "dep_1" {
+				t.Fatalf("unexpected output: %+v", out)
+			}
+
+			if len(cp.prepareReqs) != 1 {
+				t.Fatalf("expected prepare to run once across both deploys, got %d", len(cp.prepareReqs))
+			}
+			if built := second.image != ""; built != tt.wantBuild {
+				t.Fatalf("expected build=%v on resume, got %v", tt.wantBuild, built)
+			}
+			if pushed := second.pushImage != ""; pushed != tt.wantPush {
+				t.Fatalf("expected push=%v on resume, got %v", tt.wantPush, pushed)
+			}
+			if !slices.Equal(skipped, tt.wantSkipped) {
+				t.Fatalf("expected skipped stages %v, got %v", tt.wantSkipped, skipped)
+			}
+			if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+				t.Fatalf("expected the state file to be removed after success, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDeployApp_ResumeRejectsMismatchedState(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  deployState
+		commit string
+	}{
+		{name: "other app", state: deployState{Version: deployStateVersion, Name: "other-app", GitCommit: "abc1234", Image: "registry.internal/owner/other-app:abc1234"}, commit: "abc1234"},
+		{name: "other commit", state: deployState{Version: deployStateVersion, Name: "my-app", GitCommit: "def5678", Image: "registry.internal/owner/my-app:def5678"}, commit: "abc1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stateFile := filepath.Join(t.TempDir(), "deploy-state.json")
+			tt.state.path = stateFile
+			if err := tt.state.write(); err != nil {
+				t.Fatalf("write state: %v", err)
+			}
+			cp := &stubControlPlane{}
+			dockerStub := &stubDockerClient{}
+
+			_, err := newStateTestService(cp, dockerStub, tt.commit).DeployApp(context.Background(), stateTestInput(t, stateFile, true))
+			if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+				t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeInvalidInput, got, err)
+			}
+			if len(cp.prepareReqs) != 0 || dockerStub.image != "" {
+				t.Fatalf("expected nothing to run, got prepare=%d build=%q", len(cp.prepareReqs), dockerStub.image)
+			}
+		})
+	}
+}
+
+func TestDeployApp_ResumePreparesAgainWhenTokenExpired(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "deploy-state.json")
+	state := deployState{
+		Version:   deployStateVersion,
+		Name:      "my-app",
+		GitCommit: "abc1234",
+		Prepare: controlplane.PrepareAppResponse{
+			Repository:  "registry.internal/owner/my-app",
+			RequiredTag: "abc1234",
+			ExpiresAt:   time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC),
+		},
+		Image: "registry.internal/owner/my-app:abc1234",
+		Built: true,
+		path:  stateFile,
+	}
+	if err := state.write(); err != nil {
+		t.Fatalf("write state: %v", err)
+	}
+	cp := &stubControlPlane{
+		prepareRes: controlplane.PrepareAppResponse{Repository: "registry.internal/owner/my-app", RequiredTag: "abc1234"},
+		deployRes:  controlplane.DeployAppResponse{AppID: "app_1", DeploymentID: "dep_1", Status: "deploying"},
+	}
+	dockerStub := &stubDockerClient{localID: "sha256:built"}
+
+	if _, err := newStateTestService(cp, dockerStub, "abc1234").DeployApp(context.Background(), stateTestInput(t, stateFile, true)); err != nil {
+		t.Fatalf("resumed deploy: %v", err)
+	}
+	if len(cp.prepareReqs) != 1 {
+		t.Fatalf("expected an expired push token to be replaced by a new prepare, got %d prepare calls", len(cp.prepareReqs))
+	}
+	// The new prepare names the same image, so the earlier build is kept.
+	if dockerStub.image != "" || dockerStub.pushImage == "" {
+		t.Fatalf("expected push without a rebuild, got build=%q push=%q", dockerStub.image, dockerStub.pushImage)
+	}
+}
+
+func TestDeployApp_StateFileWithoutResumeStartsOver(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "deploy-state.json")
+	stale := deployState{Version: deployStateVersion, Name: "other-app", GitCommit: "def5678", Image: "x:y", Built: true, Pushed: true, path: stateFile}
+	if err := stale.write(); err != nil {
+		t.Fatalf("write state: %v", err)
+	}
+	cp := &stubControlPlane{
+		prepareRes: controlplane.PrepareAppResponse{Repository: "registry.internal/owner/my-app", RequiredTag: "abc1234"},
+		deployErr:  errors.New("connection reset"),
+	}
+	dockerStub := &stubDockerClient{}
+
+	if _, err := newStateTestService(cp, dockerStub, "abc1234").DeployApp(context.Background(), stateTestInput(t, stateFile, false)); err == nil {
+		t.Fatal("expected deploy error")
+	}
+	if len(cp.prepareReqs) != 1 || dockerStub.image == "" {
+		t.Fatalf("expected a full run, got prepare=%d build=%q", len(cp.prepareReqs), dockerStub.image)
+	}
+	if state := readStateFile(t, stateFile); state.Name != "my-app" || state.GitCommit != "abc1234" || !state.Pushed {
+		t.Fatalf("expected the state to be overwritten, got %+v", state)
+	}
+	info, err := os.Stat(stateFile)
+	if err != nil {
+		t.Fatalf("stat state file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("expected the state file to be private, got %v", perm)
+	}
+}
@@ -0,0 +1,88 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/1800agents/saki/tools/controlplane"
+)
+
+const updateExistingEnv = "SAKI_UPDATE_EXISTING"
+
+// defaultDeployRetry applies only to deploys that carry a prepare deployment
+// token: the control plane dedupes POST /apps on it, so a retry cannot create
+// a second deployment. Each attempt keeps the client's own request timeout.
+var defaultDeployRetry = prepareRetryPolicy{
+	attempts: 3,
+	delay:    2 * time.Second,
+}
+
+// postDeploy calls POST /apps, or PATCH /apps/{updateAppID} when
+// updateAppID is set. Without a deployment token it is sent exactly once.
+// With one, network errors, timeouts, and 5xx responses are retried with the
+// same token, bounded by the deploy's retry budget.
+func (s *Service) postDeploy(ctx context.Context, cp controlPlaneClient, updateAppID string, req controlplane.DeployAppRequest) (controlplane.DeployAppResponse, error) {
+	send := cp.DeployApp
+	if updateAppID != "" {
+		send = func(ctx context.Context, req controlplane.DeployAppRequest) (controlplane.DeployAppResponse, error) {
+			return cp.UpdateApp(ctx, updateAppID, req)
+		}
+	}
+	if req.DeploymentToken == "" {
+		return send(ctx, req)
+	}
+
+	policy := defaultDeployRetry
+	if s.deployRetry != nil {
+		policy = *s.deployRetry
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= max(policy.attempts, 1); attempt++ {
+		res, err := send(ctx, req)
+		if err == nil {
+			return res, nil
+		}
+		lastErr = err
+
+		if !retryableDeployError(err) || ctx.Err() != nil || attempt >= policy.attempts {
+			break
+		}
+		if !s.takeRetry(ctx, StageDeploy) {
+			break
+		}
+		s.logger.Info("deploy request failed; retrying with the same deployment token", map[string]any{
+			"attempt": attempt,
+			"error":   err.Error(),
+		})
+		if !sleepContext(ctx, s.retryDelay(policy.delay)) {
+			break
+		}
+	}
+
+	return controlplane.DeployAppResponse{}, lastErr
+}
+
+// updateAppID returns the app to update with PATCH /apps/{app_id}: the
+// prepare existing_app_id when SAKI_UPDATE_EXISTING is enabled, and ""
+// otherwise.
+func (s *Service) updateAppID(prepare controlplane.PrepareAppResponse) string {
+	if !envEnabled(envValue(s.updateExistingValue)) {
+		return ""
+	}
+	return strings.TrimSpace(prepare.ExistingAppID)
+}
+
+// retryableDeployError reports whether a POST /apps failure may be transient:
+// the request never got an answer, or the control plane answered 5xx.
+func retryableDeployError(err error) bool {
+	var reqErr *controlplane.RequestError
+	if errors.As(err, &reqErr) {
+		return true
+	}
+	var apiErr *controlplane.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= http.StatusInternalServerError
+}
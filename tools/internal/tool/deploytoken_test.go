@@ -0,0 +1,136 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+)
+
+// flakyDeployControlPlane fails the first failures POST /apps calls with err.
+type flakyDeployControlPlane struct {
+	*stubControlPlane
+	failures int
+	err      error
+}
+
+func (c *flakyDeployControlPlane) DeployApp(ctx context.Context, req controlplane.DeployAppRequest) (controlplane.DeployAppResponse, error) {
+	res, err := c.stubControlPlane.DeployApp(ctx, req)
+	if len(c.deployReqs) <= c.failures {
+		return controlplane.DeployAppResponse{}, c.err
+	}
+	return res, err
+}
+
+func TestDeployApp_DeploymentTokenMakesDeployRetrySafe(t *testing.T) {
+	unavailable := &controlplane.APIError{StatusCode: http.StatusServiceUnavailable, Message: "try again"}
+
+	tests := []struct {
+		name         string
+		token        string
+		deployErr    error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "token is reused across retries", token: "dep-token-1", deployErr: unavailable, wantAttempts: 2},
+		{name: "network errors are retried", token: "dep-token-1", deployErr: &controlplane.RequestError{Operation: "deploy app", Err: errors.New("connection reset")}, wantAttempts: 2},
+		{name: "without a token deploy is sent once", deployErr: unavailable, wantAttempts: 1, wantErr: true},
+		{name: "client errors are not retried", token: "dep-token-1", deployErr: &controlplane.APIError{StatusCode: http.StatusBadRequest, Message: "bad image"}, wantAttempts: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &flakyDeployControlPlane{
+				stubControlPlane: &stubControlPlane{
+					prepareRes: controlplane.PrepareAppResponse{
+						Repository:      "registry.internal/owner/my-app",
+						RequiredTag:     "abc1234",
+						DeploymentToken: tt.token,
+					},
+					deployRes: controlplane.DeployAppResponse{AppID: "app_1", DeploymentID: "dep_1", Status: "deploying"},
+				},
+				failures: 1,
+				err:      tt.deployErr,
+			}
+			svc := &Service{
+				logger:           &noopLogger{},
+				newControlPlane:  func(string) (controlPlaneClient, error) { return cp, nil },
+				newDockerClient:  func(Logger) dockerClient { return &stubDockerClient{} },
+				resolveGitCommit: func(context.Context) (string, error) { return "abc", nil },
+				deployRetry:      &prepareRetryPolicy{attempts: 3},
+			}
+
+			out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				Name:                "my-app",
+				Description:         "internal app",
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				AppDir:              t.TempDir(),
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && out.DeploymentID != "dep_1" {
+				t.Fatalf("unexpected output: %+v", out)
+			}
+
+			if len(cp.deployReqs) != tt.wantAttempts {
+				t.Fatalf("expected %d deploy attempts, got %d", tt.wantAttempts, len(cp.deployReqs))
+			}
+			for i, req := range cp.deployReqs {
+				if req.DeploymentToken != tt.token {
+					t.Fatalf("attempt %d sent deployment token %q, want %q", i+1, req.DeploymentToken, tt.token)
+				}
+			}
+		})
+	}
+}
+
+func TestDeployApp_IdempotencyKeyIsStablePerDeploy(t *testing.T) {
+	cp := &flakyDeployControlPlane{
+		stubControlPlane: &stubControlPlane{
+			prepareRes: controlplane.PrepareAppResponse{
+				Repository:      "registry.internal/owner/my-app",
+				RequiredTag:     "abc1234",
+				DeploymentToken: "dep-token-1",
+			},
+			deployRes: controlplane.DeployAppResponse{AppID: "app_1", DeploymentID: "dep_1", Status: "deploying"},
+		},
+		failures: 1,
+		err:      &controlplane.APIError{StatusCode: http.StatusServiceUnavailable, Message: "try again"},
+	}
+	svc := &Service{
+		logger:           &noopLogger{},
+		newControlPlane:  func(string) (controlPlaneClient, error) { return cp, nil },
+		newDockerClient:  func(Logger) dockerClient { return &stubDockerClient{} },
+		resolveGitCommit: func(context.Context) (string, error) { return "abc", nil },
+		deployRetry:      &prepareRetryPolicy{attempts: 3},
+	}
+	in := contracts.DeployAppInput{
+		Name:                "my-app",
+		Description:         "internal app",
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppDir:              t.TempDir(),
+	}
+
+	if _, err := svc.DeployApp(context.Background(), in); err != nil {
+		t.Fatalf("first deploy: %v", err)
+	}
+	if _, err := svc.DeployApp(context.Background(), in); err != nil {
+		t.Fatalf("second deploy: %v", err)
+	}
+
+	// The first deploy is retried once; the second succeeds first time.
+	if len(cp.deployReqs) != 3 {
+		t.Fatalf("expected 3 POST /apps attempts, got %d", len(cp.deployReqs))
+	}
+	first, retry, second := cp.deployReqs[0].IdempotencyKey, cp.deployReqs[1].IdempotencyKey, cp.deployReqs[2].IdempotencyKey
+	if first == "" || first != retry {
+		t.Fatalf("expected the retry to reuse the key, got %q then %q", first, retry)
+	}
+	if second == first {
+		t.Fatalf("expected a separate deploy to use a new key, got %q twice", second)
+	}
+}
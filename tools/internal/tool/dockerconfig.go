@@ -0,0 +1,117 @@
+package tool
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	dockerConfigAuthEnv = "SAKI_DOCKER_CONFIG_AUTH"
+
+	// dockerConfigDirEnv is docker's own override for ~/.docker.
+	dockerConfigDirEnv = "DOCKER_CONFIG"
+)
+
+// dockerConfigFile is the subset of docker's config.json that records where
+// registry credentials live.
+type dockerConfigFile struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		IdentityToken string `json:"identitytoken"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// dockerConfigAuthEnabled reports whether SAKI_DOCKER_CONFIG_AUTH asks to
+// reuse credentials already in docker's config instead of running docker login.
+func (s *Service) dockerConfigAuthEnabled() bool {
+	return envEnabled(envValue(s.dockerConfigAuthValue))
+}
+
+// hasExistingCredentials reports whether docker already holds credentials for
+// registry. A config that cannot be read is logged and treated as holding
+// none, so the deploy falls back to docker login.
+func (s *Service) hasExistingCredentials(registry string) bool {
+	if s.dockerCredentials == nil {
+		return false
+	}
+	ok, err := s.dockerCredentials(registry)
+	if err != nil {
+		s.logger.Error("reading docker config credentials failed; logging in instead", map[string]any{
+			"registry": registry,
+			"error":    err.Error(),
+		})
+		return false
+	}
+	return ok
+}
+
+// dockerConfigCredentials reports whether config.json in $DOCKER_CONFIG (or
+// ~/.docker) has credentials for registry. A missing file holds none.
+func dockerConfigCredentials(registry string) (bool, error) {
+	dir := os.Getenv(dockerConfigDirEnv)
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return false, err
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return parseDockerConfigCredentials(data, registry)
+}
+
+// parseDockerConfigCredentials reports whether the docker config in data has
+// credentials for registry: a credential helper for it, an inline auth or
+// identity token, or an auths entry kept by the global credential store.
+// Secrets are never decoded.
+func parseDockerConfigCredentials(data []byte, registry string) (bool, error) {
+	var cfg dockerConfigFile
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return false, err
+	}
+
+	registry = dockerConfigHost(registry)
+	for host, helper := range cfg.CredHelpers {
+		if dockerConfigHost(host) == registry && helper != "" {
+			return true, nil
+		}
+	}
+	for host, auth := range cfg.Auths {
+		if dockerConfigHost(host) != registry {
+			continue
+		}
+		if auth.Auth != "" || auth.IdentityToken != "" || cfg.CredsStore != "" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// dockerConfigHost reduces a config.json key such as
+// https://index.docker.io/v1/ to the host registryHost reports for images.
+func dockerConfigHost(key string) string {
+	host := strings.TrimSpace(key)
+	host = strings.TrimPrefix(host, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	if slash := strings.IndexByte(host, '/'); slash >= 0 {
+		host = host[:slash]
+	}
+	host = strings.ToLower(host)
+	if dockerHubAPIHosts[host] {
+		return dockerHubHost
+	}
+	return host
+}
@@ -0,0 +1,140 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/tool/tooltest"
+)
+
+func TestDeployApp_DockerConfigAuth(t *testing.T) {
+	const image = "registry.internal/owner/my-app:0123456"
+
+	tests := []struct {
+		name      string
+		enabled   string
+		hasCreds  bool
+		readErr   error
+		wantLogin bool
+	}{
+		{name: "existing credentials skip login", enabled: "1", hasCreds: true, wantLogin: false},
+		{name: "no credentials log in with push token", enabled: "1", hasCreds: false, wantLogin: true},
+		{name: "unreadable config logs in", enabled: "1", readErr: errors.New("permission denied"), wantLogin: true},
+		{name: "disabled ignores existing credentials", hasCreds: true, wantLogin: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerStub := &tooltest.Docker{}
+			svc := NewTestService(TestDeps{
+				ControlPlane: &tooltest.ControlPlane{},
+				Docker:       dockerStub,
+				Env: map[string]string{
+					dockerRegistryEnv:   "registry.internal",
+					dockerConfigAuthEnv: tt.enabled,
+				},
+			})
+			var asked []string
+			svc.dockerCredentials = func(registry string) (bool, error) {
+				asked = append(asked, registry)
+				return tt.hasCreds, tt.readErr
+			}
+
+			if _, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				Name:                "my-app",
+				Description:         "internal app",
+				AppDir:              t.TempDir(),
+			}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			wantCalls := []string{"build " + image, "push " + image}
+			if tt.wantLogin {
+				wantCalls = append([]string{"login registry.internal"}, wantCalls...)
+			}
+			if !reflect.DeepEqual(dockerStub.Calls, wantCalls) {
+				t.Fatalf("unexpected docker calls:\ngot  %q\nwant %q", dockerStub.Calls, wantCalls)
+			}
+
+			var wantAsked []string
+			if tt.enabled != "" {
+				wantAsked = []string{"registry.internal"}
+			}
+			if !reflect.DeepEqual(asked, wantAsked) {
+				t.Fatalf("unexpected credential lookups: got %q want %q", asked, wantAsked)
+			}
+		})
+	}
+}
+
+func TestParseDockerConfigCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		registry string
+		want     bool
+	}{
+		{
+			name:     "inline auth",
+			config:   `{"auths":{"registry.internal":{"auth":"c2FraTpzZWNyZXQ="}}}`,
+			registry: "registry.internal",
+			want:     true,
+		},
+		{
+			name:     "identity token under a url key",
+			config:   `{"auths":{"https://registry.internal:5000/v2/":{"identitytoken":"tok"}}}`,
+			registry: "registry.internal:5000",
+			want:     true,
+		},
+		{
+			name:     "credential store entry",
+			config:   `{"auths":{"ghcr.io":{}},"credsStore":"desktop"}`,
+			registry: "ghcr.io",
+			want:     true,
+		},
+		{
+			name:     "credential helper",
+			config:   `{"credHelpers":{"us-docker.pkg.dev":"gcloud"}}`,
+			registry: "us-docker.pkg.dev",
+			want:     true,
+		},
+		{
+			name:     "docker hub index key",
+			config:   `{"auths":{"https://index.docker.io/v1/":{"auth":"c2FraTpzZWNyZXQ="}}}`,
+			registry: "docker.io",
+			want:     true,
+		},
+		{
+			name:     "empty entry without a store",
+			config:   `{"auths":{"registry.internal":{}}}`,
+			registry: "registry.internal",
+			want:     false,
+		},
+		{
+			name:     "other registry only",
+			config:   `{"auths":{"ghcr.io":{"auth":"c2FraTpzZWNyZXQ="}},"credsStore":"desktop"}`,
+			registry: "registry.internal",
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDockerConfigCredentials([]byte(tt.config), tt.registry)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := parseDockerConfigCredentials([]byte("{"), "registry.internal"); err == nil {
+		t.Fatal("expected error for malformed config")
+	}
+}
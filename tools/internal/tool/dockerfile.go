@@ -0,0 +1,70 @@
+package tool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	dockerfileHashEnv    = "SAKI_DOCKERFILE_HASH"
+	dockerfileContentEnv = "SAKI_DOCKERFILE_CONTENT"
+
+	// dockerfileName is the Dockerfile docker build reads from the context
+	// root; deploys never pass --file.
+	dockerfileName = "Dockerfile"
+
+	// maxDockerfileContentSize bounds the Dockerfile content sent with
+	// POST /apps. Larger Dockerfiles are still hashed.
+	maxDockerfileContentSize = 16 << 10
+)
+
+// dockerfileProvenance describes the Dockerfile an image was built from,
+// for the control plane to record with the deployment.
+type dockerfileProvenance struct {
+	// hash is "sha256:<hex>" of the Dockerfile bytes.
+	hash string
+	// content is the Dockerfile itself, when SAKI_DOCKERFILE_CONTENT is
+	// enabled and it fits maxDockerfileContentSize.
+	content string
+}
+
+// dockerfileProvenance reads appDir's Dockerfile when SAKI_DOCKERFILE_HASH or
+// SAKI_DOCKERFILE_CONTENT is enabled. A missing Dockerfile is left to docker
+// build to report; other read failures are logged and do not stop the deploy.
+func (s *Service) dockerfileProvenance(appDir string) dockerfileProvenance {
+	withContent := envEnabled(envValue(s.dockerfileContentValue))
+	if !withContent && !envEnabled(envValue(s.dockerfileHashValue)) {
+		return dockerfileProvenance{}
+	}
+
+	path := filepath.Join(appDir, dockerfileName)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return dockerfileProvenance{}
+	}
+	if err != nil {
+		s.logger.Error("reading Dockerfile for provenance failed; continuing", map[string]any{
+			"path":  path,
+			"error": err.Error(),
+		})
+		return dockerfileProvenance{}
+	}
+
+	sum := sha256.Sum256(content)
+	provenance := dockerfileProvenance{hash: "sha256:" + hex.EncodeToString(sum[:])}
+	included := withContent && len(content) <= maxDockerfileContentSize
+	if included {
+		provenance.content = string(content)
+	}
+	s.logger.Info("Dockerfile hashed", map[string]any{
+		"path":             path,
+		"dockerfile_hash":  provenance.hash,
+		"bytes":            len(content),
+		"content_included": included,
+	})
+	return provenance
+}
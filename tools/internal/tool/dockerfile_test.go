@@ -0,0 +1,73 @@
+package tool
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+)
+
+func TestDeployApp_SendsDockerfileProvenance(t *testing.T) {
+	const dockerfile = "FROM scratch\nCOPY app /app\n"
+	sum := sha256.Sum256([]byte(dockerfile))
+	wantHash := "sha256:" + hex.EncodeToString(sum[:])
+	large := "FROM scratch\n" + strings.Repeat("# padding\n", maxDockerfileContentSize/10)
+	largeSum := sha256.Sum256([]byte(large))
+
+	tests := []struct {
+		name        string
+		dockerfile  string
+		hashEnv     string
+		contentEnv  string
+		wantHash    string
+		wantContent string
+	}{
+		{name: "disabled", dockerfile: dockerfile},
+		{name: "hash", dockerfile: dockerfile, hashEnv: "1", wantHash: wantHash},
+		{name: "content implies hash", dockerfile: dockerfile, contentEnv: "true", wantHash: wantHash, wantContent: dockerfile},
+		{name: "oversized content is left out", dockerfile: large, contentEnv: "1", wantHash: "sha256:" + hex.EncodeToString(largeSum[:])},
+		{name: "missing Dockerfile is skipped", hashEnv: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appDir := t.TempDir()
+			if tt.dockerfile != "" {
+				writeContextFiles(t, appDir, map[string]string{"Dockerfile": tt.dockerfile})
+			}
+			cp := &stubControlPlane{
+				prepareRes: controlplane.PrepareAppResponse{Repository: "registry.internal/owner/my-app", RequiredTag: "abc1234"},
+				deployRes:  controlplane.DeployAppResponse{AppID: "app_1", DeploymentID: "dep_1", Status: "deploying"},
+			}
+			svc := &Service{
+				logger:                 &noopLogger{},
+				newControlPlane:        func(string) (controlPlaneClient, error) { return cp, nil },
+				newDockerClient:        func(Logger) dockerClient { return &stubDockerClient{} },
+				resolveGitCommit:       func(context.Context) (string, error) { return "abc", nil },
+				dockerfileHashValue:    func() string { return tt.hashEnv },
+				dockerfileContentValue: func() string { return tt.contentEnv },
+			}
+
+			if _, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				Name:                "my-app",
+				Description:         "internal app",
+				AppDir:              appDir,
+			}); err != nil {
+				t.Fatalf("deploy: %v", err)
+			}
+
+			req := cp.deployReqs[0]
+			if req.DockerfileHash != tt.wantHash {
+				t.Fatalf("expected dockerfile_hash %q, got %q", tt.wantHash, req.DockerfileHash)
+			}
+			if req.Dockerfile != tt.wantContent {
+				t.Fatalf("expected dockerfile %q, got %q", tt.wantContent, req.Dockerfile)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package tool
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const dockerignoreFile = ".dockerignore"
+
+// defaultDockerignore keeps VCS metadata, dependencies, and env files (which
+// may hold secrets) out of the build context.
+const defaultDockerignore = `.git
+node_modules
+.env
+.env.*
+`
+
+// ensureDockerignore writes defaultDockerignore into appDir unless a
+// .dockerignore already exists there or SAKI_SKIP_DOCKERIGNORE is enabled. An
+// existing file is never overwritten. Write failures are logged and do not
+// stop the build.
+func (s *Service) ensureDockerignore(appDir string) {
+	if envEnabled(envValue(s.skipDockerignoreValue)) {
+		return
+	}
+
+	path := filepath.Join(appDir, dockerignoreFile)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return
+	}
+	if err == nil {
+		_, err = file.WriteString(defaultDockerignore)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		s.logger.Error("writing default .dockerignore failed; continuing", map[string]any{
+			"path":  path,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	s.logger.Info("wrote default .dockerignore", map[string]any{"path": path})
+}
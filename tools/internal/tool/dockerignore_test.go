@@ -0,0 +1,71 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+)
+
+func TestDeployApp_WritesDefaultDockerignoreOnlyWhenAbsent(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		skip     string
+		want     string
+	}{
+		{name: "absent file gets default", want: defaultDockerignore},
+		{name: "existing file is kept", existing: "dist\n", want: "dist\n"},
+		{name: "opt-out writes nothing", skip: "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appDir := t.TempDir()
+			path := filepath.Join(appDir, ".dockerignore")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatalf("write existing .dockerignore: %v", err)
+				}
+			}
+
+			cp := &stubControlPlane{
+				prepareRes: controlplane.PrepareAppResponse{Repository: "registry.internal/owner/my-app", RequiredTag: "abc1234"},
+				deployRes:  controlplane.DeployAppResponse{AppID: "app_123", Status: "deploying"},
+			}
+			svc := &Service{
+				logger:                &noopLogger{},
+				newControlPlane:       func(string) (controlPlaneClient, error) { return cp, nil },
+				newDockerClient:       func(Logger) dockerClient { return &stubDockerClient{} },
+				resolveGitCommit:      func(context.Context) (string, error) { return "abc", nil },
+				skipDockerignoreValue: func() string { return tt.skip },
+			}
+
+			if _, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				Name:                "my-app",
+				Description:         "internal app",
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				AppDir:              appDir,
+			}); err != nil {
+				t.Fatalf("DeployApp returned error: %v", err)
+			}
+
+			got, err := os.ReadFile(path)
+			if tt.want == "" {
+				if !os.IsNotExist(err) {
+					t.Fatalf("expected no .dockerignore, got %q (err=%v)", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("read .dockerignore: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("unexpected .dockerignore contents: got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/docker"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// FanOutOptions controls DeployAppToTargets.
+type FanOutOptions struct {
+	// FailFast skips the remaining targets after the first deploy failure.
+	FailFast bool
+	// Registries optionally selects a registry per target, aligned with the
+	// targets (for example a staging and a prod registry). The built image is
+	// tagged and pushed to each listed registry and that target deploys the
+	// retagged image. Empty entries, and targets past the end, use the image
+	// as built.
+	Registries []string
+}
+
+// DeployAppToTargets builds and pushes the image once, then deploys it to each
+// control-plane URL in targets. Prepare runs against the first target, so all
+// targets must accept images from that registry unless opts.Registries gives
+// them their own. Outputs are aligned with
+// targets; failed or skipped targets have a zero output and their errors are
+// aggregated into an *apperrors.Multi.
+func (s *Service) DeployAppToTargets(ctx context.Context, in contracts.DeployAppInput, targets []string, opts FanOutOptions) ([]contracts.DeployAppOutput, error) {
+	if len(targets) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "deploy to targets", "at least one control plane target is required")
+	}
+	for _, target := range targets {
+		if strings.TrimSpace(target) == "" {
+			return nil, apperrors.New(apperrors.CodeInvalidInput, "deploy to targets", "control plane target must not be empty")
+		}
+	}
+	if in.DryRun {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "deploy to targets", "dry runs are not supported for multi-target deploys")
+	}
+	if s.localTagEnabled() {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "deploy to targets", "multi-target deploys need the control plane prepare; unset "+localTagEnv)
+	}
+	if in.ValidateOnly {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "deploy to targets", "validate-only is not supported for multi-target deploys")
+	}
+	if len(opts.Registries) > len(targets) {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "deploy to targets", "more target registries than targets")
+	}
+	if hasTargetRegistry(opts.Registries) && (docker.BuildOptions{Platforms: in.Platforms}).PushesOnBuild() {
+		return nil, apperrors.New(apperrors.CodeInvalidInput, "deploy to targets", "per-target registries need a single-platform build; multi-platform images are pushed while building and cannot be retagged")
+	}
+
+	outputs := make([]contracts.DeployAppOutput, len(targets))
+	errs := make([]error, len(targets))
+
+	in.SakiControlPlaneURL = targets[0]
+	err := s.withDeployTimeout(ctx, in, func(ctx context.Context) error {
+		prepared, err := s.prepareImage(ctx, in, nil, nil)
+		if err != nil {
+			return err
+		}
+		buildCache, contextHash, err := s.buildAndPush(ctx, in, prepared, nil)
+		if err != nil {
+			return err
+		}
+		prepared.contextHash = contextHash
+
+		pushed := map[string]preparedImage{}
+		failed := false
+		for i, target := range targets {
+			if failed && opts.FailFast {
+				errs[i] = targetError(target, context.Canceled)
+				continue
+			}
+
+			out, err := s.deployToTarget(ctx, target, registryAt(opts.Registries, i), in, prepared, pushed)
+			if err != nil {
+				s.logger.Error("fan-out deploy failed", map[string]any{
+					"target": targetLabel(target),
+					"error":  err.Error(),
+				})
+				errs[i] = targetError(target, err)
+				failed = true
+				continue
+			}
+			out.BuildCache = buildCache
+			outputs[i] = out
+		}
+		return nil
+	})
+	if err != nil {
+		return outputs, err
+	}
+
+	return outputs, apperrors.NewMulti(errs...)
+}
+
+// deployToTarget deploys prepared to target, first pushing it to registry
+// when one is set.
+func (s *Service) deployToTarget(ctx context.Context, target, registry string, in contracts.DeployAppInput, prepared preparedImage, pushed map[string]preparedImage) (contracts.DeployAppOutput, error) {
+	controlPlaneURL, err := resolveControlPlaneURL(target, "")
+	if err != nil {
+		return contracts.DeployAppOutput{}, err
+	}
+	prepared, err = s.pushToTargetRegistry(ctx, prepared, registry, pushed)
+	if err != nil {
+		return contracts.DeployAppOutput{}, err
+	}
+	cp, err := s.newControlPlane(controlPlaneURL)
+	if err != nil {
+		return contracts.DeployAppOutput{}, err
+	}
+	return s.deployImage(ctx, cp, in, prepared, nil)
+}
+
+// pushToTargetRegistry tags the built image for registry and pushes it there,
+// returning prepared rewritten to the retagged image. Only static registry
+// credentials are used to log in, since the prepare push token belongs to the
+// first target's registry. pushed caches the result per repository so targets
+// sharing a registry push once.
+func (s *Service) pushToTargetRegistry(ctx context.Context, prepared preparedImage, registry string, pushed map[string]preparedImage) (preparedImage, error) {
+	if strings.TrimSpace(registry) == "" {
+		return prepared, nil
+	}
+	repository := resolveImageRepository(prepared.repository, registry)
+	if repository == prepared.repository {
+		return prepared, nil
+	}
+	if cached, ok := pushed[repository]; ok {
+		return cached, nil
+	}
+
+	if err := checkRegistryAllowed(repository, envValue(s.allowedRegistriesValue)); err != nil {
+		return preparedImage{}, err
+	}
+	image, err := buildImageName(repository, prepared.tag)
+	if err != nil {
+		return preparedImage{}, err
+	}
+
+	dockerClient := s.newDockerClient(s.logger)
+	if err := s.registryLogin(ctx, dockerClient, repository, "", nil); err != nil {
+		return preparedImage{}, err
+	}
+	if err := dockerClient.Tag(ctx, prepared.image, image); err != nil {
+		return preparedImage{}, err
+	}
+	if err := dockerClient.Push(ctx, image, docker.PushOptions{}); err != nil {
+		return preparedImage{}, err
+	}
+	s.logger.Info("image pushed to target registry", map[string]any{
+		"source": prepared.image,
+		"image":  image,
+	})
+
+	retagged := prepared
+	retagged.repository = repository
+	retagged.image = image
+	pushed[repository] = retagged
+	return retagged, nil
+}
+
+func registryAt(registries []string, i int) string {
+	if i < len(registries) {
+		return registries[i]
+	}
+	return ""
+}
+
+func hasTargetRegistry(registries []string) bool {
+	for _, registry := range registries {
+		if strings.TrimSpace(registry) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func targetError(target string, err error) error {
+	return fmt.Errorf("target %q: %w", targetLabel(target), err)
+}
+
+// targetLabel identifies a control-plane target without its session token.
+func targetLabel(target string) string {
+	parsed, err := url.Parse(strings.TrimSpace(target))
+	if err != nil || parsed.Host == "" {
+		return "<invalid url>"
+	}
+	return parsed.Scheme + "://" + parsed.Host + parsed.Path
+}
@@ -0,0 +1,225 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/docker"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestDeployAppToTargets_BuildsOnceAndDeploysEach(t *testing.T) {
+	primary := &stubControlPlane{
+		prepareRes: controlplane.PrepareAppResponse{Repository: "registry.internal/owner/my-app", RequiredTag: "abc1234"},
+		deployRes:  controlplane.DeployAppResponse{AppID: "app_a", URL: "https://my-app.a.internal", Status: "deploying"},
+	}
+	secondary := &stubControlPlane{
+		deployRes: controlplane.DeployAppResponse{AppID: "app_b", URL: "https://my-app.b.internal", Status: "deploying"},
+	}
+	builder := &countingDockerClient{}
+	svc := newFanOutTestService(map[string]*stubControlPlane{
+		"https://a.internal": primary,
+		"https://b.internal": secondary,
+	}, builder)
+
+	outputs, err := svc.DeployAppToTargets(context.Background(), fanOutInput(t), []string{
+		"https://a.internal?token=a-token",
+		"https://b.internal?token=b-token",
+	}, FanOutOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if builder.builds != 1 || builder.pushes != 1 {
+		t.Fatalf("expected a single build and push, got builds=%d pushes=%d", builder.builds, builder.pushes)
+	}
+	if len(primary.prepareReqs) != 1 || len(secondary.prepareReqs) != 0 {
+		t.Fatalf("expected prepare only against the first target, got %d and %d", len(primary.prepareReqs), len(secondary.prepareReqs))
+	}
+	if len(primary.deployReqs) != 1 || len(secondary.deployReqs) != 1 {
+		t.Fatalf("expected one deploy per target, got %d and %d", len(primary.deployReqs), len(secondary.deployReqs))
+	}
+
+	const image = "registry.corgi-teeth.ts.net/owner/my-app:abc1234"
+	if primary.deployReqs[0].Image != image || secondary.deployReqs[0].Image != image {
+		t.Fatalf("expected both targets to deploy %q, got %q and %q", image, primary.deployReqs[0].Image, secondary.deployReqs[0].Image)
+	}
+	if len(outputs) != 2 || outputs[0].AppID != "app_a" || outputs[1].AppID != "app_b" {
+		t.Fatalf("unexpected outputs: %+v", outputs)
+	}
+}
+
+func TestDeployAppToTargets_CollectsTargetFailures(t *testing.T) {
+	deployErr := apperrors.New(apperrors.CodeControlPlaneAPI, "deploy app", "boom")
+	targets := map[string]*stubControlPlane{
+		"https://a.internal": {
+			prepareRes: controlplane.PrepareAppResponse{Repository: "registry.internal/owner/my-app", RequiredTag: "abc1234"},
+			deployErr:  deployErr,
+		},
+		"https://b.internal": {deployRes: controlplane.DeployAppResponse{AppID: "app_b"}},
+	}
+	urls := []string{"https://a.internal?token=a-token", "https://b.internal?token=b-token"}
+
+	svc := newFanOutTestService(targets, &countingDockerClient{})
+	outputs, err := svc.DeployAppToTargets(context.Background(), fanOutInput(t), urls, FanOutOptions{})
+	var multi *apperrors.Multi
+	if !errors.As(err, &multi) || len(multi.Errors) != 1 || !errors.Is(err, deployErr) {
+		t.Fatalf("expected only the first target to fail, got %v", err)
+	}
+	if strings.Contains(err.Error(), "a-token") {
+		t.Fatalf("expected target token to be omitted from error, got %v", err)
+	}
+	if outputs[1].AppID != "app_b" {
+		t.Fatalf("expected second target to deploy despite first failure, got %+v", outputs)
+	}
+
+	targets["https://b.internal"].deployReqs = nil
+	_, err = svc.DeployAppToTargets(context.Background(), fanOutInput(t), urls, FanOutOptions{FailFast: true})
+	if !errors.As(err, &multi) || len(multi.Errors) != 2 || !errors.Is(multi.Errors[1], context.Canceled) {
+		t.Fatalf("expected fail-fast to skip the second target, got %v", err)
+	}
+	if len(targets["https://b.internal"].deployReqs) != 0 {
+		t.Fatal("expected no deploy against the skipped target")
+	}
+}
+
+func TestDeployAppToTargets_PushesPerTargetRegistry(t *testing.T) {
+	staging := &stubControlPlane{
+		prepareRes: controlplane.PrepareAppResponse{Repository: "registry.internal/owner/my-app", RequiredTag: "abc1234", PushToken: "staging-push-token"},
+		deployRes:  controlplane.DeployAppResponse{AppID: "app_staging"},
+	}
+	prod := &stubControlPlane{deployRes: controlplane.DeployAppResponse{AppID: "app_prod"}}
+	mirror := &stubControlPlane{deployRes: controlplane.DeployAppResponse{AppID: "app_mirror"}}
+	builder := &countingDockerClient{}
+	svc := newFanOutTestService(map[string]*stubControlPlane{
+		"https://staging.internal": staging,
+		"https://prod.internal":    prod,
+		"https://mirror.internal":  mirror,
+	}, builder)
+	svc.dockerRegistryValue = func() string { return "registry.staging" }
+	svc.registryUserValue = func() string { return "robot" }
+	svc.registryPassValue = func() string { return "static-secret" }
+
+	_, err := svc.DeployAppToTargets(context.Background(), fanOutInput(t), []string{
+		"https://staging.internal?token=s",
+		"https://prod.internal?token=p",
+		"https://mirror.internal?token=m",
+	}, FanOutOptions{Registries: []string{"", "https://registry.prod:5000/v2/", "registry.prod:5000"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const (
+		stagingImage = "registry.staging/owner/my-app:abc1234"
+		prodImage    = "registry.prod:5000/owner/my-app:abc1234"
+	)
+	if got := staging.deployReqs[0].Image; got != stagingImage {
+		t.Fatalf("staging deployed %q, want %q", got, stagingImage)
+	}
+	if got := prod.deployReqs[0].Image; got != prodImage {
+		t.Fatalf("prod deployed %q, want %q", got, prodImage)
+	}
+	if got := mirror.deployReqs[0].Image; got != prodImage {
+		t.Fatalf("mirror deployed %q, want %q", got, prodImage)
+	}
+
+	if builder.builds != 1 {
+		t.Fatalf("expected a single build, got %d", builder.builds)
+	}
+	if want := []string{stagingImage + " " + prodImage}; !reflect.DeepEqual(builder.tags, want) {
+		t.Fatalf("unexpected tags: got %q want %q", builder.tags, want)
+	}
+	if want := []string{stagingImage, prodImage}; !reflect.DeepEqual(builder.pushed, want) {
+		t.Fatalf("unexpected pushes: got %q want %q", builder.pushed, want)
+	}
+	if want := []string{"registry.staging", "registry.prod:5000"}; !reflect.DeepEqual(builder.logins, want) {
+		t.Fatalf("unexpected logins: got %q want %q", builder.logins, want)
+	}
+}
+
+func TestDeployAppToTargets_RejectsRegistriesForMultiPlatformBuilds(t *testing.T) {
+	in := fanOutInput(t)
+	in.Platforms = []string{"linux/amd64", "linux/arm64"}
+
+	svc := newFanOutTestService(nil, &countingDockerClient{})
+	_, err := svc.DeployAppToTargets(context.Background(), in, []string{"https://a.internal?token=a"}, FanOutOptions{Registries: []string{"registry.prod"}})
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidInput {
+		t.Fatalf("expected %s, got %v", apperrors.CodeInvalidInput, err)
+	}
+}
+
+func newFanOutTestService(targets map[string]*stubControlPlane, builder *countingDockerClient) *Service {
+	return &Service{
+		newControlPlane: func(rawURL string) (controlPlaneClient, error) {
+			for prefix, cp := range targets {
+				if strings.HasPrefix(rawURL, prefix) {
+					return cp, nil
+				}
+			}
+			return nil, errors.New("unexpected control plane " + rawURL)
+		},
+		newDockerClient: func(Logger) dockerClient {
+			return builder
+		},
+		resolveGitCommit: func(context.Context) (string, error) { return "abc", nil },
+		logger:           &noopLogger{},
+	}
+}
+
+func fanOutInput(t *testing.T) contracts.DeployAppInput {
+	return contracts.DeployAppInput{
+		Name:        "my-app",
+		Description: "internal app",
+		AppDir:      t.TempDir(),
+	}
+}
+
+type countingDockerClient struct {
+	builds int
+	pushes int
+	logins []string
+	tags   []string
+	pushed []string
+}
+
+func (c *countingDockerClient) Login(_ context.Context, registry, _, _ string) error {
+	c.logins = append(c.logins, registry)
+	return nil
+}
+
+func (c *countingDockerClient) Build(context.Context, string, string, docker.BuildOptions) error {
+	c.builds++
+	return nil
+}
+
+func (c *countingDockerClient) Push(_ context.Context, image string, _ docker.PushOptions) error {
+	c.pushes++
+	c.pushed = append(c.pushed, image)
+	return nil
+}
+
+func (c *countingDockerClient) Tag(_ context.Context, source, target string) error {
+	c.tags = append(c.tags, source+" "+target)
+	return nil
+}
+
+func (c *countingDockerClient) CopyImage(context.Context, string, string) error {
+	return nil
+}
+
+func (c *countingDockerClient) InspectManifest(context.Context, string) error {
+	return nil
+}
+
+func (c *countingDockerClient) LocalImageID(context.Context, string) (string, error) {
+	return "", nil
+}
+
+func (c *countingDockerClient) RemoteImageID(context.Context, string) (string, bool, error) {
+	return "", false, nil
+}
@@ -0,0 +1,82 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Standard OCI annotation keys applied to built images as labels.
+const (
+	ociRevisionLabel = "org.opencontainers.image.revision"
+	ociSourceLabel   = "org.opencontainers.image.source"
+	ociCreatedLabel  = "org.opencontainers.image.created"
+)
+
+// gitMetadata returns OCI provenance labels for the image built from commit.
+// The source label is omitted when the repository has no origin remote; any
+// credentials in the remote URL are stripped.
+func (s *Service) gitMetadata(ctx context.Context, commit string) map[string]string {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+
+	labels := map[string]string{
+		ociRevisionLabel: commit,
+		ociCreatedLabel:  now().UTC().Format(time.RFC3339),
+	}
+
+	if s.runGit == nil {
+		return labels
+	}
+	remote, err := s.runGit(ctx, "config", "--get", "remote.origin.url")
+	if err != nil {
+		s.logger.Info("git origin remote unavailable; omitting image source label", map[string]any{
+			"error": err.Error(),
+		})
+		return labels
+	}
+	if source := redactRemoteURL(remote); source != "" {
+		labels[ociSourceLabel] = source
+	}
+	return labels
+}
+
+// redactRemoteURL drops user info, query, and fragment from URL-style git
+// remotes, where tokens are typically embedded. scp-style remotes
+// (git@host:org/repo.git) carry no credentials and are returned as is.
+func redactRemoteURL(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if !strings.Contains(remote, "://") {
+		return remote
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// runGit runs git and returns its trimmed stdout. A failed command's error
+// includes its stderr.
+func runGit(ctx context.Context, args ...string) (string, error) {
+	output, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(bytes.TrimSpace(exitErr.Stderr)) > 0 {
+			return "", fmt.Errorf("%w: %s", err, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
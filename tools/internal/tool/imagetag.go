@@ -0,0 +1,116 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const imageTagTemplateEnv = "SAKI_IMAGE_TAG_TEMPLATE"
+
+var (
+	// imageTagPlaceholderPattern matches one {name} placeholder.
+	imageTagPlaceholderPattern = regexp.MustCompile(`\{([^{}]*)\}`)
+	// invalidTagCharPattern matches runs of characters docker tags cannot
+	// hold; branch names such as feature/login are rewritten to feature-login.
+	invalidTagCharPattern = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+)
+
+// imageTagValues are the values SAKI_IMAGE_TAG_TEMPLATE placeholders expand to.
+type imageTagValues struct {
+	commit string
+	branch string
+	date   string
+}
+
+// imageTag returns the tag to deploy for commit: requiredTag (the prepare
+// required_tag, or the short SHA under SAKI_LOCAL_TAG), or the
+// SAKI_IMAGE_TAG_TEMPLATE rendering when the template is set. The control
+// plane still validates the tag when POST /apps receives the image.
+func (s *Service) imageTag(ctx context.Context, commit, requiredTag string) (string, error) {
+	template := strings.TrimSpace(envValue(s.imageTagTemplateValue))
+	if template == "" {
+		return requiredTag, nil
+	}
+
+	values := imageTagValues{commit: commit}
+	if strings.Contains(template, "{branch}") {
+		branch, err := s.gitBranch(ctx)
+		if err != nil {
+			return "", err
+		}
+		values.branch = branch
+	}
+	if strings.Contains(template, "{date}") {
+		now := time.Now
+		if s.now != nil {
+			now = s.now
+		}
+		values.date = now().UTC().Format("20060102")
+	}
+
+	tag, err := renderImageTag(template, values)
+	if err != nil {
+		return "", err
+	}
+	s.logger.Info("image tag rendered from template", map[string]any{
+		"template":     template,
+		"tag":          tag,
+		"required_tag": requiredTag,
+	})
+	return tag, nil
+}
+
+// gitBranch returns the current branch with characters docker tags cannot
+// hold replaced by "-".
+func (s *Service) gitBranch(ctx context.Context) (string, error) {
+	if s.runGit == nil {
+		return "", apperrors.New(apperrors.CodeConfig, "render image tag", imageTagTemplateEnv+" uses {branch} but git is unavailable")
+	}
+	branch, err := s.runGit(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", apperrors.Wrap(apperrors.CodeConfig, "render image tag", fmt.Errorf("resolve git branch for {branch}: %w", err))
+	}
+	branch = strings.Trim(invalidTagCharPattern.ReplaceAllString(strings.TrimSpace(branch), "-"), "-")
+	if branch == "" || branch == "HEAD" {
+		return "", apperrors.New(apperrors.CodeConfig, "render image tag", imageTagTemplateEnv+" uses {branch} but HEAD is detached")
+	}
+	return branch, nil
+}
+
+// renderImageTag expands {commit}, {short}, {branch} and {date} in template
+// and checks the result is a valid docker tag.
+func renderImageTag(template string, values imageTagValues) (string, error) {
+	var unknown []string
+	tag := imageTagPlaceholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		switch placeholder {
+		case "{commit}":
+			return values.commit
+		case "{short}":
+			return values.commit[:min(len(values.commit), localTagLength)]
+		case "{branch}":
+			return values.branch
+		case "{date}":
+			return values.date
+		}
+		unknown = append(unknown, placeholder)
+		return placeholder
+	})
+	if len(unknown) > 0 {
+		return "", apperrors.New(apperrors.CodeConfig, "render image tag", fmt.Sprintf(
+			"%s %q has unknown placeholder %s (use {commit}, {short}, {branch}, or {date})",
+			imageTagTemplateEnv, template, unknown[0],
+		))
+	}
+	if !dockerTagPattern.MatchString(tag) {
+		return "", apperrors.New(apperrors.CodeConfig, "render image tag", fmt.Sprintf(
+			"%s %q renders %q, which is not a valid docker tag (letters, digits, '_', '.', and '-', at most 128 characters, not starting with '.' or '-')",
+			imageTagTemplateEnv, template, tag,
+		))
+	}
+	return tag, nil
+}
@@ -0,0 +1,115 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/tool/tooltest"
+)
+
+func TestRenderImageTag(t *testing.T) {
+	values := imageTagValues{
+		commit: "0123456789abcdef0123456789abcdef01234567",
+		branch: "feature-login",
+		date:   "20260301",
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  string
+	}{
+		{name: "branch and short sha", template: "{branch}-{short}", want: "feature-login-0123456"},
+		{name: "date and full sha", template: "{date}-{commit}", want: "20260301-0123456789abcdef0123456789abcdef01234567"},
+		{name: "literal text", template: "release.{short}", want: "release.0123456"},
+		{name: "unknown placeholder", template: "{sha}", wantErr: "unknown placeholder {sha}"},
+		{name: "leading dash", template: "-{short}", wantErr: `renders "-0123456", which is not a valid docker tag`},
+		{name: "illegal character", template: "{branch}:{short}", wantErr: "not a valid docker tag"},
+		{name: "too long", template: "{commit}{commit}{commit}{commit}", wantErr: "not a valid docker tag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderImageTag(tt.template, values)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if code := apperrors.CodeOf(err); code != apperrors.CodeConfig {
+					t.Fatalf("expected code %q, got %q", apperrors.CodeConfig, code)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("expected %q, got %q (err=%v)", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestDeployApp_UsesImageTagTemplate(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		branch    string
+		wantImage string
+		wantErr   string
+	}{
+		{name: "unset keeps the required tag", wantImage: "registry.internal/owner/my-app:0123456"},
+		{name: "branch is sanitized", template: "{branch}-{short}", branch: "feature/login", wantImage: "registry.internal/owner/my-app:feature-login-0123456"},
+		{name: "date", template: "{date}-{short}", wantImage: "registry.internal/owner/my-app:20260301-0123456"},
+		{name: "detached head", template: "{branch}-{short}", branch: "HEAD", wantErr: "HEAD is detached"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &tooltest.ControlPlane{}
+			svc := NewTestService(TestDeps{
+				ControlPlane: cp,
+				Docker:       &tooltest.Docker{},
+				Env: map[string]string{
+					dockerRegistryEnv:   "registry.internal",
+					imageTagTemplateEnv: tt.template,
+				},
+			})
+			svc.now = func() time.Time { return time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC) }
+			svc.runGit = func(_ context.Context, args ...string) (string, error) {
+				if strings.Join(args, " ") == "rev-parse --abbrev-ref HEAD" {
+					return tt.branch + "\n", nil
+				}
+				return "", errors.New("exit status 1")
+			}
+
+			out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				Name:                "my-app",
+				Description:         "internal app",
+				AppDir:              t.TempDir(),
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if len(cp.Deployed) != 0 {
+					t.Fatalf("expected no deploy, got %+v", cp.Deployed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if out.Image != tt.wantImage {
+				t.Fatalf("expected image %q, got %q", tt.wantImage, out.Image)
+			}
+			if len(cp.Deployed) != 1 || cp.Deployed[0].Image != tt.wantImage {
+				t.Fatalf("expected POST /apps with %q, got %+v", tt.wantImage, cp.Deployed)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/docker"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const immutableTagsEnv = "SAKI_IMMUTABLE_TAGS"
+
+// checkTagConflict enforces SAKI_IMMUTABLE_TAGS: before image is pushed, a tag
+// that already exists in the registry must hold the same content as the local
+// build, compared by config digest. A mismatch fails with CodeConflict unless
+// the deploy sets force. Multi-platform builds push while building, so they
+// cannot be checked.
+func (s *Service) checkTagConflict(ctx context.Context, dockerClient dockerClient, in contracts.DeployAppInput, image string, buildOpts docker.BuildOptions) error {
+	if !envEnabled(envValue(s.immutableTagsValue)) || buildOpts.PushesOnBuild() {
+		return nil
+	}
+
+	remoteID, exists, err := dockerClient.RemoteImageID(ctx, image)
+	if err != nil || !exists {
+		return err
+	}
+	localID, err := dockerClient.LocalImageID(ctx, image)
+	if err != nil {
+		return err
+	}
+	if remoteID == localID {
+		return nil
+	}
+
+	if in.Force {
+		s.logger.Error("image tag already exists with different content; overwriting because force is set", map[string]any{
+			"image":     image,
+			"local_id":  localID,
+			"remote_id": remoteID,
+		})
+		return nil
+	}
+	return apperrors.New(apperrors.CodeConflict, "check image tag", fmt.Sprintf("%s already exists in the registry with different content (remote %s, local %s); set force to overwrite it", image, displayImageID(remoteID), localID))
+}
+
+func displayImageID(id string) string {
+	if id == "" {
+		return "multi-platform index"
+	}
+	return id
+}
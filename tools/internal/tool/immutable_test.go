@@ -0,0 +1,66 @@
+package tool
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/tool/tooltest"
+)
+
+func TestDeployApp_ImmutableTagsRejectsConflictingDigest(t *testing.T) {
+	const image = "registry.internal/owner/my-app:0123456"
+
+	tests := []struct {
+		name     string
+		remoteID string
+		force    bool
+		wantCode apperrors.Code
+	}{
+		{name: "different remote content is a conflict", remoteID: "sha256:remote", wantCode: apperrors.CodeConflict},
+		{name: "force overwrites different content", remoteID: "sha256:remote", force: true},
+		{name: "identical content is pushed again", remoteID: "sha256:local"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &tooltest.ControlPlane{}
+			dockerStub := &tooltest.Docker{
+				LocalID:   "sha256:local",
+				RemoteIDs: map[string]string{image: tt.remoteID},
+			}
+			svc := NewTestService(TestDeps{
+				ControlPlane: cp,
+				Docker:       dockerStub,
+				Env:          map[string]string{dockerRegistryEnv: "registry.internal", immutableTagsEnv: "1"},
+			})
+
+			_, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				Name:                "my-app",
+				Description:         "internal app",
+				AppDir:              t.TempDir(),
+				Force:               tt.force,
+			})
+
+			pushed := slices.Contains(dockerStub.Calls, "push "+image)
+			if tt.wantCode != "" {
+				if got := apperrors.CodeOf(err); got != tt.wantCode {
+					t.Fatalf("expected %s, got %s (%v)", tt.wantCode, got, err)
+				}
+				if pushed || len(cp.Deployed) != 0 {
+					t.Fatalf("expected no push or deploy, got calls=%q deploys=%d", dockerStub.Calls, len(cp.Deployed))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !pushed || len(cp.Deployed) != 1 {
+				t.Fatalf("expected push and deploy, got calls=%q deploys=%d", dockerStub.Calls, len(cp.Deployed))
+			}
+		})
+	}
+}
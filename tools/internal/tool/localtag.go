@@ -0,0 +1,126 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const (
+	localTagEnv = "SAKI_LOCAL_TAG"
+
+	// localTagLength matches git's default abbreviated commit length.
+	localTagLength = 7
+)
+
+// dockerTagPattern is the reference grammar for an image tag.
+var dockerTagPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+// localTagEnabled reports whether SAKI_LOCAL_TAG asks to skip prepare and tag
+// images from the local git commit, for air-gapped registry-only pushes.
+func (s *Service) localTagEnabled() bool {
+	return envEnabled(envValue(s.localTagValue))
+}
+
+// localImageTag derives the content-addressed tag for commit: its short SHA.
+func localImageTag(commit string) (string, error) {
+	tag := commit
+	if len(tag) > localTagLength {
+		tag = tag[:localTagLength]
+	}
+	if !dockerTagPattern.MatchString(tag) {
+		return "", apperrors.New(apperrors.CodeConfig, "compute local image tag", fmt.Sprintf("git commit %q does not form a valid docker tag", commit))
+	}
+	return tag, nil
+}
+
+// deployLocalTag builds and pushes without any control plane call: the tag
+// comes from the git commit (or SAKI_IMAGE_TAG_TEMPLATE) and the repository
+// from image_repository (or SAKI_IMAGE_REPOSITORY), falling back to
+// <registry>/<name>. Like registry-only mode it stops after the push.
+func (s *Service) deployLocalTag(ctx context.Context, in contracts.DeployAppInput, progress ProgressFunc) (contracts.DeployAppOutput, error) {
+	if in.DryRun {
+		return contracts.DeployAppOutput{}, apperrors.New(apperrors.CodeInvalidInput, "deploy app", "dry runs need the control plane prepare; unset "+localTagEnv)
+	}
+
+	prepared, err := s.prepareLocalImage(ctx, in, progress)
+	if err != nil {
+		return contracts.DeployAppOutput{}, err
+	}
+	buildCache, _, err := s.buildAndPush(ctx, in, prepared, progress)
+	if err != nil {
+		return contracts.DeployAppOutput{}, err
+	}
+	out := contracts.DeployAppOutput{
+		Image:      prepared.image,
+		Status:     "pushed",
+		GitCommit:  prepared.commit,
+		BuildCache: buildCache,
+	}
+	s.notifyWebhook(ctx, in.Name, out)
+	return out, nil
+}
+
+// prepareLocalImage is the prepare stage of deployLocalTag.
+func (s *Service) prepareLocalImage(ctx context.Context, in contracts.DeployAppInput, progress ProgressFunc) (preparedImage, error) {
+	var zero preparedImage
+
+	repositoryOverride, err := resolveImageRepositoryOverride(in.ImageRepository, envValue(s.imageRepositoryValue))
+	if err != nil {
+		return zero, err
+	}
+
+	progress.started(StagePrepare)
+	commit, err := s.gitCommit(ctx, in)
+	if err != nil {
+		progress.failed(StagePrepare, err)
+		return zero, err
+	}
+	tag, err := localImageTag(commit)
+	if err != nil {
+		progress.failed(StagePrepare, err)
+		return zero, err
+	}
+	tag, err = s.imageTag(ctx, commit, tag)
+	if err != nil {
+		progress.failed(StagePrepare, err)
+		return zero, err
+	}
+
+	resolution := explainImageRepository(in.Name, resolveDockerRegistry(envValue(s.dockerRegistryValue)))
+	if repositoryOverride != "" {
+		resolution = resolution.withOverride(repositoryOverride)
+	}
+	s.logger.Info("image repository resolved", resolution.logFields())
+	image, err := buildImageName(resolution.Repository, tag)
+	if err != nil {
+		progress.failed(StagePrepare, err)
+		return zero, err
+	}
+	if err := checkRegistryAllowed(resolution.Repository, envValue(s.allowedRegistriesValue)); err != nil {
+		progress.failed(StagePrepare, err)
+		return zero, err
+	}
+
+	appDir, err := resolveAppDir(in.AppDir)
+	if err != nil {
+		progress.failed(StagePrepare, err)
+		return zero, err
+	}
+	progress.completed(StagePrepare)
+	s.logger.Info("prepare skipped; image tagged from local git commit", map[string]any{
+		"commit": commit,
+		"image":  image,
+	})
+
+	return preparedImage{
+		commit:     commit,
+		repository: resolution.Repository,
+		tag:        tag,
+		image:      image,
+		appDir:     appDir,
+	}, nil
+}
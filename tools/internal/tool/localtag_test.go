@@ -0,0 +1,109 @@
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestDeployApp_LocalTagSkipsPrepare(t *testing.T) {
+	cp := &stubControlPlane{}
+	dockerStub := &stubDockerClient{}
+	svc := &Service{
+		newControlPlane: func(string) (controlPlaneClient, error) {
+			t.Fatal("local tag mode must not create a control plane client")
+			return cp, nil
+		},
+		newDockerClient:     func(Logger) dockerClient { return dockerStub },
+		resolveGitCommit:    func(context.Context) (string, error) { return "0123456789abcdef0123456789abcdef01234567", nil },
+		localTagValue:       func() string { return "true" },
+		dockerRegistryValue: func() string { return "registry.internal:5000" },
+		logger:              &noopLogger{},
+	}
+
+	out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		Name:        "my-app",
+		Description: "internal app",
+		AppDir:      t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	const want = "registry.internal:5000/my-app:0123456"
+	if dockerStub.image != want || dockerStub.pushImage != want {
+		t.Fatalf("expected build and push of %q, got build %q push %q", want, dockerStub.image, dockerStub.pushImage)
+	}
+	if out.Image != want || out.Status != "pushed" || out.GitCommit != "0123456789abcdef0123456789abcdef01234567" {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	if len(cp.prepareReqs) != 0 || len(cp.deployReqs) != 0 {
+		t.Fatalf("expected no control plane calls, got prepare=%d deploy=%d", len(cp.prepareReqs), len(cp.deployReqs))
+	}
+}
+
+func TestLocalImageTag(t *testing.T) {
+	tests := []struct {
+		commit  string
+		want    string
+		wantErr bool
+	}{
+		{commit: "0123456789abcdef", want: "0123456"},
+		{commit: "abc12", want: "abc12"},
+		{commit: "-bad/ref", wantErr: true},
+		{commit: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := localImageTag(tt.commit)
+		if tt.wantErr {
+			if got := apperrors.CodeOf(err); got != apperrors.CodeConfig {
+				t.Fatalf("commit %q: expected code %q, got %q", tt.commit, apperrors.CodeConfig, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Fatalf("commit %q: expected tag %q, got %q (%v)", tt.commit, tt.want, got, err)
+		}
+	}
+}
+
+func TestDeployApp_LocalTagUsesConfiguredRepository(t *testing.T) {
+	cp := &stubControlPlane{}
+	dockerStub := &stubDockerClient{}
+	svc := &Service{
+		newControlPlane: func(string) (controlPlaneClient, error) {
+			t.Fatal("local tag mode must not create a control plane client")
+			return cp, nil
+		},
+		newDockerClient:       func(Logger) dockerClient { return dockerStub },
+		resolveGitCommit:      func(context.Context) (string, error) { return "0123456789abcdef0123456789abcdef01234567", nil },
+		localTagValue:         func() string { return "1" },
+		imageRepositoryValue:  func() string { return "mirror.airgap.internal/team/my-app" },
+		imageTagTemplateValue: func() string { return "release-{short}" },
+		dockerRegistryValue:   func() string { return "registry.internal:5000" },
+		logger:                &noopLogger{},
+	}
+
+	out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		Name:        "my-app",
+		Description: "internal app",
+		AppDir:      t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	const want = "mirror.airgap.internal/team/my-app:release-0123456"
+	if dockerStub.image != want || dockerStub.pushImage != want {
+		t.Fatalf("expected build and push of %q, got build %q push %q", want, dockerStub.image, dockerStub.pushImage)
+	}
+	if out.Image != want || out.Status != "pushed" {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	if len(cp.prepareReqs) != 0 || len(cp.deployReqs) != 0 {
+		t.Fatalf("expected no control plane calls, got prepare=%d deploy=%d", len(cp.prepareReqs), len(cp.deployReqs))
+	}
+}
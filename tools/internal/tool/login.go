@@ -0,0 +1,99 @@
+package tool
+
+import (
+	"context"
+	"strings"
+)
+
+// registryTokenUsernameEnv names the registry username paired with the
+// prepare push_token. Spec §3.1 (spec/API.md) defines only push_token and
+// expires_at, with no username, so the username is a deployment setting;
+// registries that authenticate by token alone accept any non-empty value.
+const (
+	registryTokenUsernameEnv = "SAKI_REGISTRY_TOKEN_USERNAME"
+
+	defaultPushTokenUsername = "saki"
+)
+
+// pushTokenUsername returns SAKI_REGISTRY_TOKEN_USERNAME, or "saki" when it
+// is unset.
+func (s *Service) pushTokenUsername() string {
+	if username := strings.TrimSpace(envValue(s.registryTokenUserValue)); username != "" {
+		return username
+	}
+	return defaultPushTokenUsername
+}
+
+// registryCredentials picks the credentials used for docker login. Static
+// SAKI_REGISTRY_USERNAME/SAKI_REGISTRY_PASSWORD take precedence over the
+// prepare push token; ok is false when neither is available.
+func (s *Service) registryCredentials(pushToken string) (username, password string, ok bool) {
+	staticUser := strings.TrimSpace(envValue(s.registryUserValue))
+	staticPass := envValue(s.registryPassValue)
+
+	if staticUser != "" && staticPass != "" {
+		return staticUser, staticPass, true
+	}
+	if staticUser != "" || staticPass != "" {
+		s.logger.Error("ignoring partial static registry credentials; both username and password are required", map[string]any{
+			"has_username": staticUser != "",
+			"has_password": staticPass != "",
+		})
+	}
+
+	if token := strings.TrimSpace(pushToken); token != "" {
+		return s.pushTokenUsername(), token, true
+	}
+	return "", "", false
+}
+
+// registryLogin runs docker login against the host of repository when
+// credentials are available. It is a no-op otherwise, and also when
+// SAKI_DOCKER_CONFIG_AUTH is set and docker already holds credentials for the
+// host, so user-managed credentials are not overwritten.
+func (s *Service) registryLogin(ctx context.Context, dockerClient dockerClient, repository, pushToken string, progress ProgressFunc) error {
+	username, password, ok := s.registryCredentials(pushToken)
+	if !ok {
+		return nil
+	}
+
+	registry := registryHost(repository)
+	if registry == "" {
+		return nil
+	}
+
+	if s.dockerConfigAuthEnabled() && s.hasExistingCredentials(registry) {
+		s.logger.Info("docker login skipped; docker config has credentials for registry", map[string]any{
+			"registry": registry,
+		})
+		return nil
+	}
+
+	progress.started(StageLogin)
+	if err := dockerClient.Login(ctx, registry, username, password); err != nil {
+		s.logger.Error("docker login failed", map[string]any{
+			"registry": registry,
+			"username": username,
+			"error":    err.Error(),
+		})
+		progress.failed(StageLogin, err)
+		return err
+	}
+	progress.completed(StageLogin)
+	return nil
+}
+
+// registryHost returns the host[:port] prefix of an image repository, or ""
+// when the repository has no explicit registry host.
+func registryHost(repository string) string {
+	slash := strings.IndexByte(repository, '/')
+	if slash < 0 {
+		return ""
+	}
+
+	first := repository[:slash]
+	if first == "localhost" || strings.ContainsAny(first, ".:") {
+		return first
+	}
+	return ""
+}
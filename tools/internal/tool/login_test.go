@@ -0,0 +1,135 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/docker"
+)
+
+func TestDeployApp_RegistryLoginPrecedence(t *testing.T) {
+	tests := []struct {
+		name         string
+		staticUser   string
+		staticPass   string
+		tokenUser    string
+		pushToken    string
+		wantLogin    bool
+		wantUser     string
+		wantPassword string
+	}{
+		{
+			name:         "static credentials win over push token",
+			staticUser:   "robot",
+			staticPass:   "static-secret",
+			pushToken:    "push-token-secret",
+			wantLogin:    true,
+			wantUser:     "robot",
+			wantPassword: "static-secret",
+		},
+		{
+			name:         "push token when no static credentials",
+			pushToken:    "push-token-secret",
+			wantLogin:    true,
+			wantUser:     defaultPushTokenUsername,
+			wantPassword: "push-token-secret",
+		},
+		{
+			name:         "partial static credentials fall back to push token",
+			staticUser:   "robot",
+			pushToken:    "push-token-secret",
+			wantLogin:    true,
+			wantUser:     defaultPushTokenUsername,
+			wantPassword: "push-token-secret",
+		},
+		{
+			name:         "push token with a configured username",
+			tokenUser:    "registry-bot",
+			pushToken:    "push-token-secret",
+			wantLogin:    true,
+			wantUser:     "registry-bot",
+			wantPassword: "push-token-secret",
+		},
+		{
+			name:      "no login without credentials",
+			wantLogin: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &stubControlPlane{
+				prepareRes: controlplane.PrepareAppResponse{
+					Repository:  "registry.internal/owner/my-app",
+					RequiredTag: "abc1234",
+					PushToken:   tt.pushToken,
+				},
+			}
+			runner := &recordingRunner{}
+			logger := &captureLogger{}
+
+			svc := &Service{
+				newControlPlane:        func(string) (controlPlaneClient, error) { return cp, nil },
+				newDockerClient:        func(l Logger) dockerClient { return docker.NewAdapter(l, runner) },
+				resolveGitCommit:       func(context.Context) (string, error) { return "abc", nil },
+				registryUserValue:      func() string { return tt.staticUser },
+				registryPassValue:      func() string { return tt.staticPass },
+				registryTokenUserValue: func() string { return tt.tokenUser },
+				logger:                 logger,
+			}
+
+			if _, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				Name:                "my-app",
+				Description:         "internal app",
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				AppDir:              t.TempDir(),
+			}); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			login, ok := runner.find("login")
+			if ok != tt.wantLogin {
+				t.Fatalf("expected login=%v, got %v (%v)", tt.wantLogin, ok, runner.requests)
+			}
+			if !tt.wantLogin {
+				return
+			}
+
+			wantArgs := "login registry.corgi-teeth.ts.net --username " + tt.wantUser + " --password-stdin"
+			if got := strings.Join(login.Args, " "); got != wantArgs {
+				t.Fatalf("unexpected login args: got %q want %q", got, wantArgs)
+			}
+			if login.Stdin != tt.wantPassword+"\n" {
+				t.Fatalf("expected password on stdin, got %q", login.Stdin)
+			}
+
+			for _, entry := range logger.entries {
+				if strings.Contains(fmt.Sprint(entry.fields), tt.wantPassword) {
+					t.Fatalf("log entry %q leaked the registry password: %v", entry.message, entry.fields)
+				}
+			}
+		})
+	}
+}
+
+type recordingRunner struct {
+	requests []docker.CommandRequest
+}
+
+func (r *recordingRunner) Run(_ context.Context, req docker.CommandRequest) (docker.CommandResult, error) {
+	r.requests = append(r.requests, req)
+	return docker.CommandResult{}, nil
+}
+
+func (r *recordingRunner) find(subcommand string) (docker.CommandRequest, bool) {
+	for _, req := range r.requests {
+		if len(req.Args) > 0 && req.Args[0] == subcommand {
+			return req, true
+		}
+	}
+	return docker.CommandRequest{}, false
+}
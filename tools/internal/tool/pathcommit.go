@@ -0,0 +1,61 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const (
+	pathCommitEnv = "SAKI_PATH_COMMIT"
+	gitCommitEnv  = "SAKI_GIT_COMMIT"
+)
+
+// gitCommit resolves the commit an image is built from. An explicit commit
+// (the deploy input, then SAKI_GIT_COMMIT) is used as is without running
+// git. With SAKI_PATH_COMMIT enabled it is the last commit touching the app
+// directory, so an app living in a monorepo subdirectory only gets a new
+// required tag when the app itself changes. It falls back to HEAD when git
+// finds no commit for the path.
+func (s *Service) gitCommit(ctx context.Context, in contracts.DeployAppInput) (string, error) {
+	commit, err := resolveGitCommitOverride(in.GitCommit, envValue(s.gitCommitValue))
+	if err != nil || commit != "" {
+		return commit, err
+	}
+
+	appDir := strings.TrimSpace(in.AppDir)
+	if envEnabled(envValue(s.pathCommitValue)) && appDir != "" && s.runGit != nil {
+		commit, err := s.runGitHistory(ctx, "log", "-1", "--format=%H", "--", appDir)
+		commit = strings.TrimSpace(commit)
+		if err == nil && commit != "" {
+			return commit, nil
+		}
+		fields := map[string]any{"app_dir": appDir}
+		if err != nil {
+			fields["error"] = err.Error()
+		}
+		s.logger.Info("no commit found for app_dir; using HEAD", fields)
+	}
+	return s.resolveGitCommit(ctx)
+}
+
+// resolveGitCommitOverride returns the commit that replaces git resolution,
+// preferring the deploy input over SAKI_GIT_COMMIT, lowercased. The input is
+// checked by DeployAppInput.Validate; an invalid env value fails with
+// CodeConfig.
+func resolveGitCommitOverride(inputCommit, envCommit string) (string, error) {
+	if commit := strings.TrimSpace(inputCommit); commit != "" {
+		return strings.ToLower(commit), nil
+	}
+	commit := strings.TrimSpace(envCommit)
+	if commit == "" {
+		return "", nil
+	}
+	if err := contracts.ValidateGitCommit(commit); err != nil {
+		return "", apperrors.Wrap(apperrors.CodeConfig, "resolve git commit", fmt.Errorf("invalid %s: %w", gitCommitEnv, err))
+	}
+	return strings.ToLower(commit), nil
+}
@@ -0,0 +1,142 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/tool/tooltest"
+)
+
+func TestDeployApp_PathCommitScopesCommitToAppDir(t *testing.T) {
+	const pathCommit = "fedcba9876543210fedcba9876543210fedcba98"
+
+	tests := []struct {
+		name       string
+		env        string
+		gitOut     string
+		gitErr     error
+		wantCommit string
+		wantGit    bool
+	}{
+		{name: "last commit touching app_dir", env: "1", gitOut: pathCommit + "\n", wantCommit: pathCommit, wantGit: true},
+		{name: "falls back to HEAD without path history", env: "1", gitOut: "", wantCommit: defaultTestGitCommit, wantGit: true},
+		{name: "falls back to HEAD on git failure", env: "1", gitErr: errors.New("exit status 128"), wantCommit: defaultTestGitCommit, wantGit: true},
+		{name: "disabled uses HEAD", wantCommit: defaultTestGitCommit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appDir := t.TempDir()
+			cp := &tooltest.ControlPlane{}
+			svc := NewTestService(TestDeps{
+				ControlPlane: cp,
+				Docker:       &tooltest.Docker{},
+				Env:          map[string]string{pathCommitEnv: tt.env},
+			})
+			var gitLog []string
+			svc.runGit = func(_ context.Context, args ...string) (string, error) {
+				if len(args) > 0 && args[0] == "log" {
+					gitLog = args
+					return tt.gitOut, tt.gitErr
+				}
+				return "", errors.New("exit status 1")
+			}
+
+			out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				Name:                "my-app",
+				Description:         "internal app",
+				AppDir:              appDir,
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if tt.wantGit {
+				if got, want := strings.Join(gitLog, " "), "log -1 --format=%H -- "+appDir; got != want {
+					t.Fatalf("unexpected git command: got %q want %q", got, want)
+				}
+			} else if gitLog != nil {
+				t.Fatalf("expected no path-scoped git log, got %q", gitLog)
+			}
+			if out.GitCommit != tt.wantCommit || cp.Prepared[0].GitCommit != tt.wantCommit {
+				t.Fatalf("expected commit %q, got output %q prepare %q", tt.wantCommit, out.GitCommit, cp.Prepared[0].GitCommit)
+			}
+		})
+	}
+}
+
+func TestDeployApp_GitCommitOverrideSkipsGit(t *testing.T) {
+	const override = "ABCDEF0123456789abcdef0123456789abcdef01"
+
+	tests := []struct {
+		name  string
+		env   string
+		input string
+	}{
+		{name: "env", env: override},
+		{name: "input", input: override},
+		{name: "input wins over env", env: "1111111", input: override},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &tooltest.ControlPlane{}
+			svc := NewTestService(TestDeps{
+				ControlPlane: cp,
+				Docker:       &tooltest.Docker{},
+				Env:          map[string]string{gitCommitEnv: tt.env, pathCommitEnv: "1"},
+			})
+			svc.resolveGitCommit = func(context.Context) (string, error) {
+				t.Fatal("expected git rev-parse not to run")
+				return "", nil
+			}
+			svc.runGit = func(_ context.Context, args ...string) (string, error) {
+				if joined := strings.Join(args, " "); joined == "rev-parse HEAD" || strings.HasPrefix(joined, "log ") {
+					t.Fatalf("expected no commit lookup, got git %q", args)
+				}
+				return "", errors.New("not a git repository")
+			}
+
+			out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				Name:                "my-app",
+				Description:         "internal app",
+				AppDir:              t.TempDir(),
+				GitCommit:           tt.input,
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			want := strings.ToLower(override)
+			if out.GitCommit != want || cp.Prepared[0].GitCommit != want {
+				t.Fatalf("expected commit %q, got output %q prepare %q", want, out.GitCommit, cp.Prepared[0].GitCommit)
+			}
+		})
+	}
+}
+
+func TestDeployApp_RejectsInvalidGitCommitOverride(t *testing.T) {
+	in := contracts.DeployAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		Name:                "my-app",
+		Description:         "internal app",
+		AppDir:              t.TempDir(),
+	}
+
+	svc := NewTestService(TestDeps{Env: map[string]string{gitCommitEnv: "HEAD"}})
+	_, err := svc.DeployApp(context.Background(), in)
+	if apperrors.CodeOf(err) != apperrors.CodeConfig || !strings.Contains(err.Error(), gitCommitEnv) {
+		t.Fatalf("expected a config error naming %s, got %v", gitCommitEnv, err)
+	}
+
+	in.GitCommit = "abc"
+	_, err = NewTestService(TestDeps{}).DeployApp(context.Background(), in)
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidInput {
+		t.Fatalf("expected an invalid input error, got %v", err)
+	}
+}
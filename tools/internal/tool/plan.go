@@ -0,0 +1,149 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/docker"
+)
+
+const statusPlanned = "planned"
+
+// Dry-run plan actions.
+const (
+	planActionCreate    = "create"
+	planActionUpdate    = "update"
+	planActionUnchanged = "unchanged"
+	planActionBlocked   = "blocked"
+)
+
+// planDeploy reports what deployApp would do for prepared without building,
+// pushing, or deploying. Beyond prepare, it only looks up the app's current
+// state via GET /apps/{app_id} (see currentApp).
+func (s *Service) planDeploy(ctx context.Context, in contracts.DeployAppInput, prepared preparedImage) (contracts.DeployAppOutput, error) {
+	controlPlaneURL, err := s.controlPlaneURL(in.SakiControlPlaneURL)
+	if err != nil {
+		return contracts.DeployAppOutput{}, err
+	}
+	plan := &contracts.DeployPlan{
+		Action:           planActionCreate,
+		Image:            prepared.image,
+		Registry:         imageRegistryHost(prepared.repository),
+		ControlPlaneHost: controlPlaneHost(controlPlaneURL),
+		GitCommit:        prepared.commit,
+	}
+
+	current, err := currentApp(ctx, prepared.controlPlane, prepared.prepare.ExistingAppID, in.Name)
+	var apiErr *controlplane.APIError
+	switch {
+	case err == nil:
+		plan.CurrentImage = strings.TrimSpace(current.Image)
+		plan.Action = planActionUpdate
+		if plan.CurrentImage == prepared.image {
+			plan.Action = planActionUnchanged
+		}
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+	case errors.As(err, &apiErr):
+		plan.Action = planActionBlocked
+		plan.Conflict = apiErr.Error()
+	default:
+		return contracts.DeployAppOutput{}, err
+	}
+
+	if plan.Action != planActionBlocked {
+		plan.Steps, plan.SkippedSteps = s.planSteps(in, prepared)
+	}
+	if plan.Steps == nil {
+		plan.Steps = []string{}
+	}
+	if plan.SkippedSteps == nil {
+		plan.SkippedSteps = []string{}
+	}
+
+	s.logger.Info("deploy planned", map[string]any{
+		"name":   in.Name,
+		"image":  prepared.image,
+		"action": plan.Action,
+	})
+
+	return contracts.DeployAppOutput{
+		Image:          prepared.image,
+		Status:         statusPlanned,
+		GitCommit:      prepared.commit,
+		TokenExpiresAt: prepared.prepare.ExpiresAt,
+		Plan:           plan,
+	}, nil
+}
+
+// planSteps lists the side effects deployApp would perform, mirroring its
+// environment-driven branches, and the optional steps it would leave out.
+func (s *Service) planSteps(in contracts.DeployAppInput, prepared preparedImage) (steps, skipped []string) {
+	registry := registryHost(prepared.repository)
+	_, _, hasCredentials := s.registryCredentials(prepared.prepare.PushToken)
+	switch {
+	case registry == "":
+		skipped = append(skipped, "docker login (image repository has no registry host)")
+	case !hasCredentials:
+		skipped = append(skipped, "docker login (no registry credentials or push token)")
+	case s.dockerConfigAuthEnabled() && s.hasExistingCredentials(registry):
+		skipped = append(skipped, "docker login "+registry+" (docker config has credentials)")
+	default:
+		steps = append(steps, "docker login "+registry)
+	}
+
+	buildOpts := docker.BuildOptions{Platforms: in.Platforms}
+	if buildOpts.PushesOnBuild() {
+		steps = append(steps, fmt.Sprintf("docker buildx build --platform %s -t %s --push (in %s)", strings.Join(in.Platforms, ","), prepared.image, prepared.appDir))
+	} else {
+		steps = append(steps,
+			fmt.Sprintf("docker build -t %s (in %s)", prepared.image, prepared.appDir),
+			"docker push "+prepared.image,
+		)
+	}
+
+	if envEnabled(envValue(s.registryOnlyValue)) {
+		skipped = append(skipped, "POST /apps ("+registryOnlyEnv+" is set)")
+	} else {
+		if appID := s.updateAppID(prepared.prepare); appID != "" {
+			steps = append(steps, fmt.Sprintf("PATCH /apps/%s (name=%s, image=%s)", appID, in.Name, prepared.image))
+		} else {
+			steps = append(steps, fmt.Sprintf("POST /apps (name=%s, image=%s)", in.Name, prepared.image))
+		}
+		if envEnabled(envValue(s.smokeCheckValue)) {
+			steps = append(steps, "smoke check the app URL")
+		} else {
+			skipped = append(skipped, "smoke check ("+smokeCheckEnv+" is not set)")
+		}
+	}
+
+	if webhook := strings.TrimSpace(envValue(s.deployWebhookValue)); webhook != "" {
+		steps = append(steps, "POST "+redactWebhookURL(webhook))
+	} else {
+		skipped = append(skipped, "deploy webhook ("+deployWebhookEnv+" is not set)")
+	}
+	return steps, skipped
+}
+
+// imageRegistryHost returns the registry host repository is pushed to;
+// repositories without an explicit host go to Docker Hub.
+func imageRegistryHost(repository string) string {
+	if host := registryHost(repository); host != "" {
+		return host
+	}
+	return dockerHubHost
+}
+
+// controlPlaneHost reduces a tokenized control plane URL to its host.
+func controlPlaneHost(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,231 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/tool/tooltest"
+)
+
+func TestDeployApp_DryRunOnlyPreparesAndChecksAvailability(t *testing.T) {
+	tests := []struct {
+		name          string
+		existingAppID string
+		apps          []controlplane.App
+		appStatusRes  controlplane.AppStatusResponse
+		appStatusErr  error
+		wantLookups   []string
+		wantAction    string
+		wantConflict  bool
+	}{
+		{
+			name:       "new app is created",
+			wantAction: "create",
+		},
+		{
+			name:         "existing app is updated",
+			apps:         []controlplane.App{{AppID: "app_1", Name: "my-app"}},
+			appStatusRes: controlplane.AppStatusResponse{Image: "registry.internal/owner/my-app:old"},
+			wantLookups:  []string{"app_1"},
+			wantAction:   "update",
+		},
+		{
+			name:          "name owned by someone else is blocked",
+			existingAppID: "app_1",
+			appStatusErr:  &controlplane.APIError{StatusCode: http.StatusForbidden, RemoteCode: "forbidden", Message: "not your app"},
+			wantLookups:   []string{"app_1"},
+			wantAction:    "blocked",
+			wantConflict:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &stubControlPlane{
+				prepareRes:   controlplane.PrepareAppResponse{Repository: "registry.internal/owner/my-app", RequiredTag: "abc1234", ExistingAppID: tt.existingAppID},
+				apps:         tt.apps,
+				appStatusRes: tt.appStatusRes,
+				appStatusErr: tt.appStatusErr,
+			}
+			builder := &stubDockerClient{}
+
+			svc := &Service{
+				logger:              &noopLogger{},
+				newControlPlane:     func(string) (controlPlaneClient, error) { return cp, nil },
+				newDockerClient:     func(Logger) dockerClient { return builder },
+				resolveGitCommit:    func(context.Context) (string, error) { return "abc", nil },
+				dockerRegistryValue: func() string { return "registry.internal" },
+			}
+
+			out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				Name:                "my-app",
+				Description:         "internal app",
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				AppDir:              t.TempDir(),
+				DryRun:              true,
+			})
+			if err != nil {
+				t.Fatalf("DeployApp returned error: %v", err)
+			}
+
+			if len(cp.prepareReqs) != 1 || !reflect.DeepEqual(cp.appStatusReqs, tt.wantLookups) {
+				t.Fatalf("expected one prepare and app lookups %v, got %d and %v", tt.wantLookups, len(cp.prepareReqs), cp.appStatusReqs)
+			}
+			if len(cp.deployReqs) != 0 || builder.image != "" || builder.pushImage != "" {
+				t.Fatalf("dry run must not build, push, or deploy (deploys=%d build=%q push=%q)", len(cp.deployReqs), builder.image, builder.pushImage)
+			}
+
+			if out.Status != "planned" || out.Image != "registry.internal/owner/my-app:abc1234" {
+				t.Fatalf("unexpected output: %+v", out)
+			}
+			if out.Plan == nil || out.Plan.Action != tt.wantAction {
+				t.Fatalf("expected plan action %q, got %+v", tt.wantAction, out.Plan)
+			}
+			if got := out.Plan.Conflict != ""; got != tt.wantConflict {
+				t.Fatalf("expected conflict=%v, got %q", tt.wantConflict, out.Plan.Conflict)
+			}
+		})
+	}
+}
+
+func TestDeployApp_DryRunPlanFields(t *testing.T) {
+	const image = "registry.internal/owner/my-app:0123456"
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantSteps   []string
+		wantSkipped []string
+	}{
+		{
+			name: "full deploy",
+			env: map[string]string{
+				dockerRegistryEnv: "registry.internal",
+				smokeCheckEnv:     "1",
+			},
+			wantSteps: []string{
+				"docker login registry.internal",
+				"docker build -t " + image + " (in APPDIR)",
+				"docker push " + image,
+				"POST /apps (name=my-app, image=" + image + ")",
+				"smoke check the app URL",
+			},
+			wantSkipped: []string{
+				"deploy webhook (SAKI_DEPLOY_WEBHOOK is not set)",
+			},
+		},
+		{
+			name: "registry only",
+			env: map[string]string{
+				dockerRegistryEnv: "registry.internal",
+				registryOnlyEnv:   "1",
+				smokeCheckEnv:     "1",
+			},
+			wantSteps: []string{
+				"docker login registry.internal",
+				"docker build -t " + image + " (in APPDIR)",
+				"docker push " + image,
+			},
+			wantSkipped: []string{
+				"POST /apps (SAKI_REGISTRY_ONLY is set)",
+				"deploy webhook (SAKI_DEPLOY_WEBHOOK is not set)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appDir := t.TempDir()
+			svc := NewTestService(TestDeps{
+				ControlPlane: &tooltest.ControlPlane{},
+				Docker:       &tooltest.Docker{},
+				Env:          tt.env,
+			})
+
+			out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				Name:                "my-app",
+				Description:         "internal app",
+				SakiControlPlaneURL: "https://cp.internal:8443/base?token=test-token",
+				AppDir:              appDir,
+				DryRun:              true,
+			})
+			if err != nil {
+				t.Fatalf("DeployApp returned error: %v", err)
+			}
+
+			for i, step := range tt.wantSteps {
+				tt.wantSteps[i] = strings.Replace(step, "APPDIR", appDir, 1)
+			}
+			want := &contracts.DeployPlan{
+				Action:           "create",
+				Image:            image,
+				Registry:         "registry.internal",
+				ControlPlaneHost: "cp.internal:8443",
+				GitCommit:        defaultTestGitCommit,
+				Steps:            tt.wantSteps,
+				SkippedSteps:     tt.wantSkipped,
+			}
+			if !reflect.DeepEqual(out.Plan, want) {
+				t.Fatalf("unexpected plan:\ngot  %+v\nwant %+v", out.Plan, want)
+			}
+			if strings.Contains(fmt.Sprintf("%+v", out.Plan), "test-token") {
+				t.Fatalf("plan leaked the control plane token: %+v", out.Plan)
+			}
+		})
+	}
+}
+
+func TestCurrentApp_LooksUpAppByID(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /apps", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"apps":[{"app_id":"app_other","name":"other-app"},{"app_id":"app_123","name":"my-app"}]}`)
+	})
+	mux.HandleFunc("GET /apps/{app_id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("app_id") != "app_123" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"code":"not_found","message":"app not found"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"app_id":"app_123","deployment_id":"dep_1","name":"my-app","url":"https://my-app.saki.internal","status":"healthy","image":"registry.internal/owner/my-app:abc1234"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	svc := &Service{logger: &noopLogger{}}
+	cp, err := svc.newControlPlaneClient(server.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("newControlPlaneClient returned error: %v", err)
+	}
+
+	for _, existingAppID := range []string{"", "app_123"} {
+		app, err := currentApp(context.Background(), cp, existingAppID, "my-app")
+		if err != nil {
+			t.Fatalf("currentApp(%q) returned error: %v", existingAppID, err)
+		}
+		want := controlplane.App{
+			AppID:        "app_123",
+			Name:         "my-app",
+			Image:        "registry.internal/owner/my-app:abc1234",
+			URL:          "https://my-app.saki.internal",
+			Status:       "healthy",
+			DeploymentID: "dep_1",
+		}
+		if app != want {
+			t.Fatalf("currentApp(%q) = %+v, want %+v", existingAppID, app, want)
+		}
+	}
+
+	_, err = currentApp(context.Background(), cp, "", "new-app")
+	var apiErr *controlplane.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected an unlisted app to be a 404, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// prepareRetryPolicy bounds the cold-start retry around POST /apps/prepare.
+// Prepare is safe to repeat, so timed-out and 5xx/429 attempts are retried;
+// deploy is not.
+type prepareRetryPolicy struct {
+	attempts     int
+	firstTimeout time.Duration
+	timeout      time.Duration
+	delay        time.Duration
+}
+
+// defaultPrepareRetry gives the first attempt room for a control-plane cold
+// start, then falls back to the regular request timeout. The delay between
+// attempts is jittered by the service.
+var defaultPrepareRetry = prepareRetryPolicy{
+	attempts:     3,
+	firstTimeout: 45 * time.Second,
+	timeout:      15 * time.Second,
+	delay:        time.Second,
+}
+
+// prepareApp calls PrepareApp, retrying attempts that time out or that the
+// control plane answers with 5xx or 429 while ctx is still live. Other
+// failures are returned immediately. This loop is the only retry: the
+// client's own retries are disabled for prepare.
+func (s *Service) prepareApp(ctx context.Context, cp controlPlaneClient, req controlplane.PrepareAppRequest) (controlplane.PrepareAppResponse, error) {
+	policy := defaultPrepareRetry
+	if s.prepareRetry != nil {
+		policy = *s.prepareRetry
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= max(policy.attempts, 1); attempt++ {
+		timeout := policy.timeout
+		if attempt == 1 {
+			timeout = policy.firstTimeout
+		}
+
+		res, err := prepareAttempt(ctx, cp, req, timeout)
+		if err == nil {
+			return res, nil
+		}
+		lastErr = err
+
+		retryable := apperrors.CodeOf(err) == apperrors.CodeTimeout || controlplane.Retryable(err)
+		if !retryable || ctx.Err() != nil || attempt >= policy.attempts {
+			break
+		}
+		if !s.takeRetry(ctx, StagePrepare) {
+			break
+		}
+		s.logger.Info("prepare failed; retrying", map[string]any{
+			"attempt": attempt,
+			"timeout": timeout.String(),
+			"error":   err.Error(),
+		})
+		if !sleepContext(ctx, prepareRetryDelay(err, s.retryDelay(policy.delay))) {
+			break
+		}
+	}
+
+	return controlplane.PrepareAppResponse{}, lastErr
+}
+
+// prepareRetryDelay is the wait before the next prepare attempt: delay, or
+// longer when a 429 asked for it with Retry-After.
+func prepareRetryDelay(err error, delay time.Duration) time.Duration {
+	var apiErr *controlplane.APIError
+	if errors.As(err, &apiErr) {
+		return max(delay, apiErr.RetryAfter)
+	}
+	return delay
+}
+
+// prepareAttempt bounds one prepare call by timeout, which also replaces the
+// client's shorter per-request timeout. Client retries are disabled, since
+// prepareApp already retries.
+func prepareAttempt(ctx context.Context, cp controlPlaneClient, req controlplane.PrepareAppRequest, timeout time.Duration) (controlplane.PrepareAppResponse, error) {
+	ctx = controlplane.ContextWithRetryGate(ctx, nil)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(controlplane.ContextWithRequestTimeout(ctx, timeout), timeout)
+		defer cancel()
+	}
+	return cp.PrepareApp(ctx, req)
+}
@@ -0,0 +1,96 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+)
+
+func TestDeployApp_RetriesPrepareAfterColdStartTimeout(t *testing.T) {
+	cp := &coldStartControlPlane{
+		stubControlPlane: stubControlPlane{
+			prepareRes: controlplane.PrepareAppResponse{Repository: "registry.internal/owner/my-app", RequiredTag: "abc1234"},
+			deployRes:  controlplane.DeployAppResponse{AppID: "app_123", Status: "deploying"},
+		},
+		timeouts: 1,
+	}
+	logger := &captureLogger{}
+	svc := &Service{
+		newControlPlane:  func(string) (controlPlaneClient, error) { return cp, nil },
+		newDockerClient:  func(Logger) dockerClient { return &stubDockerClient{} },
+		resolveGitCommit: func(context.Context) (string, error) { return "abc", nil },
+		logger:           logger,
+		prepareRetry: &prepareRetryPolicy{
+			attempts:     3,
+			firstTimeout: time.Minute,
+			timeout:      time.Second,
+		},
+	}
+
+	out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		Name:                "my-app",
+		Description:         "internal app",
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppDir:              t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("expected deploy to succeed after prepare retry, got %v", err)
+	}
+	if out.AppID != "app_123" {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+
+	if len(cp.prepareReqs) != 2 {
+		t.Fatalf("expected two prepare attempts, got %d", len(cp.prepareReqs))
+	}
+	if cp.budgets[0] <= 30*time.Second || cp.budgets[1] > time.Second {
+		t.Fatalf("expected a longer first attempt, got budgets %v", cp.budgets)
+	}
+	if len(cp.deployReqs) != 1 {
+		t.Fatalf("expected a single deploy call, got %d", len(cp.deployReqs))
+	}
+	if _, ok := logger.find("prepare failed; retrying"); !ok {
+		t.Fatal("expected retry to be logged")
+	}
+}
+
+func TestPrepareApp_DoesNotRetryNonTimeoutErrors(t *testing.T) {
+	apiErr := &controlplane.APIError{StatusCode: 400, Message: "invalid name"}
+	cp := &coldStartControlPlane{stubControlPlane: stubControlPlane{prepareErr: apiErr}}
+	svc := &Service{logger: &noopLogger{}, prepareRetry: &prepareRetryPolicy{attempts: 3}}
+
+	_, err := svc.prepareApp(context.Background(), cp, controlplane.PrepareAppRequest{Name: "my-app"})
+	if !errors.Is(err, apiErr) {
+		t.Fatalf("expected API error, got %v", err)
+	}
+	if len(cp.prepareReqs) != 1 {
+		t.Fatalf("expected a single prepare attempt, got %d", len(cp.prepareReqs))
+	}
+}
+
+// coldStartControlPlane times out the first prepare calls and records the
+// deadline budget each attempt was given.
+type coldStartControlPlane struct {
+	stubControlPlane
+	timeouts int
+	budgets  []time.Duration
+}
+
+func (c *coldStartControlPlane) PrepareApp(ctx context.Context, req controlplane.PrepareAppRequest) (controlplane.PrepareAppResponse, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		c.budgets = append(c.budgets, time.Until(deadline))
+	}
+	if len(c.prepareReqs) < c.timeouts {
+		c.prepareReqs = append(c.prepareReqs, req)
+		return controlplane.PrepareAppResponse{}, &controlplane.RequestError{
+			Err:       context.DeadlineExceeded,
+			Timeout:   true,
+			Operation: "prepare app",
+		}
+	}
+	return c.stubControlPlane.PrepareApp(ctx, req)
+}
@@ -0,0 +1,78 @@
+package tool
+
+import "github.com/1800agents/saki/tools/docker"
+
+// Deploy stages reported through ProgressFunc.
+const (
+	StagePrepare    = "prepare"
+	StageLogin      = "login"
+	StageBuild      = "build"
+	StagePush       = "push"
+	StageDeploy     = "deploy"
+	StageSmokeCheck = "smoke_check"
+)
+
+// Stage transition states reported through ProgressFunc.
+const (
+	ProgressStarted   = "started"
+	ProgressCompleted = "completed"
+	ProgressFailed    = "failed"
+	// ProgressSkipped reports a stage a resumed deploy did not rerun
+	// because its deploy state records it as completed.
+	ProgressSkipped = "skipped"
+	// ProgressUpdated reports intermediate progress within a stage, currently
+	// the push percentage.
+	ProgressUpdated = "progress"
+)
+
+// ProgressEvent describes a single deploy stage transition.
+type ProgressEvent struct {
+	Stage  string `json:"stage"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Percent is set on ProgressUpdated events.
+	Percent int `json:"percent,omitempty"`
+	// Digest is the pushed image digest, set on the final push update.
+	Digest string `json:"digest,omitempty"`
+}
+
+// ProgressFunc receives deploy stage transitions. It is called synchronously
+// from the deploy flow and must not block for long.
+type ProgressFunc func(ProgressEvent)
+
+func (p ProgressFunc) started(stage string) {
+	p.emit(ProgressEvent{Stage: stage, Status: ProgressStarted})
+}
+
+func (p ProgressFunc) completed(stage string) {
+	p.emit(ProgressEvent{Stage: stage, Status: ProgressCompleted})
+}
+
+func (p ProgressFunc) skipped(stage string) {
+	p.emit(ProgressEvent{Stage: stage, Status: ProgressSkipped})
+}
+
+func (p ProgressFunc) failed(stage string, err error) {
+	event := ProgressEvent{Stage: stage, Status: ProgressFailed}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	p.emit(event)
+}
+
+// pushProgressFunc forwards docker push progress as ProgressUpdated events.
+// It returns nil when p is nil, so the push output is not streamed.
+func (p ProgressFunc) pushProgressFunc() func(docker.PushProgress) {
+	if p == nil {
+		return nil
+	}
+	return func(update docker.PushProgress) {
+		p.emit(ProgressEvent{Stage: StagePush, Status: ProgressUpdated, Percent: update.Percent, Digest: update.Digest})
+	}
+}
+
+func (p ProgressFunc) emit(event ProgressEvent) {
+	if p != nil {
+		p(event)
+	}
+}
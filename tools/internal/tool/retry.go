@@ -0,0 +1,74 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// retryBudget caps the number of retries across every stage of one deploy
+// (prepare retries, control plane client retries, smoke check re-polls), so
+// per-stage retry policies cannot add up to an unbounded total.
+type retryBudget struct {
+	mu        sync.Mutex
+	remaining int
+}
+
+type retryBudgetKey struct{}
+
+// withRetryBudget attaches a budget of n retries to ctx and charges the
+// control plane client's own retries to it. A negative n leaves retries
+// unbounded.
+func (s *Service) withRetryBudget(ctx context.Context, n int) context.Context {
+	if n < 0 {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, retryBudgetKey{}, &retryBudget{remaining: n})
+	return controlplane.ContextWithRetryGate(ctx, func() bool {
+		return s.takeRetry(ctx, "control_plane")
+	})
+}
+
+// takeRetry spends one retry from the budget on ctx before stage retries. It
+// reports false, logging why, once the budget is exhausted; the caller must
+// then return its last failure instead of retrying.
+func (s *Service) takeRetry(ctx context.Context, stage string) bool {
+	budget, ok := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	if !ok {
+		return true
+	}
+
+	budget.mu.Lock()
+	defer budget.mu.Unlock()
+	if budget.remaining <= 0 {
+		s.logger.Info("retry budget exhausted; not retrying", map[string]any{
+			"stage": stage,
+		})
+		return false
+	}
+	budget.remaining--
+	return true
+}
+
+// resolveRetryBudget parses SAKI_RETRY_BUDGET as a non-negative retry count.
+// Unset means unbounded (-1).
+func resolveRetryBudget(envBudget string) (int, error) {
+	value := strings.TrimSpace(envBudget)
+	if value == "" {
+		return -1, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, apperrors.Wrap(apperrors.CodeConfig, "resolve retry budget", fmt.Errorf("parse %s: %w", retryBudgetEnv, err))
+	}
+	if n < 0 {
+		return 0, apperrors.New(apperrors.CodeConfig, "resolve retry budget", retryBudgetEnv+" must not be negative")
+	}
+	return n, nil
+}
@@ -0,0 +1,114 @@
+package tool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestDeployApp_RetryBudgetIsSharedAcrossStages(t *testing.T) {
+	var probes atomic.Int32
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		probes.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer app.Close()
+
+	cp := &coldStartControlPlane{
+		stubControlPlane: stubControlPlane{
+			prepareRes: controlplane.PrepareAppResponse{Repository: "registry.internal/owner/my-app", RequiredTag: "abc1234"},
+			deployRes:  controlplane.DeployAppResponse{AppID: "app_123", URL: app.URL, Status: "deploying"},
+		},
+		timeouts: 1,
+	}
+	logger := &captureLogger{}
+	svc := &Service{
+		newControlPlane:        func(string) (controlPlaneClient, error) { return cp, nil },
+		newDockerClient:        func(Logger) dockerClient { return &stubDockerClient{} },
+		resolveGitCommit:       func(context.Context) (string, error) { return "abc", nil },
+		smokeCheckValue:        func() string { return "true" },
+		smokeCheckTimeoutValue: func() string { return "250ms" },
+		retryBudgetValue:       func() string { return "1" },
+		prepareRetry:           &prepareRetryPolicy{attempts: 3, firstTimeout: time.Second, timeout: time.Second},
+		logger:                 logger,
+	}
+
+	out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		Name:                "my-app",
+		Description:         "internal app",
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppDir:              t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("DeployApp returned error: %v", err)
+	}
+
+	if len(cp.prepareReqs) != 2 {
+		t.Fatalf("expected the prepare retry to use the budget, got %d prepare attempts", len(cp.prepareReqs))
+	}
+	if got := probes.Load(); got != 1 {
+		t.Fatalf("expected no smoke check re-polls once the budget was spent, got %d probes", got)
+	}
+	if out.Status != statusUnhealthy {
+		t.Fatalf("expected unhealthy status, got %q", out.Status)
+	}
+	fields, ok := logger.find("retry budget exhausted; not retrying")
+	if !ok || fields["stage"] != StageSmokeCheck {
+		t.Fatalf("expected budget exhaustion to be logged for the smoke check, got %v", fields)
+	}
+}
+
+func TestResolveRetryBudget(t *testing.T) {
+	if n, err := resolveRetryBudget(""); err != nil || n != -1 {
+		t.Fatalf("expected unset budget to be unbounded, got %d, %v", n, err)
+	}
+	if n, err := resolveRetryBudget(" 3 "); err != nil || n != 3 {
+		t.Fatalf("expected budget 3, got %d, %v", n, err)
+	}
+	for _, value := range []string{"-1", "lots"} {
+		if _, err := resolveRetryBudget(value); apperrors.CodeOf(err) != apperrors.CodeConfig {
+			t.Fatalf("expected %q to be rejected with %q, got %v", value, apperrors.CodeConfig, err)
+		}
+	}
+}
+
+func TestControlPlaneRetries_ShareTheBudgetAndDoNotNest(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	svc := &Service{
+		logger:       &noopLogger{},
+		prepareRetry: &prepareRetryPolicy{attempts: 3, timeout: time.Second},
+	}
+	cp, err := svc.newControlPlaneClient(server.URL + "?token=test-token")
+	if err != nil {
+		t.Fatalf("newControlPlaneClient returned error: %v", err)
+	}
+
+	if _, err := svc.prepareApp(context.Background(), cp, controlplane.PrepareAppRequest{Name: "my-app"}); err == nil {
+		t.Fatal("expected prepare to fail")
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected the prepare retry loop alone to retry (3 requests), got %d", got)
+	}
+
+	calls.Store(0)
+	ctx := svc.withRetryBudget(context.Background(), 1)
+	if _, err := cp.GetAppStatus(ctx, "app_123"); err == nil {
+		t.Fatal("expected the app lookup to fail")
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected client retries to stop when the budget of 1 is spent, got %d requests", got)
+	}
+}
@@ -0,0 +1,53 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// RollbackApp redeploys the image of an earlier deployment of in.AppID. The
+// control plane already has that image, so nothing is built or pushed and
+// docker is never used. An app or deployment the control plane does not know
+// fails with CodeInvalidInput.
+func (s *Service) RollbackApp(ctx context.Context, in contracts.RollbackAppInput) (contracts.RollbackAppOutput, error) {
+	if err := in.Validate(); err != nil {
+		return contracts.RollbackAppOutput{}, apperrors.Wrap(apperrors.CodeInvalidInput, "validate rollback input", err)
+	}
+
+	controlPlaneURL, err := s.controlPlaneURL(in.SakiControlPlaneURL)
+	if err != nil {
+		return contracts.RollbackAppOutput{}, err
+	}
+	cp, err := s.newControlPlane(controlPlaneURL)
+	if err != nil {
+		return contracts.RollbackAppOutput{}, err
+	}
+
+	res, err := cp.RollbackDeployment(ctx, in.AppID, in.DeploymentID)
+	if err != nil {
+		var apiErr *controlplane.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return contracts.RollbackAppOutput{}, apperrors.Wrap(apperrors.CodeInvalidInput, "rollback app", fmt.Errorf("app %q or deployment %q does not exist: %w", in.AppID, in.DeploymentID, err))
+		}
+		return contracts.RollbackAppOutput{}, err
+	}
+
+	s.logger.Info("rollback started", map[string]any{
+		"app_id":            in.AppID,
+		"rolled_back_to":    in.DeploymentID,
+		"deployment_id":     res.DeploymentID,
+		"deployment_status": res.Status,
+	})
+	return contracts.RollbackAppOutput{
+		AppID:        res.AppID,
+		DeploymentID: res.DeploymentID,
+		URL:          res.URL,
+		Status:       res.Status,
+	}, nil
+}
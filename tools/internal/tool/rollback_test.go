@@ -0,0 +1,99 @@
+package tool
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestRollbackApp_ReturnsNewDeployment(t *testing.T) {
+	cp := &stubControlPlane{
+		rollbackRes: controlplane.DeployAppResponse{
+			AppID:        "app_1",
+			DeploymentID: "dep_3",
+			URL:          "https://my-app.saki.internal",
+			Status:       "deploying",
+		},
+	}
+	svc := &Service{
+		logger:          &noopLogger{},
+		newControlPlane: func(string) (controlPlaneClient, error) { return cp, nil },
+		newDockerClient: func(Logger) dockerClient {
+			t.Fatal("rollback must not use docker")
+			return nil
+		},
+	}
+
+	out, err := svc.RollbackApp(context.Background(), contracts.RollbackAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppID:               "app_1",
+		DeploymentID:        "dep_1",
+	})
+	if err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	want := contracts.RollbackAppOutput{
+		AppID:        "app_1",
+		DeploymentID: "dep_3",
+		URL:          "https://my-app.saki.internal",
+		Status:       "deploying",
+	}
+	if out != want {
+		t.Fatalf("expected %+v, got %+v", want, out)
+	}
+	if len(cp.rollbackReqs) != 1 || cp.rollbackReqs[0] != [2]string{"app_1", "dep_1"} {
+		t.Fatalf("unexpected rollback requests: %v", cp.rollbackReqs)
+	}
+}
+
+func TestRollbackApp_RejectsInvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input contracts.RollbackAppInput
+	}{
+		{name: "missing app id", input: contracts.RollbackAppInput{DeploymentID: "dep_1"}},
+		{name: "missing deployment id", input: contracts.RollbackAppInput{AppID: "app_1", DeploymentID: " "}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := &stubControlPlane{}
+			svc := &Service{
+				logger:          &noopLogger{},
+				newControlPlane: func(string) (controlPlaneClient, error) { return cp, nil },
+			}
+
+			tt.input.SakiControlPlaneURL = "https://cp.internal?token=test-token"
+			_, err := svc.RollbackApp(context.Background(), tt.input)
+			if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+				t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeInvalidInput, got, err)
+			}
+			if len(cp.rollbackReqs) != 0 {
+				t.Fatalf("expected no rollback request, got %v", cp.rollbackReqs)
+			}
+		})
+	}
+}
+
+func TestRollbackApp_MapsNotFoundToInvalidInput(t *testing.T) {
+	cp := &stubControlPlane{
+		rollbackErr: &controlplane.APIError{StatusCode: http.StatusNotFound, Message: "deployment not found"},
+	}
+	svc := &Service{
+		logger:          &noopLogger{},
+		newControlPlane: func(string) (controlPlaneClient, error) { return cp, nil },
+	}
+
+	_, err := svc.RollbackApp(context.Background(), contracts.RollbackAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppID:               "app_1",
+		DeploymentID:        "dep_missing",
+	})
+	if got := apperrors.CodeOf(err); got != apperrors.CodeInvalidInput {
+		t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeInvalidInput, got, err)
+	}
+}
@@ -0,0 +1,65 @@
+package tool
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/1800agents/saki/tools/docker"
+)
+
+// semverTagPattern matches release tags such as v1.2.3 or 1.2.3-rc.1. Build
+// metadata (+...) is excluded since docker tags cannot contain "+".
+var semverTagPattern = regexp.MustCompile(`^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
+// semverTag returns the nearest git tag when it is a semantic version, or ""
+// when there is no tag or it is not one.
+func (s *Service) semverTag(ctx context.Context) string {
+	if s.runGit == nil {
+		return ""
+	}
+	tag, err := s.runGitHistory(ctx, "describe", "--tags", "--abbrev=0")
+	if err != nil {
+		return ""
+	}
+	tag = strings.TrimSpace(tag)
+	if !semverTagPattern.MatchString(tag) || !dockerTagPattern.MatchString(tag) {
+		return ""
+	}
+	return tag
+}
+
+// pushSemverTag also publishes the pushed image as <repository>:<semver> when
+// the nearest git tag is a semantic version. The commit tag stays the one that
+// is deployed, so failures are logged and the deploy continues.
+func (s *Service) pushSemverTag(ctx context.Context, dockerClient dockerClient, prepared preparedImage, buildOpts docker.BuildOptions) {
+	tag := s.semverTag(ctx)
+	if tag == "" {
+		return
+	}
+	target := prepared.repository + ":" + tag
+	if buildOpts.PushesOnBuild() {
+		s.logger.Info("semver image tag skipped; multi-platform builds are not kept locally", map[string]any{
+			"image": target,
+		})
+		return
+	}
+
+	if err := dockerClient.Tag(ctx, prepared.image, target); err != nil {
+		s.logger.Error("semver image tag failed; continuing", map[string]any{
+			"image": target,
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := dockerClient.Push(ctx, target, docker.PushOptions{}); err != nil {
+		s.logger.Error("semver image push failed; continuing", map[string]any{
+			"image": target,
+			"error": err.Error(),
+		})
+		return
+	}
+	s.logger.Info("semver image tag pushed", map[string]any{
+		"image": target,
+	})
+}
@@ -0,0 +1,78 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/tool/tooltest"
+)
+
+func TestDeployApp_PushesSemverTagFromGit(t *testing.T) {
+	const image = "registry.internal/owner/my-app:0123456"
+
+	tests := []struct {
+		name      string
+		gitTag    string
+		gitErr    error
+		wantCalls []string
+	}{
+		{
+			name:   "semver tag is pushed alongside the commit tag",
+			gitTag: "v1.2.3\n",
+			wantCalls: []string{
+				"login registry.internal",
+				"build " + image,
+				"push " + image,
+				"tag " + image + " registry.internal/owner/my-app:v1.2.3",
+				"push registry.internal/owner/my-app:v1.2.3",
+			},
+		},
+		{
+			name:      "non-semver tag is ignored",
+			gitTag:    "release-candidate",
+			wantCalls: []string{"login registry.internal", "build " + image, "push " + image},
+		},
+		{
+			name:      "missing tag falls back silently",
+			gitErr:    errors.New("exit status 128: fatal: No names found, cannot describe anything."),
+			wantCalls: []string{"login registry.internal", "build " + image, "push " + image},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerStub := &tooltest.Docker{}
+			svc := NewTestService(TestDeps{
+				ControlPlane: &tooltest.ControlPlane{},
+				Docker:       dockerStub,
+				Env:          map[string]string{dockerRegistryEnv: "registry.internal"},
+			})
+			svc.runGit = func(_ context.Context, args ...string) (string, error) {
+				if strings.Join(args, " ") == "describe --tags --abbrev=0" {
+					return tt.gitTag, tt.gitErr
+				}
+				return "", errors.New("exit status 1")
+			}
+
+			out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				Name:                "my-app",
+				Description:         "internal app",
+				AppDir:              t.TempDir(),
+			})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if out.Image != image {
+				t.Fatalf("expected the commit tag to be deployed, got %q", out.Image)
+			}
+			if !reflect.DeepEqual(dockerStub.Calls, tt.wantCalls) {
+				t.Fatalf("unexpected docker calls:\ngot  %q\nwant %q", dockerStub.Calls, tt.wantCalls)
+			}
+		})
+	}
+}
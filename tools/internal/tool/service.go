@@ -2,26 +2,60 @@ package tool
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
-	"os/exec"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/1800agents/saki/tools/contracts"
 	"github.com/1800agents/saki/tools/controlplane"
 	"github.com/1800agents/saki/tools/docker"
 	"github.com/1800agents/saki/tools/internal/apperrors"
+	"github.com/1800agents/saki/tools/internal/jitter"
 	"github.com/1800agents/saki/tools/internal/logging"
 )
 
 const (
-	controlPlaneURLEnv    = "SAKI_CONTROL_PLANE_URL"
-	dockerRegistryEnv     = "SAKI_DOCKER_REGISTRY"
-	registryOnlyEnv       = "SAKI_REGISTRY_ONLY"
+	controlPlaneURLEnv   = "SAKI_CONTROL_PLANE_URL"
+	controlPlaneFileEnv  = "SAKI_CONTROL_PLANE_URL_FILE"
+	tokenHeaderEnv       = "SAKI_CONTROL_PLANE_TOKEN_HEADER"
+	dockerRegistryEnv    = "SAKI_DOCKER_REGISTRY"
+	registryOnlyEnv      = "SAKI_REGISTRY_ONLY"
+	deployTimeoutEnv     = "SAKI_DEPLOY_TIMEOUT"
+	smokeCheckEnv        = "SAKI_SMOKE_CHECK"
+	smokeCheckPathEnv    = "SAKI_SMOKE_CHECK_PATH"
+	smokeCheckTimeoutEnv = "SAKI_SMOKE_CHECK_TIMEOUT"
+	skipUnchangedEnv     = "SAKI_SKIP_UNCHANGED"
+	registryUsernameEnv  = "SAKI_REGISTRY_USERNAME"
+	registryPasswordEnv  = "SAKI_REGISTRY_PASSWORD"
+	stderrTailLinesEnv   = "SAKI_DOCKER_STDERR_LINES"
+	allowedRegistriesEnv = "SAKI_ALLOWED_REGISTRIES"
+	retryBudgetEnv       = "SAKI_RETRY_BUDGET"
+	skipDockerignoreEnv  = "SAKI_SKIP_DOCKERIGNORE"
+	imageRepositoryEnv   = "SAKI_IMAGE_REPOSITORY"
+	extraBuildArgsEnv    = "SAKI_EXTRA_BUILD_ARGS"
+	buildxBuilderEnv     = "SAKI_BUILDX_BUILDER"
+
 	defaultDockerRegistry = "https://registry.corgi-teeth.ts.net/v2/"
+	defaultDeployTimeout  = 20 * time.Minute
+
+	statusUnchanged = "unchanged"
 )
 
+const dockerHubHost = "docker.io"
+
+var dockerHubAPIHosts = map[string]bool{
+	"index.docker.io":         true,
+	"registry-1.docker.io":    true,
+	"registry.hub.docker.com": true,
+}
+
+var registryAPIVersionSegments = map[string]bool{"v1": true, "v2": true}
+
 var sessionLikeIDPattern = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[1-5][0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}|[0-9a-f]{32}`)
 
 type Logger interface {
@@ -32,38 +66,154 @@ type Logger interface {
 type controlPlaneClient interface {
 	PrepareApp(ctx context.Context, req controlplane.PrepareAppRequest) (controlplane.PrepareAppResponse, error)
 	DeployApp(ctx context.Context, req controlplane.DeployAppRequest) (controlplane.DeployAppResponse, error)
+	UpdateApp(ctx context.Context, appID string, req controlplane.DeployAppRequest) (controlplane.DeployAppResponse, error)
+	ListApps(ctx context.Context, req controlplane.ListAppsRequest) (controlplane.ListAppsResponse, error)
+	GetAppStatus(ctx context.Context, appID string) (controlplane.AppStatusResponse, error)
+	CancelDeployment(ctx context.Context, deploymentID string) error
+	CheckName(ctx context.Context, name string) (controlplane.NameAvailability, error)
+	DeleteApp(ctx context.Context, appID string) error
+	RollbackDeployment(ctx context.Context, appID, deploymentID string) (controlplane.DeployAppResponse, error)
 }
 
 type dockerClient interface {
-	Build(ctx context.Context, workDir, image string) error
-	Push(ctx context.Context, image string) error
+	Login(ctx context.Context, registry, username, password string) error
+	Build(ctx context.Context, workDir, image string, opts docker.BuildOptions) error
+	Push(ctx context.Context, image string, opts docker.PushOptions) error
+	Tag(ctx context.Context, source, target string) error
+	CopyImage(ctx context.Context, source, target string) error
+	InspectManifest(ctx context.Context, image string) error
+	LocalImageID(ctx context.Context, image string) (string, error)
+	RemoteImageID(ctx context.Context, image string) (id string, exists bool, err error)
 }
 
 type controlPlaneFactory func(controlPlaneURL string) (controlPlaneClient, error)
 
 // Service owns deploy orchestration and runtime server lifecycle.
 type Service struct {
-	logger               Logger
-	newControlPlane      controlPlaneFactory
-	newDockerClient      func(logger Logger) dockerClient
-	resolveGitCommit     func(ctx context.Context) (string, error)
-	dockerRegistryValue  func() string
-	registryOnlyValue    func() string
-	controlPlaneURLValue func() string
+	logger                 Logger
+	newControlPlane        controlPlaneFactory
+	newDockerClient        func(logger Logger) dockerClient
+	resolveGitCommit       func(ctx context.Context) (string, error)
+	runGit                 func(ctx context.Context, args ...string) (string, error)
+	now                    func() time.Time
+	jitter                 jitter.Func
+	dockerRegistryValue    func() string
+	registryOnlyValue      func() string
+	controlPlaneURLValue   func() string
+	controlPlaneFileValue  func() string
+	tokenHeaderValue       func() string
+	deployTimeoutValue     func() string
+	skipUnchangedValue     func() string
+	registryUserValue      func() string
+	registryPassValue      func() string
+	registryTokenUserValue func() string
+	allowedRegistriesValue func() string
+	retryBudgetValue       func() string
+	stderrTailLinesValue   func() string
+	skipDockerignoreValue  func() string
+	cancelOnAbortValue     func() string
+	imageRepositoryValue   func() string
+	checkNameValue         func() string
+	localTagValue          func() string
+	stagedPushValue        func() string
+	immutableTagsValue     func() string
+	extraBuildArgsValue    func() string
+	pathCommitValue        func() string
+	gitCommitValue         func() string
+	buildxBuilderValue     func() string
+	buildCPUQuotaValue     func() string
+	buildMemoryValue       func() string
+	deployWebhookValue     func() string
+	dockerConfigAuthValue  func() string
+	gitUnshallowValue      func() string
+	contextHashValue       func() string
+	imageTagTemplateValue  func() string
+	dockerfileHashValue    func() string
+	dockerfileContentValue func() string
+	updateExistingValue    func() string
+
+	smokeCheckValue         func() string
+	smokeCheckPathValue     func() string
+	smokeCheckTimeoutValue  func() string
+	smokeCheckRequiredValue func() string
+	smokeHTTPClient         httpDoer
+	webhookHTTPClient       httpDoer
+
+	// prepareRetry overrides defaultPrepareRetry when set.
+	prepareRetry *prepareRetryPolicy
+	// webhookRetry overrides defaultWebhookRetry when set.
+	webhookRetry *prepareRetryPolicy
+	// deployRetry overrides defaultDeployRetry when set.
+	deployRetry *prepareRetryPolicy
+	// dockerCredentials reports whether docker's config already holds
+	// credentials for a registry host; used with SAKI_DOCKER_CONFIG_AUTH.
+	dockerCredentials func(registry string) (bool, error)
 }
 
 func NewService() *Service {
-	return &Service{
-		logger:          logging.New(),
-		newControlPlane: newControlPlaneClient,
+	logger := logging.New()
+	s := &Service{
+		logger: logger,
 		newDockerClient: func(logger Logger) dockerClient {
-			return docker.NewAdapter(logger, nil)
+			adapter := docker.NewAdapter(logger, nil)
+			adapter.SetStderrTailLines(resolveStderrTailLines(os.Getenv(stderrTailLinesEnv)))
+			return adapter
 		},
-		resolveGitCommit:     resolveGitCommit,
-		dockerRegistryValue:  func() string { return os.Getenv(dockerRegistryEnv) },
-		registryOnlyValue:    func() string { return os.Getenv(registryOnlyEnv) },
-		controlPlaneURLValue: func() string { return os.Getenv(controlPlaneURLEnv) },
+		resolveGitCommit:  gitCommitResolver(runGit),
+		runGit:            runGit,
+		dockerCredentials: dockerConfigCredentials,
+		jitter:            jitter.Full(nil),
 	}
+	s.newControlPlane = newControlPlaneCache(defaultControlPlaneCacheSize, s.newControlPlaneClient).get
+	s.bindEnv(os.Getenv)
+	return s
+}
+
+// bindEnv points every SAKI_* setting at lookup.
+func (s *Service) bindEnv(lookup func(key string) string) {
+	value := func(key string) func() string {
+		return func() string { return lookup(key) }
+	}
+
+	s.dockerRegistryValue = value(dockerRegistryEnv)
+	s.registryOnlyValue = value(registryOnlyEnv)
+	s.controlPlaneURLValue = value(controlPlaneURLEnv)
+	s.controlPlaneFileValue = value(controlPlaneFileEnv)
+	s.tokenHeaderValue = value(tokenHeaderEnv)
+	s.deployTimeoutValue = value(deployTimeoutEnv)
+	s.skipUnchangedValue = value(skipUnchangedEnv)
+	s.registryUserValue = value(registryUsernameEnv)
+	s.registryPassValue = value(registryPasswordEnv)
+	s.registryTokenUserValue = value(registryTokenUsernameEnv)
+	s.allowedRegistriesValue = value(allowedRegistriesEnv)
+	s.retryBudgetValue = value(retryBudgetEnv)
+	s.stderrTailLinesValue = value(stderrTailLinesEnv)
+	s.skipDockerignoreValue = value(skipDockerignoreEnv)
+	s.cancelOnAbortValue = value(cancelOnAbortEnv)
+	s.imageRepositoryValue = value(imageRepositoryEnv)
+	s.checkNameValue = value(checkNameEnv)
+	s.localTagValue = value(localTagEnv)
+	s.stagedPushValue = value(stagedPushEnv)
+	s.immutableTagsValue = value(immutableTagsEnv)
+	s.extraBuildArgsValue = value(extraBuildArgsEnv)
+	s.pathCommitValue = value(pathCommitEnv)
+	s.gitCommitValue = value(gitCommitEnv)
+	s.buildxBuilderValue = value(buildxBuilderEnv)
+	s.buildCPUQuotaValue = value(buildCPUQuotaEnv)
+	s.buildMemoryValue = value(buildMemoryEnv)
+	s.deployWebhookValue = value(deployWebhookEnv)
+	s.dockerConfigAuthValue = value(dockerConfigAuthEnv)
+	s.gitUnshallowValue = value(gitUnshallowEnv)
+	s.contextHashValue = value(contextHashEnv)
+	s.imageTagTemplateValue = value(imageTagTemplateEnv)
+	s.dockerfileHashValue = value(dockerfileHashEnv)
+	s.dockerfileContentValue = value(dockerfileContentEnv)
+	s.updateExistingValue = value(updateExistingEnv)
+
+	s.smokeCheckValue = value(smokeCheckEnv)
+	s.smokeCheckPathValue = value(smokeCheckPathEnv)
+	s.smokeCheckTimeoutValue = value(smokeCheckTimeoutEnv)
+	s.smokeCheckRequiredValue = value(smokeCheckRequiredEnv)
 }
 
 func (s *Service) Run(ctx context.Context) error {
@@ -72,18 +222,175 @@ func (s *Service) Run(ctx context.Context) error {
 }
 
 // DeployApp executes the v1 deploy flow and returns normalized output payload.
+// The whole flow is bounded by the deploy timeout (SAKI_DEPLOY_TIMEOUT).
 func (s *Service) DeployApp(ctx context.Context, in contracts.DeployAppInput) (contracts.DeployAppOutput, error) {
-	var zero contracts.DeployAppOutput
+	return s.DeployAppWithProgress(ctx, in, nil)
+}
 
+// DeployAppWithProgress runs DeployApp and reports each stage transition to progress.
+func (s *Service) DeployAppWithProgress(ctx context.Context, in contracts.DeployAppInput, progress ProgressFunc) (contracts.DeployAppOutput, error) {
+	if in.ValidateOnly {
+		return s.validateOnly(ctx, in)
+	}
+
+	var out contracts.DeployAppOutput
+	err := s.withDeployTimeout(ctx, in, func(ctx context.Context) error {
+		timings := s.newStageTimings()
+		var err error
+		out, err = s.deployApp(ctx, in, timings.wrap(progress))
+		out.Timings = timings.millis()
+		s.logger.Info("deploy stage timings", logging.DeployFields(ctx, map[string]any{
+			"succeeded":  err == nil,
+			"timings_ms": out.Timings,
+		}))
+		return err
+	})
+	if err != nil {
+		return contracts.DeployAppOutput{}, err
+	}
+	return out, nil
+}
+
+// withDeployTimeout validates in and runs fn under the deploy timeout and
+// retry budget, mapping a deadline-caused failure to CodeTimeout. The context
+// passed to fn carries the app name and a fresh deploy ID for component logs.
+func (s *Service) withDeployTimeout(ctx context.Context, in contracts.DeployAppInput, fn func(ctx context.Context) error) error {
 	if err := in.Validate(); err != nil {
-		return zero, apperrors.Wrap(apperrors.CodeInvalidInput, "validate deploy input", err)
+		return apperrors.Wrap(apperrors.CodeInvalidInput, "validate deploy input", err)
+	}
+	if err := docker.ValidatePlatforms(in.Platforms); err != nil {
+		return err
+	}
+
+	timeout, err := resolveDeployTimeout(envValue(s.deployTimeoutValue))
+	if err != nil {
+		return err
+	}
+	retries, err := resolveRetryBudget(envValue(s.retryBudgetValue))
+	if err != nil {
+		return err
+	}
+
+	deployCtx := logging.WithDeployContext(s.withRetryBudget(ctx, retries), in.Name, logging.NewDeployID())
+	deployCtx, cancel := context.WithTimeout(deployCtx, timeout)
+	defer cancel()
+
+	err = fn(deployCtx)
+	if err != nil && errors.Is(deployCtx.Err(), context.DeadlineExceeded) && apperrors.CodeOf(err) != apperrors.CodeTimeout {
+		return apperrors.Wrap(apperrors.CodeTimeout, "deploy app", fmt.Errorf("deploy exceeded timeout of %s: %w", timeout, err))
 	}
+	return err
+}
+
+// preparedImage is the outcome of the prepare stage: the control plane used for
+// prepare and the image reference to build and push.
+type preparedImage struct {
+	controlPlane controlPlaneClient
+	prepare      controlplane.PrepareAppResponse
+	commit       string
+	repository   string
+	// tag is the image tag: the required tag, or the SAKI_IMAGE_TAG_TEMPLATE
+	// rendering.
+	tag    string
+	image  string
+	appDir string
+	// contextHash is the build context hash sent with POST /apps; set by
+	// the build when SAKI_CONTEXT_HASH is enabled.
+	contextHash string
+	// state is saved after each stage when the deploy has a state file.
+	state *deployState
+}
+
+func (s *Service) deployApp(ctx context.Context, in contracts.DeployAppInput, progress ProgressFunc) (contracts.DeployAppOutput, error) {
+	var zero contracts.DeployAppOutput
 
-	envControlPlaneURL := ""
-	if s.controlPlaneURLValue != nil {
-		envControlPlaneURL = s.controlPlaneURLValue()
+	if s.localTagEnabled() {
+		return s.deployLocalTag(ctx, in, progress)
 	}
-	controlPlaneURL, err := resolveControlPlaneURL(in.SakiControlPlaneURL, envControlPlaneURL)
+
+	state, err := s.openDeployState(in)
+	if err != nil {
+		return zero, err
+	}
+	prepared, err := s.prepareImage(ctx, in, state, progress)
+	if err != nil {
+		return zero, err
+	}
+
+	if in.DryRun {
+		return s.planDeploy(ctx, in, prepared)
+	}
+
+	if envEnabled(envValue(s.skipUnchangedValue)) {
+		if current, ok := s.liveApp(ctx, prepared, in.Name); ok {
+			s.clearDeployState(state)
+			return contracts.DeployAppOutput{
+				AppID:          current.AppID,
+				DeploymentID:   current.DeploymentID,
+				Image:          prepared.image,
+				URL:            current.URL,
+				Status:         statusUnchanged,
+				GitCommit:      prepared.commit,
+				TokenExpiresAt: prepared.prepare.ExpiresAt,
+			}, nil
+		}
+	}
+
+	state.recordPrepare(in.Name, prepared)
+	s.saveDeployState(state)
+	prepared.state = state
+
+	var buildCache *contracts.BuildCacheStats
+	var contextHash string
+	if state.pushed() {
+		s.logger.Info("deploy state records the image as pushed; skipping build and push", map[string]any{
+			"state_file": state.path,
+			"image":      prepared.image,
+		})
+		buildCache, contextHash = state.BuildCache, state.ContextHash
+		progress.skipped(StageBuild)
+		progress.skipped(StagePush)
+	} else {
+		buildCache, contextHash, err = s.buildAndPush(ctx, in, prepared, progress)
+		if err != nil {
+			return zero, err
+		}
+	}
+	prepared.contextHash = contextHash
+
+	if envEnabled(envValue(s.registryOnlyValue)) {
+		out := contracts.DeployAppOutput{
+			Image:          prepared.image,
+			Status:         "pushed",
+			GitCommit:      prepared.commit,
+			TokenExpiresAt: prepared.prepare.ExpiresAt,
+			BuildCache:     buildCache,
+		}
+		s.notifyWebhook(ctx, in.Name, out)
+		s.clearDeployState(state)
+		return out, nil
+	}
+
+	out, err := s.deployImage(ctx, prepared.controlPlane, in, prepared, progress)
+	if err != nil {
+		return zero, err
+	}
+	out.BuildCache = buildCache
+	s.clearDeployState(state)
+	return out, nil
+}
+
+// prepareImage runs the prepare stage. When state holds a prepare for the
+// same commit whose push token is still valid, POST /apps/prepare is skipped
+// and the saved response is used.
+func (s *Service) prepareImage(ctx context.Context, in contracts.DeployAppInput, state *deployState, progress ProgressFunc) (preparedImage, error) {
+	var zero preparedImage
+
+	controlPlaneURL, err := s.controlPlaneURL(in.SakiControlPlaneURL)
+	if err != nil {
+		return zero, err
+	}
+	repositoryOverride, err := resolveImageRepositoryOverride(in.ImageRepository, envValue(s.imageRepositoryValue))
 	if err != nil {
 		return zero, err
 	}
@@ -93,108 +400,395 @@ func (s *Service) DeployApp(ctx context.Context, in contracts.DeployAppInput) (c
 		return zero, err
 	}
 
-	commit, err := s.resolveGitCommit(ctx)
+	progress.started(StagePrepare)
+	if err := s.ensureNameAvailable(ctx, cp, in.Name); err != nil {
+		progress.failed(StagePrepare, err)
+		return zero, err
+	}
+	commit, err := s.gitCommit(ctx, in)
 	if err != nil {
+		progress.failed(StagePrepare, err)
 		return zero, err
 	}
 
-	prepareRes, err := cp.PrepareApp(ctx, controlplane.PrepareAppRequest{
-		Name:      in.Name,
-		GitCommit: commit,
-	})
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	prepareRes, resumed, err := state.resumedPrepare(commit, now())
 	if err != nil {
+		progress.failed(StagePrepare, err)
 		return zero, err
 	}
+	if resumed {
+		s.logger.Info("resuming deploy from state file; skipping prepare", map[string]any{
+			"state_file": state.path,
+			"image":      state.Image,
+		})
+	} else {
+		prepareRes, err = s.prepareApp(ctx, cp, controlplane.PrepareAppRequest{
+			Name:      in.Name,
+			GitCommit: commit,
+			Org:       in.Org,
+		})
+		if err != nil {
+			progress.failed(StagePrepare, err)
+			return zero, err
+		}
+	}
 
-	imageRepository := resolveImageRepository(
+	resolution := explainImageRepository(
 		prepareRes.Repository,
 		resolveDockerRegistry(envValue(s.dockerRegistryValue)),
 	)
-	image, err := buildImageName(imageRepository, prepareRes.RequiredTag)
+	if repositoryOverride != "" {
+		resolution = resolution.withOverride(repositoryOverride)
+	}
+	s.logger.Info("image repository resolved", resolution.logFields())
+	tag, err := s.imageTag(ctx, commit, prepareRes.RequiredTag)
 	if err != nil {
+		progress.failed(StagePrepare, err)
+		return zero, err
+	}
+	image, err := buildImageName(resolution.Repository, tag)
+	if err != nil {
+		progress.failed(StagePrepare, err)
+		return zero, err
+	}
+	if err := checkRegistryAllowed(resolution.Repository, envValue(s.allowedRegistriesValue)); err != nil {
+		progress.failed(StagePrepare, err)
 		return zero, err
 	}
 
 	appDir, err := resolveAppDir(in.AppDir)
 	if err != nil {
+		progress.failed(StagePrepare, err)
 		return zero, err
 	}
+	progress.completed(StagePrepare)
 
-	s.logger.Info("docker build starting", map[string]any{
-		"app_dir": appDir,
-		"image":   image,
-	})
+	return preparedImage{
+		controlPlane: cp,
+		prepare:      prepareRes,
+		commit:       commit,
+		repository:   resolution.Repository,
+		tag:          tag,
+		image:        image,
+		appDir:       appDir,
+	}, nil
+}
+
+// buildAndPush builds and pushes the prepared image. It returns the BuildKit
+// cache summary, or nil when the build output could not be parsed, and the
+// build context hash ("" unless SAKI_CONTEXT_HASH is enabled).
+func (s *Service) buildAndPush(ctx context.Context, in contracts.DeployAppInput, prepared preparedImage, progress ProgressFunc) (*contracts.BuildCacheStats, string, error) {
+	appDir, image := prepared.appDir, prepared.image
+	if s.stagedPushEnabled() {
+		if err := checkStagingTag(prepared.tag); err != nil {
+			return nil, "", err
+		}
+	}
+	limits, err := s.buildLimits()
+	if err != nil {
+		return nil, "", err
+	}
+	buildArgs, err := readBuildArgFiles(in.BuildArgFiles)
+	if err != nil {
+		return nil, "", err
+	}
+	var buildCache *contracts.BuildCacheStats
+	buildOpts := docker.BuildOptions{
+		CPUQuota:       limits.cpuQuota,
+		Memory:         limits.memory,
+		Platforms:      in.Platforms,
+		Labels:         s.gitMetadata(ctx, prepared.commit),
+		ExtraBuildArgs: strings.Fields(envValue(s.extraBuildArgsValue)),
+		BuildArgs:      buildArgs,
+		Builder:        strings.TrimSpace(envValue(s.buildxBuilderValue)),
+		CacheStats: func(stats docker.BuildCacheStats) {
+			buildCache = &contracts.BuildCacheStats{CachedSteps: stats.CachedSteps, TotalSteps: stats.TotalSteps}
+		},
+	}
 	dockerClient := s.newDockerClient(s.logger)
-	if err := dockerClient.Build(ctx, appDir, image); err != nil {
-		s.logger.Error("docker build failed", map[string]any{
+
+	if err := s.registryLogin(ctx, dockerClient, prepared.repository, prepared.prepare.PushToken, progress); err != nil {
+		return nil, "", err
+	}
+
+	var contextHash string
+	if s.builtImageAvailable(ctx, dockerClient, prepared.state) {
+		s.logger.Info("deploy state records the image as built; skipping docker build", map[string]any{
+			"image": image,
+		})
+		buildCache, contextHash = prepared.state.BuildCache, prepared.state.ContextHash
+		progress.skipped(StageBuild)
+	} else {
+		progress.started(StageBuild)
+		s.ensureDockerignore(appDir)
+		contextHash = s.contextHash(appDir)
+		s.logger.Info("docker build starting", map[string]any{
+			"app_dir":   appDir,
+			"image":     image,
+			"platforms": in.Platforms,
+		})
+		if err := dockerClient.Build(ctx, appDir, s.buildTarget(image, buildOpts), buildOpts); err != nil {
+			s.logger.Error("docker build failed", map[string]any{
+				"app_dir": appDir,
+				"image":   image,
+				"error":   err.Error(),
+			})
+			progress.failed(StageBuild, err)
+			return nil, "", err
+		}
+		buildFields := map[string]any{
 			"app_dir": appDir,
 			"image":   image,
-			"error":   err.Error(),
-		})
-		return zero, err
+		}
+		if buildCache != nil {
+			buildFields["cached_steps"] = buildCache.CachedSteps
+			buildFields["total_steps"] = buildCache.TotalSteps
+		}
+		s.logger.Info("docker build completed", buildFields)
+		progress.completed(StageBuild)
+		prepared.state.recordBuild(contextHash, buildCache)
+		s.saveDeployState(prepared.state)
 	}
-	s.logger.Info("docker build completed", map[string]any{
-		"app_dir": appDir,
-		"image":   image,
-	})
-	s.logger.Info("docker push starting", map[string]any{
-		"image": image,
-	})
-	if err := dockerClient.Push(ctx, image); err != nil {
-		s.logger.Error("docker push failed", map[string]any{
+
+	progress.started(StagePush)
+	if err := s.checkTagConflict(ctx, dockerClient, in, image, buildOpts); err != nil {
+		s.logger.Error("image tag check failed", map[string]any{
 			"image": image,
 			"error": err.Error(),
 		})
-		return zero, err
+		progress.failed(StagePush, err)
+		return nil, "", err
 	}
-	s.logger.Info("docker push completed", map[string]any{
-		"image": image,
-	})
-
-	if envEnabled(envValue(s.registryOnlyValue)) {
-		return contracts.DeployAppOutput{
-			Image:  image,
-			Status: "pushed",
-		}, nil
+	if buildOpts.PushesOnBuild() && s.stagedPushEnabled() {
+		if err := s.promoteStaged(ctx, dockerClient, image); err != nil {
+			s.logger.Error("staging image promotion failed", map[string]any{
+				"image": image,
+				"error": err.Error(),
+			})
+			progress.failed(StagePush, err)
+			return nil, "", err
+		}
+	} else if buildOpts.PushesOnBuild() {
+		s.logger.Info("docker push skipped; multi-platform build already pushed", map[string]any{
+			"image": image,
+		})
+	} else {
+		s.logger.Info("docker push starting", map[string]any{
+			"image": image,
+		})
+		if err := s.pushImage(ctx, dockerClient, image, progress); err != nil {
+			s.logger.Error("docker push failed", map[string]any{
+				"image": image,
+				"error": err.Error(),
+			})
+			progress.failed(StagePush, err)
+			return nil, "", err
+		}
+		s.logger.Info("docker push completed", map[string]any{
+			"image": image,
+		})
 	}
+	s.pushSemverTag(ctx, dockerClient, prepared, buildOpts)
+	progress.completed(StagePush)
+	prepared.state.recordPush()
+	s.saveDeployState(prepared.state)
+
+	return buildCache, contextHash, nil
+}
 
-	deployRes, err := cp.DeployApp(ctx, controlplane.DeployAppRequest{
-		Name:        in.Name,
-		Description: in.Description,
-		Image:       image,
+func (s *Service) deployImage(ctx context.Context, cp controlPlaneClient, in contracts.DeployAppInput, prepared preparedImage, progress ProgressFunc) (contracts.DeployAppOutput, error) {
+	var zero contracts.DeployAppOutput
+
+	dockerfile := s.dockerfileProvenance(prepared.appDir)
+
+	progress.started(StageDeploy)
+	deployRes, err := s.postDeploy(ctx, cp, s.updateAppID(prepared.prepare), controlplane.DeployAppRequest{
+		Name:            in.Name,
+		Description:     in.Description,
+		Image:           prepared.image,
+		Org:             in.Org,
+		DeploymentToken: prepared.prepare.DeploymentToken,
+		ContextHash:     prepared.contextHash,
+		DockerfileHash:  dockerfile.hash,
+		Dockerfile:      dockerfile.content,
+		Note:            contracts.CleanNote(in.Note),
+		// One key per logical deploy, reused by every postDeploy retry.
+		IdempotencyKey: controlplane.NewIdempotencyKey(),
 	})
 	if err != nil {
+		progress.failed(StageDeploy, err)
 		return zero, err
 	}
+	progress.completed(StageDeploy)
 
-	return contracts.DeployAppOutput{
-		AppID:        deployRes.AppID,
-		DeploymentID: deployRes.DeploymentID,
-		Image:        image,
-		URL:          deployRes.URL,
-		Status:       deployRes.Status,
-	}, nil
+	out := contracts.DeployAppOutput{
+		AppID:          deployRes.AppID,
+		DeploymentID:   deployRes.DeploymentID,
+		Image:          prepared.image,
+		URL:            deployRes.URL,
+		Status:         deployRes.Status,
+		GitCommit:      prepared.commit,
+		TokenExpiresAt: prepared.prepare.ExpiresAt,
+	}
+
+	if envEnabled(envValue(s.smokeCheckValue)) && strings.TrimSpace(out.URL) != "" {
+		progress.started(StageSmokeCheck)
+		status, err := s.smokeCheck(ctx, out.URL)
+		if err != nil {
+			progress.failed(StageSmokeCheck, err)
+			return zero, err
+		}
+		progress.completed(StageSmokeCheck)
+		out.Status = status
+	}
+
+	if s.cancelAbortedDeployment(ctx, cp, out.DeploymentID) {
+		return zero, fmt.Errorf("deploy aborted after deployment %s was created: %w", out.DeploymentID, ctx.Err())
+	}
+
+	s.notifyWebhook(ctx, in.Name, out)
+	return out, nil
 }
 
-func newControlPlaneClient(controlPlaneURL string) (controlPlaneClient, error) {
-	return controlplane.NewClient(controlPlaneURL)
+// liveApp reports whether the control plane already runs prepared.image for
+// name. Lookup failures are logged and treated as "not live" so the deploy
+// proceeds.
+func (s *Service) liveApp(ctx context.Context, prepared preparedImage, name string) (controlplane.App, bool) {
+	image := prepared.image
+	current, err := currentApp(ctx, prepared.controlPlane, prepared.prepare.ExistingAppID, name)
+	if err != nil {
+		var apiErr *controlplane.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			s.logger.Info("app not found; deploying it as new", map[string]any{
+				"name": name,
+			})
+			return controlplane.App{}, false
+		}
+		s.logger.Error("current app lookup failed; continuing with deploy", map[string]any{
+			"name":  name,
+			"error": err.Error(),
+		})
+		return controlplane.App{}, false
+	}
+
+	if strings.TrimSpace(current.Image) != image {
+		return controlplane.App{}, false
+	}
+
+	s.logger.Info("image already live; skipping build, push, and deploy", map[string]any{
+		"name":  name,
+		"image": image,
+	})
+	return current, true
 }
 
-func resolveGitCommit(ctx context.Context) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
-	output, err := cmd.CombinedOutput()
+// currentApp looks up the caller's app called name with GET /apps/{app_id}.
+// The control plane only serves apps by ID, so the ID is prepare's
+// existing_app_id when set, and is otherwise found by paging through GET
+// /apps. An app that is not listed is reported as a 404 *controlplane.APIError.
+func currentApp(ctx context.Context, cp controlPlaneClient, appID, name string) (controlplane.App, error) {
+	appID = strings.TrimSpace(appID)
+	if appID == "" {
+		id, err := findAppID(ctx, cp, name)
+		if err != nil {
+			return controlplane.App{}, err
+		}
+		appID = id
+	}
+
+	status, err := cp.GetAppStatus(ctx, appID)
 	if err != nil {
-		return "", apperrors.Wrap(apperrors.CodeConfig, "resolve git commit", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output))))
+		return controlplane.App{}, err
+	}
+	return controlplane.App{
+		AppID:        appID,
+		Name:         name,
+		Image:        status.Image,
+		URL:          status.URL,
+		Status:       status.Status,
+		DeploymentID: status.CurrentDeploymentID,
+	}, nil
+}
+
+// findAppID returns the ID of the caller's app called name from GET /apps.
+func findAppID(ctx context.Context, cp controlPlaneClient, name string) (string, error) {
+	cursor := ""
+	for {
+		page, err := cp.ListApps(ctx, controlplane.ListAppsRequest{Cursor: cursor})
+		if err != nil {
+			return "", err
+		}
+		for _, app := range page.Apps {
+			if app.Name == name && app.AppID != "" {
+				return app.AppID, nil
+			}
+		}
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return "", &controlplane.APIError{
+				StatusCode: http.StatusNotFound,
+				RemoteCode: "not_found",
+				Message:    fmt.Sprintf("app %q not found", name),
+			}
+		}
+		cursor = page.NextCursor
 	}
+}
+
+// Idempotent control plane requests are retried on 5xx and timeouts, so a
+// control plane rolling restart does not fail the deploy. POST /apps is not.
+const (
+	controlPlaneRetryAttempts = 3
+	controlPlaneRetryDelay    = 500 * time.Millisecond
+)
 
-	commit := strings.TrimSpace(string(output))
-	if commit == "" {
-		return "", apperrors.New(apperrors.CodeConfig, "resolve git commit", "git commit hash is empty")
+// newControlPlaneClient is the production controlPlaneFactory. It sends the
+// token as a header under SAKI_CONTROL_PLANE_TOKEN_HEADER.
+func (s *Service) newControlPlaneClient(controlPlaneURL string) (controlPlaneClient, error) {
+	opts := []controlplane.Option{
+		controlplane.WithLogger(s.logger),
+		controlplane.WithRetry(controlPlaneRetryAttempts, controlPlaneRetryDelay),
 	}
+	if envEnabled(envValue(s.tokenHeaderValue)) {
+		opts = append(opts, controlplane.WithTokenInHeader())
+	}
+	return controlplane.NewClient(controlPlaneURL, opts...)
+}
+
+// gitCommitResolver resolves HEAD with run (runGit in production). Running
+// outside a git repository gets a dedicated message, since it usually means
+// the tool was started from the wrong directory.
+func gitCommitResolver(run func(ctx context.Context, args ...string) (string, error)) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		commit, err := run(ctx, "rev-parse", "HEAD")
+		if err != nil {
+			if strings.Contains(strings.ToLower(err.Error()), "not a git repository") {
+				return "", apperrors.Wrap(apperrors.CodeConfig, "resolve git commit", fmt.Errorf("current directory is not a git repository; run inside your app's repo: %w", err))
+			}
+			return "", apperrors.Wrap(apperrors.CodeConfig, "resolve git commit", err)
+		}
 
-	return commit, nil
+		commit = strings.TrimSpace(commit)
+		if commit == "" {
+			return "", apperrors.New(apperrors.CodeConfig, "resolve git commit", "git commit hash is empty")
+		}
+
+		return commit, nil
+	}
 }
 
+// Docker's reference limits: a repository name, including its registry host,
+// is at most 255 characters and a tag at most 128.
+const (
+	maxRepositoryLength = 255
+	maxTagLength        = 128
+)
+
+// buildImageName joins repository and requiredTag into an image reference,
+// rejecting components longer than docker accepts.
 func buildImageName(repository, requiredTag string) (string, error) {
 	repo := strings.TrimSpace(repository)
 	tag := strings.TrimSpace(requiredTag)
@@ -205,6 +799,18 @@ func buildImageName(repository, requiredTag string) (string, error) {
 	if tag == "" {
 		return "", apperrors.New(apperrors.CodeControlPlane, "prepare app", "required tag is empty")
 	}
+	if len(repo) > maxRepositoryLength {
+		return "", apperrors.New(apperrors.CodeInvalidInput, "build image name", fmt.Sprintf(
+			"image repository %q is %d characters; docker allows at most %d (shorten the app name or image_repository)",
+			repo, len(repo), maxRepositoryLength,
+		))
+	}
+	if len(tag) > maxTagLength {
+		return "", apperrors.New(apperrors.CodeInvalidInput, "build image name", fmt.Sprintf(
+			"image tag %q is %d characters; docker allows at most %d",
+			tag, len(tag), maxTagLength,
+		))
+	}
 
 	return repo + ":" + tag, nil
 }
@@ -226,16 +832,106 @@ func resolveAppDir(appDir string) (string, error) {
 	return dir, nil
 }
 
+func resolveDeployTimeout(envTimeout string) (time.Duration, error) {
+	value := strings.TrimSpace(envTimeout)
+	if value == "" {
+		return defaultDeployTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, apperrors.Wrap(apperrors.CodeConfig, "resolve deploy timeout", fmt.Errorf("parse %s: %w", deployTimeoutEnv, err))
+	}
+	if timeout <= 0 {
+		return 0, apperrors.New(apperrors.CodeConfig, "resolve deploy timeout", deployTimeoutEnv+" must be positive")
+	}
+
+	return timeout, nil
+}
+
+// DockerRegistry returns the registry endpoint from SAKI_DOCKER_REGISTRY or the default.
+func DockerRegistry() string {
+	return resolveDockerRegistry(os.Getenv(dockerRegistryEnv))
+}
+
+// ControlPlaneURL returns the tokenized control plane URL from
+// SAKI_CONTROL_PLANE_URL, or from the file named by
+// SAKI_CONTROL_PLANE_URL_FILE. An unreadable file yields "".
+func ControlPlaneURL() string {
+	url, err := envControlPlaneURL(os.Getenv(controlPlaneURLEnv), os.Getenv(controlPlaneFileEnv))
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
 func resolveDockerRegistry(envRegistry string) string {
 	return firstNonEmpty(envRegistry, defaultDockerRegistry)
 }
 
 func resolveImageRepository(prepareRepository, registry string) string {
+	return explainImageRepository(prepareRepository, registry).Repository
+}
+
+// imageRepositoryResolution records each step that turns the prepare repository
+// into the pushed repository, so users can see why their image name changed.
+type imageRepositoryResolution struct {
+	Input         string
+	DetectedHost  string
+	Path          string
+	SanitizedPath string
+	Registry      string
+	Override      string
+	Repository    string
+}
+
+func (r imageRepositoryResolution) logFields() map[string]any {
+	return map[string]any{
+		"input_repository": r.Input,
+		"detected_host":    r.DetectedHost,
+		"path":             r.Path,
+		"sanitized_path":   r.SanitizedPath,
+		"registry":         r.Registry,
+		"override":         r.Override,
+		"repository":       r.Repository,
+	}
+}
+
+// withOverride replaces the resolved repository with an explicitly chosen
+// one. The override is used verbatim: no registry prefix or sanitizing.
+func (r imageRepositoryResolution) withOverride(repository string) imageRepositoryResolution {
+	r.Override = repository
+	r.Repository = repository
+	return r
+}
+
+// resolveImageRepositoryOverride returns the repository that replaces the
+// prepare repository, preferring the deploy input over SAKI_IMAGE_REPOSITORY.
+// The input is checked by DeployAppInput.Validate; an invalid env value fails
+// with CodeConfig.
+func resolveImageRepositoryOverride(inputRepository, envRepository string) (string, error) {
+	if repository := strings.TrimSpace(inputRepository); repository != "" {
+		return repository, nil
+	}
+	repository := strings.TrimSpace(envRepository)
+	if repository == "" {
+		return "", nil
+	}
+	if err := contracts.ValidateImageRepository(repository); err != nil {
+		return "", apperrors.Wrap(apperrors.CodeConfig, "resolve image repository", fmt.Errorf("invalid %s: %w", imageRepositoryEnv, err))
+	}
+	return repository, nil
+}
+
+func explainImageRepository(prepareRepository, registry string) imageRepositoryResolution {
 	repository := strings.TrimSpace(prepareRepository)
-	normalizedRegistry := normalizeRegistryForImage(registry)
+	res := imageRepositoryResolution{
+		Input:    repository,
+		Registry: normalizeRegistryForImage(registry),
+	}
 
 	if repository == "" {
-		return repository
+		return res
 	}
 
 	hasHost := false
@@ -255,26 +951,30 @@ func resolveImageRepository(prepareRepository, registry string) string {
 
 	pathPart := repository
 	if hasHost {
+		res.DetectedHost = repository
 		if slash := strings.IndexByte(repository, '/'); slash >= 0 {
+			res.DetectedHost = repository[:slash]
 			pathPart = repository[slash+1:]
 		}
 	}
+	res.Path = pathPart
 
 	pathPart = sanitizeRepositoryPath(pathPart)
 	if pathPart == "" {
 		pathPart = repository
 	}
+	res.SanitizedPath = pathPart
 
-	if normalizedRegistry == "" {
-		if hasHost {
-			if slash := strings.IndexByte(repository, '/'); slash >= 0 {
-				return repository[:slash+1] + pathPart
-			}
-		}
-		return pathPart
+	switch {
+	case res.Registry != "":
+		res.Repository = res.Registry + "/" + pathPart
+	case hasHost && strings.Contains(repository, "/"):
+		res.Repository = res.DetectedHost + "/" + pathPart
+	default:
+		res.Repository = pathPart
 	}
 
-	return normalizedRegistry + "/" + pathPart
+	return res
 }
 
 func sanitizeRepositoryPath(path string) string {
@@ -299,20 +999,62 @@ func sanitizeRepositoryPath(path string) string {
 	return strings.Join(out, "/")
 }
 
+// normalizeRegistryForImage turns a registry endpoint (e.g.
+// https://registry.internal:8443/v2/, ghcr.io, docker.io/library) into the
+// host[:port][/namespace] prefix used in image references. Scheme-less values
+// are parsed as a host so that ports are never mistaken for a URL scheme, a
+// trailing API version segment (/v1, /v2) is dropped when present, and Docker
+// Hub API hosts collapse to docker.io.
 func normalizeRegistryForImage(registry string) string {
 	value := strings.TrimSpace(registry)
 	if value == "" {
 		return ""
 	}
 
-	if strings.Contains(value, "://") {
-		parts := strings.SplitN(value, "://", 2)
-		value = parts[1]
+	if !strings.Contains(value, "://") {
+		value = "//" + value
+	}
+
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" {
+		value = strings.TrimPrefix(value, "//")
+		if idx := strings.Index(value, "://"); idx >= 0 {
+			value = value[idx+3:]
+		}
+		value = strings.TrimRight(value, "/")
+		value = strings.TrimSuffix(value, "/v2")
+		return strings.TrimRight(value, "/")
+	}
+
+	host := strings.ToLower(parsed.Host)
+	if dockerHubAPIHosts[host] {
+		host = dockerHubHost
+	}
+
+	segments := strings.FieldsFunc(parsed.Path, func(r rune) bool { return r == '/' })
+	if n := len(segments); n > 0 && registryAPIVersionSegments[segments[n-1]] {
+		segments = segments[:n-1]
+	}
+	path := strings.Join(segments, "/")
+
+	if path == "" {
+		return host
 	}
+	return host + "/" + path
+}
 
-	value = strings.TrimRight(value, "/")
-	value = strings.TrimSuffix(value, "/v2")
-	return strings.TrimRight(value, "/")
+// controlPlaneURL resolves the control plane URL with precedence input >
+// SAKI_CONTROL_PLANE_URL > SAKI_CONTROL_PLANE_URL_FILE. The file is only read
+// when neither of the others is set.
+func (s *Service) controlPlaneURL(inputURL string) (string, error) {
+	if strings.TrimSpace(inputURL) != "" {
+		return resolveControlPlaneURL(inputURL, "")
+	}
+	envURL, err := envControlPlaneURL(envValue(s.controlPlaneURLValue), envValue(s.controlPlaneFileValue))
+	if err != nil {
+		return "", err
+	}
+	return resolveControlPlaneURL(inputURL, envURL)
 }
 
 func resolveControlPlaneURL(inputURL, envURL string) (string, error) {
@@ -320,7 +1062,31 @@ func resolveControlPlaneURL(inputURL, envURL string) (string, error) {
 		return url, nil
 	}
 
-	return "", apperrors.New(apperrors.CodeInvalidInput, "resolve control plane URL", "saki_control_plane_url is required (or set SAKI_CONTROL_PLANE_URL)")
+	return "", apperrors.New(apperrors.CodeInvalidInput, "resolve control plane URL", "saki_control_plane_url is required (or set SAKI_CONTROL_PLANE_URL or SAKI_CONTROL_PLANE_URL_FILE)")
+}
+
+// envControlPlaneURL returns envURL, falling back to the contents of the file
+// at path. Keeping the tokenized URL in a file keeps it out of
+// /proc/<pid>/environ. A file that is set but unreadable or empty fails with
+// CodeConfig.
+func envControlPlaneURL(envURL, path string) (string, error) {
+	if url := strings.TrimSpace(envURL); url != "" {
+		return url, nil
+	}
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", apperrors.Wrap(apperrors.CodeConfig, "read "+controlPlaneFileEnv, err)
+	}
+	url := strings.TrimSpace(string(data))
+	if url == "" {
+		return "", apperrors.New(apperrors.CodeConfig, "read "+controlPlaneFileEnv, fmt.Sprintf("%s is empty", path))
+	}
+	return url, nil
 }
 
 func firstNonEmpty(values ...string) string {
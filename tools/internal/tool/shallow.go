@@ -0,0 +1,62 @@
+package tool
+
+import (
+	"context"
+	"strings"
+)
+
+const gitUnshallowEnv = "SAKI_GIT_UNSHALLOW"
+
+type noUnshallowKey struct{}
+
+// withoutUnshallow marks ctx so runGitHistory never fetches history on it,
+// even with SAKI_GIT_UNSHALLOW enabled. Validate uses it to stay free of side
+// effects.
+func withoutUnshallow(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noUnshallowKey{}, true)
+}
+
+// runGitHistory runs a git command that walks history (path-scoped log,
+// describe). CI checkouts are often shallow, so when it fails or prints
+// nothing in a shallow clone and SAKI_GIT_UNSHALLOW is enabled, the full
+// history is fetched once with git fetch --unshallow and the command retried.
+// Unshallowing is off by default to keep CI checkouts fast.
+func (s *Service) runGitHistory(ctx context.Context, args ...string) (string, error) {
+	out, err := s.runGit(ctx, args...)
+	if err == nil && strings.TrimSpace(out) != "" {
+		return out, nil
+	}
+	if !s.shallowRepository(ctx) {
+		return out, err
+	}
+
+	if !envEnabled(envValue(s.gitUnshallowValue)) {
+		s.logger.Info("git history is shallow; set "+gitUnshallowEnv+" to fetch it", map[string]any{
+			"git_args": strings.Join(args, " "),
+		})
+		return out, err
+	}
+	if skip, _ := ctx.Value(noUnshallowKey{}).(bool); skip {
+		s.logger.Info("git history is shallow; not fetching it during validation", map[string]any{
+			"git_args": strings.Join(args, " "),
+		})
+		return out, err
+	}
+
+	s.logger.Info("git history is shallow; fetching full history", map[string]any{
+		"git_args": strings.Join(args, " "),
+	})
+	if _, fetchErr := s.runGit(ctx, "fetch", "--unshallow"); fetchErr != nil {
+		s.logger.Error("git fetch --unshallow failed; continuing", map[string]any{
+			"error": fetchErr.Error(),
+		})
+		return out, err
+	}
+	return s.runGit(ctx, args...)
+}
+
+// shallowRepository reports whether the working directory is a shallow clone.
+func (s *Service) shallowRepository(ctx context.Context) bool {
+	out, err := s.runGit(ctx, "rev-parse", "--is-shallow-repository")
+	return err == nil && strings.TrimSpace(out) == "true"
+}
@@ -0,0 +1,108 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// shallowGit fakes a shallow clone in which describe fails until the history
+// is fetched.
+type shallowGit struct {
+	shallow  bool
+	fetchErr error
+	calls    []string
+}
+
+func (g *shallowGit) run(_ context.Context, args ...string) (string, error) {
+	call := strings.Join(args, " ")
+	g.calls = append(g.calls, call)
+	switch call {
+	case "rev-parse --is-shallow-repository":
+		if g.shallow {
+			return "true", nil
+		}
+		return "false", nil
+	case "fetch --unshallow":
+		if g.fetchErr != nil {
+			return "", g.fetchErr
+		}
+		g.shallow = false
+		return "", nil
+	case "describe --tags --abbrev=0":
+		if g.shallow {
+			return "", errors.New("exit status 128: fatal: No names found, cannot describe anything.")
+		}
+		return "v1.2.3", nil
+	}
+	return "", errors.New("unexpected git command " + call)
+}
+
+func TestRunGitHistory_Unshallow(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   string
+		shallow   bool
+		fetchErr  error
+		wantTag   string
+		wantCalls []string
+	}{
+		{
+			name:    "shallow clone is fetched and retried",
+			enabled: "1",
+			shallow: true,
+			wantTag: "v1.2.3",
+			wantCalls: []string{
+				"describe --tags --abbrev=0",
+				"rev-parse --is-shallow-repository",
+				"fetch --unshallow",
+				"describe --tags --abbrev=0",
+			},
+		},
+		{
+			name:    "shallow clone is left alone by default",
+			shallow: true,
+			wantCalls: []string{
+				"describe --tags --abbrev=0",
+				"rev-parse --is-shallow-repository",
+			},
+		},
+		{
+			name:     "failed fetch keeps the original failure",
+			enabled:  "1",
+			shallow:  true,
+			fetchErr: errors.New("exit status 128: fatal: could not read from remote repository"),
+			wantCalls: []string{
+				"describe --tags --abbrev=0",
+				"rev-parse --is-shallow-repository",
+				"fetch --unshallow",
+			},
+		},
+		{
+			name:      "full clone is not fetched",
+			enabled:   "1",
+			wantTag:   "v1.2.3",
+			wantCalls: []string{"describe --tags --abbrev=0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			git := &shallowGit{shallow: tt.shallow, fetchErr: tt.fetchErr}
+			svc := &Service{
+				logger:            &noopLogger{},
+				runGit:            git.run,
+				gitUnshallowValue: func() string { return tt.enabled },
+			}
+
+			if got := svc.semverTag(context.Background()); got != tt.wantTag {
+				t.Fatalf("expected tag %q, got %q", tt.wantTag, got)
+			}
+			if !reflect.DeepEqual(git.calls, tt.wantCalls) {
+				t.Fatalf("unexpected git calls:\ngot  %q\nwant %q", git.calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const (
+	statusHealthy   = "healthy"
+	statusUnhealthy = "unhealthy"
+
+	defaultSmokeCheckPath     = "/"
+	defaultSmokeCheckTimeout  = 60 * time.Second
+	defaultSmokeCheckAttempts = 5
+)
+
+// smokeCheckRequiredEnv makes an unhealthy smoke check fail the deploy.
+const smokeCheckRequiredEnv = "SAKI_SMOKE_CHECK_REQUIRED"
+
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// smokeCheck polls the deployed app URL until it answers with a 2xx status or
+// the attempts run out. An unhealthy app is reported as a warning, not an
+// error, unless SAKI_SMOKE_CHECK_REQUIRED is enabled.
+func (s *Service) smokeCheck(ctx context.Context, appURL string) (string, error) {
+	path := firstNonEmpty(envValue(s.smokeCheckPathValue), defaultSmokeCheckPath)
+	timeout, err := resolveSmokeCheckTimeout(envValue(s.smokeCheckTimeoutValue))
+	if err != nil {
+		return "", err
+	}
+
+	client := s.smokeHTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	target := strings.TrimRight(appURL, "/") + "/" + strings.TrimLeft(path, "/")
+	interval := timeout / defaultSmokeCheckAttempts
+
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		lastErr = probeURL(checkCtx, client, target, interval)
+		if lastErr == nil {
+			s.logger.Info("smoke check passed", map[string]any{
+				"url":     target,
+				"attempt": attempt,
+			})
+			return statusHealthy, nil
+		}
+		if attempt >= defaultSmokeCheckAttempts || !s.takeRetry(ctx, StageSmokeCheck) || !sleepContext(checkCtx, s.retryDelay(interval)) {
+			break
+		}
+	}
+
+	if envEnabled(envValue(s.smokeCheckRequiredValue)) {
+		return statusUnhealthy, apperrors.Wrap(apperrors.CodeUnhealthy, "smoke check", fmt.Errorf("%s did not answer with 2xx after deploy: %w", target, lastErr))
+	}
+	s.logger.Error("smoke check failed; continuing with unhealthy status", map[string]any{
+		"url":   target,
+		"error": lastErr.Error(),
+	})
+	return statusUnhealthy, nil
+}
+
+func probeURL(ctx context.Context, client httpDoer, target string, timeout time.Duration) error {
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(probeCtx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sleepContext waits for d and reports false when ctx ends first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func resolveSmokeCheckTimeout(envTimeout string) (time.Duration, error) {
+	value := strings.TrimSpace(envTimeout)
+	if value == "" {
+		return defaultSmokeCheckTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, apperrors.Wrap(apperrors.CodeConfig, "resolve smoke check timeout", fmt.Errorf("parse %s: %w", smokeCheckTimeoutEnv, err))
+	}
+	if timeout <= 0 {
+		return 0, apperrors.New(apperrors.CodeConfig, "resolve smoke check timeout", smokeCheckTimeoutEnv+" must be positive")
+	}
+
+	return timeout, nil
+}
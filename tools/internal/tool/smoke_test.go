@@ -0,0 +1,126 @@
+package tool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// smokeCheckService deploys to an app at appURL with the smoke check enabled.
+func smokeCheckService(appURL string, required bool) *Service {
+	cp := &stubControlPlane{
+		prepareRes: controlplane.PrepareAppResponse{
+			Repository:  "registry.internal/owner/my-app",
+			RequiredTag: "abc1234",
+		},
+		deployRes: controlplane.DeployAppResponse{
+			AppID:  "app_123",
+			URL:    appURL,
+			Status: "deploying",
+		},
+	}
+	return &Service{
+		newControlPlane:         func(string) (controlPlaneClient, error) { return cp, nil },
+		newDockerClient:         func(Logger) dockerClient { return &stubDockerClient{} },
+		resolveGitCommit:        func(context.Context) (string, error) { return "abc", nil },
+		smokeCheckValue:         func() string { return "true" },
+		smokeCheckPathValue:     func() string { return "/healthz" },
+		smokeCheckTimeoutValue:  func() string { return "250ms" },
+		smokeCheckRequiredValue: func() string { return strconv.FormatBool(required) },
+		logger:                  &noopLogger{},
+	}
+}
+
+func smokeCheckInput(t *testing.T) contracts.DeployAppInput {
+	return contracts.DeployAppInput{
+		Name:                "my-app",
+		Description:         "internal app",
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppDir:              t.TempDir(),
+	}
+}
+
+func TestDeployApp_SmokeCheckReportsHealth(t *testing.T) {
+	var calls, statusCode atomic.Int32
+	statusCode.Store(http.StatusOK)
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/healthz" {
+			t.Errorf("expected /healthz path, got %s", r.URL.Path)
+		}
+		w.WriteHeader(int(statusCode.Load()))
+	}))
+	defer app.Close()
+	svc := smokeCheckService(app.URL, false)
+
+	out, err := svc.DeployApp(context.Background(), smokeCheckInput(t))
+	if err != nil {
+		t.Fatalf("DeployApp returned error: %v", err)
+	}
+	if out.Status != statusHealthy || calls.Load() != 1 {
+		t.Fatalf("expected healthy after one request, got %q after %d", out.Status, calls.Load())
+	}
+
+	statusCode.Store(http.StatusServiceUnavailable)
+	calls.Store(0)
+	out, err = svc.DeployApp(context.Background(), smokeCheckInput(t))
+	if err != nil {
+		t.Fatalf("expected smoke check failures to be non-fatal, got %v", err)
+	}
+	if out.Status != statusUnhealthy || calls.Load() != defaultSmokeCheckAttempts {
+		t.Fatalf("expected unhealthy after %d requests, got %q after %d", defaultSmokeCheckAttempts, out.Status, calls.Load())
+	}
+}
+
+func TestDeployApp_SmokeCheckRequiredFailsUnhealthyDeploy(t *testing.T) {
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer app.Close()
+
+	_, err := smokeCheckService(app.URL, true).DeployApp(context.Background(), smokeCheckInput(t))
+	if got := apperrors.CodeOf(err); got != apperrors.CodeUnhealthy {
+		t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeUnhealthy, got, err)
+	}
+	if !strings.Contains(err.Error(), app.URL+"/healthz") {
+		t.Fatalf("expected the error to name the checked URL, got %v", err)
+	}
+}
+
+func TestDeployApp_SmokeCheckDisabledByDefault(t *testing.T) {
+	cp := &stubControlPlane{
+		prepareRes: controlplane.PrepareAppResponse{
+			Repository:  "registry.internal/owner/my-app",
+			RequiredTag: "abc1234",
+		},
+		deployRes: controlplane.DeployAppResponse{URL: "http://127.0.0.1:1", Status: "deploying"},
+	}
+
+	svc := &Service{
+		newControlPlane:  func(string) (controlPlaneClient, error) { return cp, nil },
+		newDockerClient:  func(Logger) dockerClient { return &stubDockerClient{} },
+		resolveGitCommit: func(context.Context) (string, error) { return "abc", nil },
+		logger:           &noopLogger{},
+	}
+
+	out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		Name:                "my-app",
+		Description:         "internal app",
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppDir:              t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if out.Status != "deploying" {
+		t.Fatalf("expected control plane status to pass through, got %q", out.Status)
+	}
+}
@@ -0,0 +1,92 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1800agents/saki/tools/docker"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const (
+	stagedPushEnv = "SAKI_STAGED_PUSH"
+
+	stagingTagSuffix = "-staging"
+)
+
+func (s *Service) stagedPushEnabled() bool {
+	return envEnabled(envValue(s.stagedPushValue))
+}
+
+// checkStagingTag rejects tags that fit docker's limit on their own but not
+// once the staging suffix is appended.
+func checkStagingTag(tag string) error {
+	if len(tag)+len(stagingTagSuffix) <= maxTagLength {
+		return nil
+	}
+	return apperrors.New(apperrors.CodeConfig, "staged push", fmt.Sprintf(
+		"staging tag %q is %d characters; docker allows at most %d (use a shorter tag or unset %s)",
+		tag+stagingTagSuffix, len(tag)+len(stagingTagSuffix), maxTagLength, stagedPushEnv,
+	))
+}
+
+// buildTarget returns the reference the build should produce. A
+// multi-platform build pushes as it builds, so with SAKI_STAGED_PUSH enabled
+// it builds the staging tag and promoteStaged moves it to image afterwards.
+func (s *Service) buildTarget(image string, buildOpts docker.BuildOptions) string {
+	if s.stagedPushEnabled() && buildOpts.PushesOnBuild() {
+		return image + stagingTagSuffix
+	}
+	return image
+}
+
+// pushImage pushes the built image. With SAKI_STAGED_PUSH enabled the push
+// goes through a staging tag first (see stagedPush).
+func (s *Service) pushImage(ctx context.Context, dockerClient dockerClient, image string, progress ProgressFunc) error {
+	if !s.stagedPushEnabled() {
+		return dockerClient.Push(ctx, image, docker.PushOptions{Progress: progress.pushProgressFunc()})
+	}
+	return s.stagedPush(ctx, dockerClient, image, progress)
+}
+
+// stagedPush pushes image as <image>-staging, verifies the registry serves
+// it, and only then promotes it by pushing the final tag. The final push only
+// re-links layers the staging push uploaded, so the window in which the final
+// tag points at a partially pushed image is as small as the registry allows.
+// A failure before promotion leaves the final tag untouched.
+func (s *Service) stagedPush(ctx context.Context, dockerClient dockerClient, image string, progress ProgressFunc) error {
+	staging := image + stagingTagSuffix
+
+	if err := dockerClient.Tag(ctx, image, staging); err != nil {
+		return err
+	}
+	if err := dockerClient.Push(ctx, staging, docker.PushOptions{Progress: progress.pushProgressFunc()}); err != nil {
+		return err
+	}
+	if err := s.verifyStaging(ctx, dockerClient, image); err != nil {
+		return err
+	}
+	return dockerClient.Push(ctx, image, docker.PushOptions{})
+}
+
+// promoteStaged finishes a staged multi-platform push: buildx already pushed
+// <image>-staging, so once the registry serves it the index is copied to the
+// final tag in the registry.
+func (s *Service) promoteStaged(ctx context.Context, dockerClient dockerClient, image string) error {
+	if err := s.verifyStaging(ctx, dockerClient, image); err != nil {
+		return err
+	}
+	return dockerClient.CopyImage(ctx, image+stagingTagSuffix, image)
+}
+
+func (s *Service) verifyStaging(ctx context.Context, dockerClient dockerClient, image string) error {
+	staging := image + stagingTagSuffix
+	if err := dockerClient.InspectManifest(ctx, staging); err != nil {
+		return err
+	}
+	s.logger.Info("staging image verified; promoting", map[string]any{
+		"staging_image": staging,
+		"image":         image,
+	})
+	return nil
+}
@@ -0,0 +1,178 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+// orderedControlPlane records the docker calls made before DeployApp.
+type orderedControlPlane struct {
+	*stubControlPlane
+	docker        *stubDockerClient
+	callsAtDeploy []string
+}
+
+func (o *orderedControlPlane) DeployApp(ctx context.Context, req controlplane.DeployAppRequest) (controlplane.DeployAppResponse, error) {
+	o.callsAtDeploy = append([]string(nil), o.docker.calls...)
+	return o.stubControlPlane.DeployApp(ctx, req)
+}
+
+func TestDeployApp_StagedPushPromotesBeforeDeploy(t *testing.T) {
+	const image = "registry.corgi-teeth.ts.net/owner/my-app:abc1234"
+
+	tests := []struct {
+		name       string
+		inspectErr error
+		wantCalls  []string
+		wantDeploy bool
+	}{
+		{
+			name: "verified staging image is promoted then deployed",
+			wantCalls: []string{
+				"tag " + image + " " + image + "-staging",
+				"push " + image + "-staging",
+				"inspect " + image + "-staging",
+				"push " + image,
+			},
+			wantDeploy: true,
+		},
+		{
+			name:       "missing staging image is never promoted or deployed",
+			inspectErr: errors.New("no such manifest"),
+			wantCalls: []string{
+				"tag " + image + " " + image + "-staging",
+				"push " + image + "-staging",
+				"inspect " + image + "-staging",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerStub := &stubDockerClient{inspectErr: tt.inspectErr}
+			cp := &orderedControlPlane{
+				stubControlPlane: &stubControlPlane{
+					prepareRes: controlplane.PrepareAppResponse{
+						Repository:  "registry.internal/owner/my-app",
+						RequiredTag: "abc1234",
+					},
+					deployRes: controlplane.DeployAppResponse{Status: "deploying"},
+				},
+				docker: dockerStub,
+			}
+			svc := NewTestService(TestDeps{
+				ControlPlane: cp,
+				Docker:       dockerStub,
+				Env:          map[string]string{stagedPushEnv: "1"},
+			})
+
+			_, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				Name:                "my-app",
+				Description:         "internal app",
+				AppDir:              t.TempDir(),
+			})
+
+			if !reflect.DeepEqual(dockerStub.calls, tt.wantCalls) {
+				t.Fatalf("unexpected docker calls:\ngot  %q\nwant %q", dockerStub.calls, tt.wantCalls)
+			}
+			if !tt.wantDeploy {
+				if !errors.Is(err, tt.inspectErr) {
+					t.Fatalf("expected inspect error, got %v", err)
+				}
+				if len(cp.deployReqs) != 0 {
+					t.Fatalf("expected no deploy, got %d", len(cp.deployReqs))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !reflect.DeepEqual(cp.callsAtDeploy, tt.wantCalls) {
+				t.Fatalf("deploy ran before promotion; docker calls at deploy: %q", cp.callsAtDeploy)
+			}
+		})
+	}
+}
+
+func TestDeployApp_StagedPushPromotesMultiPlatformIndex(t *testing.T) {
+	const image = "registry.corgi-teeth.ts.net/owner/my-app:abc1234"
+
+	dockerStub := &stubDockerClient{}
+	cp := &orderedControlPlane{
+		stubControlPlane: &stubControlPlane{
+			prepareRes: controlplane.PrepareAppResponse{
+				Repository:  "registry.internal/owner/my-app",
+				RequiredTag: "abc1234",
+			},
+			deployRes: controlplane.DeployAppResponse{Status: "deploying"},
+		},
+		docker: dockerStub,
+	}
+	svc := NewTestService(TestDeps{
+		ControlPlane: cp,
+		Docker:       dockerStub,
+		Env:          map[string]string{stagedPushEnv: "1"},
+	})
+
+	_, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		Name:                "my-app",
+		Description:         "internal app",
+		AppDir:              t.TempDir(),
+		Platforms:           []string{"linux/amd64", "linux/arm64"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if dockerStub.image != image+"-staging" {
+		t.Fatalf("expected buildx to push the staging tag, got %q", dockerStub.image)
+	}
+	wantCalls := []string{
+		"inspect " + image + "-staging",
+		"copy " + image + "-staging " + image,
+	}
+	if !reflect.DeepEqual(cp.callsAtDeploy, wantCalls) {
+		t.Fatalf("unexpected docker calls at deploy:\ngot  %q\nwant %q", cp.callsAtDeploy, wantCalls)
+	}
+	if len(cp.deployReqs) != 1 || cp.deployReqs[0].Image != image {
+		t.Fatalf("expected deploy of %q, got %#v", image, cp.deployReqs)
+	}
+}
+
+func TestDeployApp_StagedPushRejectsTagWithoutRoomForSuffix(t *testing.T) {
+	dockerStub := &stubDockerClient{}
+	cp := &stubControlPlane{
+		prepareRes: controlplane.PrepareAppResponse{
+			Repository:  "registry.internal/owner/my-app",
+			RequiredTag: strings.Repeat("t", maxTagLength-len(stagingTagSuffix)+1),
+		},
+	}
+	svc := NewTestService(TestDeps{
+		ControlPlane: cp,
+		Docker:       dockerStub,
+		Env:          map[string]string{stagedPushEnv: "1"},
+	})
+
+	_, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		Name:                "my-app",
+		Description:         "internal app",
+		AppDir:              t.TempDir(),
+	})
+
+	if got := apperrors.CodeOf(err); got != apperrors.CodeConfig {
+		t.Fatalf("expected code %q, got %q (%v)", apperrors.CodeConfig, got, err)
+	}
+	if dockerStub.image != "" || len(dockerStub.calls) != 0 || len(cp.deployReqs) != 0 {
+		t.Fatalf("expected nothing built, pushed or deployed, got build=%q calls=%q deploys=%d", dockerStub.image, dockerStub.calls, len(cp.deployReqs))
+	}
+}
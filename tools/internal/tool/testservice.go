@@ -0,0 +1,62 @@
+package tool
+
+import (
+	"context"
+	"errors"
+)
+
+// ControlPlaneClient and DockerClient are the Service's external
+// dependencies, exported so fakes outside this package (see tooltest) can be
+// passed to NewTestService.
+type (
+	ControlPlaneClient = controlPlaneClient
+	DockerClient       = dockerClient
+)
+
+// defaultTestGitCommit is HEAD for a NewTestService without TestDeps.GitCommit.
+const defaultTestGitCommit = "0123456789abcdef0123456789abcdef01234567"
+
+// TestDeps replaces everything a Service reaches outside the process.
+type TestDeps struct {
+	// ControlPlane serves every control plane URL. Required.
+	ControlPlane ControlPlaneClient
+	// Docker runs logins, builds and pushes. Required.
+	Docker DockerClient
+	// GitCommit is reported as HEAD; it defaults to a fixed 40-character hash.
+	GitCommit string
+	// Env holds SAKI_* settings by name in place of the process environment.
+	Env map[string]string
+	// Logger defaults to discarding everything.
+	Logger Logger
+}
+
+// NewTestService returns a Service that runs the real deploy orchestration
+// against deps, for integration-style tests. Git has no origin remote and
+// retry waits have no jitter.
+func NewTestService(deps TestDeps) *Service {
+	logger := deps.Logger
+	if logger == nil {
+		logger = discardLogger{}
+	}
+	commit := deps.GitCommit
+	if commit == "" {
+		commit = defaultTestGitCommit
+	}
+
+	s := &Service{
+		logger:           logger,
+		newControlPlane:  func(string) (controlPlaneClient, error) { return deps.ControlPlane, nil },
+		newDockerClient:  func(Logger) dockerClient { return deps.Docker },
+		resolveGitCommit: func(context.Context) (string, error) { return commit, nil },
+		runGit: func(context.Context, ...string) (string, error) {
+			return "", errors.New("exit status 1")
+		},
+	}
+	s.bindEnv(func(key string) string { return deps.Env[key] })
+	return s
+}
+
+type discardLogger struct{}
+
+func (discardLogger) Info(string, map[string]any)  {}
+func (discardLogger) Error(string, map[string]any) {}
@@ -0,0 +1,116 @@
+package tool
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/tool/tooltest"
+)
+
+func TestNewTestService_DeploysEndToEnd(t *testing.T) {
+	cp := &tooltest.ControlPlane{}
+	dockerStub := &tooltest.Docker{}
+	svc := NewTestService(TestDeps{
+		ControlPlane: cp,
+		Docker:       dockerStub,
+		Env:          map[string]string{dockerRegistryEnv: "registry.internal"},
+	})
+
+	out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		Name:                "my-app",
+		Description:         "internal app",
+		AppDir:              t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	const image = "registry.internal/owner/my-app:0123456"
+	wantCalls := []string{"login registry.internal", "build " + image, "push " + image}
+	if !reflect.DeepEqual(dockerStub.Calls, wantCalls) {
+		t.Fatalf("unexpected docker calls:\ngot  %q\nwant %q", dockerStub.Calls, wantCalls)
+	}
+	if len(cp.Prepared) != 1 || cp.Prepared[0].GitCommit != defaultTestGitCommit {
+		t.Fatalf("unexpected prepare requests: %+v", cp.Prepared)
+	}
+	if len(cp.Deployed) != 1 || cp.Deployed[0].Image != image {
+		t.Fatalf("unexpected deploy requests: %+v", cp.Deployed)
+	}
+	want := contracts.DeployAppOutput{
+		AppID:          "app-my-app",
+		DeploymentID:   "deployment-1",
+		Image:          image,
+		URL:            "https://my-app.saki.internal",
+		Status:         "deploying",
+		GitCommit:      defaultTestGitCommit,
+		TokenExpiresAt: out.TokenExpiresAt,
+		Timings:        out.Timings,
+	}
+	if !reflect.DeepEqual(out, want) {
+		t.Fatalf("unexpected output:\ngot  %+v\nwant %+v", out, want)
+	}
+
+	// A second deploy of the same commit is now live, so skip-unchanged
+	// short-circuits before docker runs.
+	svc = NewTestService(TestDeps{
+		ControlPlane: cp,
+		Docker:       dockerStub,
+		Env:          map[string]string{dockerRegistryEnv: "registry.internal", skipUnchangedEnv: "1"},
+	})
+	out, err = svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		Name:                "my-app",
+		Description:         "internal app",
+		AppDir:              t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if out.Status != statusUnchanged || len(dockerStub.Calls) != len(wantCalls) {
+		t.Fatalf("expected unchanged deploy without docker calls, got %+v calls=%q", out, dockerStub.Calls)
+	}
+}
+
+func TestDeployApp_ForwardsOrg(t *testing.T) {
+	cp := &tooltest.ControlPlane{}
+	svc := NewTestService(TestDeps{ControlPlane: cp, Docker: &tooltest.Docker{}})
+
+	_, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		Name:                "my-app",
+		Description:         "internal app",
+		Org:                 "platform-team",
+		AppDir:              t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(cp.Prepared) != 1 || cp.Prepared[0].Org != "platform-team" {
+		t.Fatalf("expected org on prepare, got %+v", cp.Prepared)
+	}
+	if len(cp.Deployed) != 1 || cp.Deployed[0].Org != "platform-team" {
+		t.Fatalf("expected org on deploy, got %+v", cp.Deployed)
+	}
+}
+
+func TestDeployApp_ForwardsNote(t *testing.T) {
+	cp := &tooltest.ControlPlane{}
+	svc := NewTestService(TestDeps{ControlPlane: cp, Docker: &tooltest.Docker{}})
+
+	_, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		Name:                "my-app",
+		Description:         "internal app",
+		AppDir:              t.TempDir(),
+		Note:                "hotfix\nfor incident 123\n",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(cp.Deployed) != 1 || cp.Deployed[0].Note != "hotfix for incident 123" {
+		t.Fatalf("expected the flattened note on deploy, got %+v", cp.Deployed)
+	}
+}
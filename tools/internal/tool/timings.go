@@ -0,0 +1,65 @@
+package tool
+
+import (
+	"sync"
+	"time"
+)
+
+// timingTotal is the Timings key for the whole deploy.
+const timingTotal = "total"
+
+// stageTimings measures how long each deploy stage takes from its progress
+// events, using the service clock.
+type stageTimings struct {
+	now   func() time.Time
+	begin time.Time
+
+	mu      sync.Mutex
+	started map[string]time.Time
+	elapsed map[string]int64
+}
+
+func (s *Service) newStageTimings() *stageTimings {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	return &stageTimings{
+		now:     now,
+		begin:   now(),
+		started: map[string]time.Time{},
+		elapsed: map[string]int64{},
+	}
+}
+
+// wrap returns a ProgressFunc that records stage durations before forwarding
+// events to next.
+func (t *stageTimings) wrap(next ProgressFunc) ProgressFunc {
+	return func(event ProgressEvent) {
+		t.mu.Lock()
+		switch event.Status {
+		case ProgressStarted:
+			t.started[event.Stage] = t.now()
+		case ProgressCompleted:
+			if start, ok := t.started[event.Stage]; ok {
+				t.elapsed[event.Stage] = max(t.now().Sub(start).Milliseconds(), 0)
+			}
+		}
+		t.mu.Unlock()
+		next.emit(event)
+	}
+}
+
+// millis returns each completed stage's duration and the total, in
+// milliseconds.
+func (t *stageTimings) millis() map[string]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := make(map[string]int64, len(t.elapsed)+1)
+	for stage, ms := range t.elapsed {
+		timings[stage] = ms
+	}
+	timings[timingTotal] = max(t.now().Sub(t.begin).Milliseconds(), 0)
+	return timings
+}
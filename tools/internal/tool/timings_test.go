@@ -0,0 +1,46 @@
+package tool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/tool/tooltest"
+)
+
+func TestDeployApp_ReportsStageTimings(t *testing.T) {
+	svc := NewTestService(TestDeps{
+		ControlPlane: &tooltest.ControlPlane{},
+		Docker:       &tooltest.Docker{},
+		Env:          map[string]string{dockerRegistryEnv: "registry.internal"},
+	})
+	clock := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	svc.now = func() time.Time {
+		clock = clock.Add(10 * time.Millisecond)
+		return clock
+	}
+
+	out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		Name:                "my-app",
+		Description:         "internal app",
+		AppDir:              t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for _, key := range []string{StagePrepare, StageLogin, StageBuild, StagePush, StageDeploy, timingTotal} {
+		ms, ok := out.Timings[key]
+		if !ok {
+			t.Fatalf("expected a %q timing, got %v", key, out.Timings)
+		}
+		if ms < 0 {
+			t.Fatalf("expected a non-negative %q timing, got %d", key, ms)
+		}
+	}
+	if out.Timings[timingTotal] < out.Timings[StageBuild] {
+		t.Fatalf("expected total to cover every stage, got %v", out.Timings)
+	}
+}
@@ -0,0 +1,263 @@
+// Package tooltest provides in-memory fakes of the deploy service's control
+// plane and docker dependencies, for use with tool.NewTestService.
+package tooltest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1800agents/saki/tools/controlplane"
+	"github.com/1800agents/saki/tools/docker"
+)
+
+// DefaultRepositoryPrefix is where ControlPlane places app repositories unless
+// RepositoryPrefix is set.
+const DefaultRepositoryPrefix = "registry.internal/owner"
+
+// ControlPlane is an in-memory control plane. Prepare hands out
+// <RepositoryPrefix>/<name> with the short commit as the required tag, and
+// deploys are stored so ListApps, GetAppStatus and CheckName see them until
+// DeleteApp. Prepare
+// reports a stored app as existing_app_id, which UpdateApp accepts. The zero
+// value is ready to use and it is safe for concurrent use.
+type ControlPlane struct {
+	RepositoryPrefix string
+
+	mu          sync.Mutex
+	apps        map[string]controlplane.App
+	deployments int
+
+	Prepared   []controlplane.PrepareAppRequest
+	Deployed   []controlplane.DeployAppRequest
+	Updated    []controlplane.DeployAppRequest
+	Cancelled  []string
+	Deleted    []string
+	RolledBack []string
+}
+
+func (c *ControlPlane) PrepareApp(_ context.Context, req controlplane.PrepareAppRequest) (controlplane.PrepareAppResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Prepared = append(c.Prepared, req)
+
+	prefix := c.RepositoryPrefix
+	if prefix == "" {
+		prefix = DefaultRepositoryPrefix
+	}
+	tag := req.GitCommit
+	if len(tag) > 7 {
+		tag = tag[:7]
+	}
+	return controlplane.PrepareAppResponse{
+		Repository:    prefix + "/" + req.Name,
+		PushToken:     "push-token",
+		ExpiresAt:     time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		RequiredTag:   tag,
+		ExistingAppID: c.apps[req.Name].AppID,
+	}, nil
+}
+
+func (c *ControlPlane) DeployApp(_ context.Context, req controlplane.DeployAppRequest) (controlplane.DeployAppResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Deployed = append(c.Deployed, req)
+
+	if c.apps == nil {
+		c.apps = map[string]controlplane.App{}
+	}
+	c.deployments++
+	app := controlplane.App{
+		AppID:        "app-" + req.Name,
+		Name:         req.Name,
+		Image:        req.Image,
+		URL:          "https://" + req.Name + ".saki.internal",
+		Status:       "deploying",
+		DeploymentID: fmt.Sprintf("deployment-%d", c.deployments),
+	}
+	c.apps[req.Name] = app
+
+	return controlplane.DeployAppResponse{
+		AppID:        app.AppID,
+		DeploymentID: app.DeploymentID,
+		URL:          app.URL,
+		Status:       app.Status,
+	}, nil
+}
+
+// UpdateApp starts a new deployment of req.Image for the app with appID, or
+// answers 404 when there is none.
+func (c *ControlPlane) UpdateApp(_ context.Context, appID string, req controlplane.DeployAppRequest) (controlplane.DeployAppResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, app := range c.apps {
+		if app.AppID != appID {
+			continue
+		}
+		c.Updated = append(c.Updated, req)
+		c.deployments++
+		app.Image = req.Image
+		app.DeploymentID = fmt.Sprintf("deployment-%d", c.deployments)
+		app.Status = "deploying"
+		c.apps[name] = app
+		return controlplane.DeployAppResponse{
+			AppID:        app.AppID,
+			DeploymentID: app.DeploymentID,
+			URL:          app.URL,
+			Status:       app.Status,
+		}, nil
+	}
+	return controlplane.DeployAppResponse{}, &controlplane.APIError{StatusCode: http.StatusNotFound, Message: "app not found"}
+}
+
+// ListApps returns every stored app on a single page.
+func (c *ControlPlane) ListApps(_ context.Context, _ controlplane.ListAppsRequest) (controlplane.ListAppsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	apps := make([]controlplane.App, 0, len(c.apps))
+	for _, app := range c.apps {
+		apps = append(apps, app)
+	}
+	return controlplane.ListAppsResponse{Apps: apps}, nil
+}
+
+func (c *ControlPlane) GetAppStatus(_ context.Context, appID string) (controlplane.AppStatusResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, app := range c.apps {
+		if app.AppID == appID {
+			return controlplane.AppStatusResponse{
+				Status:              app.Status,
+				URL:                 app.URL,
+				CurrentDeploymentID: app.DeploymentID,
+				Image:               app.Image,
+			}, nil
+		}
+	}
+	return controlplane.AppStatusResponse{}, &controlplane.APIError{StatusCode: http.StatusNotFound, Message: "app not found"}
+}
+
+func (c *ControlPlane) CancelDeployment(_ context.Context, deploymentID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Cancelled = append(c.Cancelled, deploymentID)
+	return nil
+}
+
+// RollbackDeployment starts a new deployment of the app with appID, or
+// answers 404 when there is none. The rolled-back deployment id is recorded
+// but not checked.
+func (c *ControlPlane) RollbackDeployment(_ context.Context, appID, deploymentID string) (controlplane.DeployAppResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, app := range c.apps {
+		if app.AppID != appID {
+			continue
+		}
+		c.RolledBack = append(c.RolledBack, deploymentID)
+		c.deployments++
+		app.DeploymentID = fmt.Sprintf("deployment-%d", c.deployments)
+		app.Status = "deploying"
+		c.apps[name] = app
+		return controlplane.DeployAppResponse{
+			AppID:        app.AppID,
+			DeploymentID: app.DeploymentID,
+			URL:          app.URL,
+			Status:       app.Status,
+		}, nil
+	}
+	return controlplane.DeployAppResponse{}, &controlplane.APIError{StatusCode: http.StatusNotFound, Message: "app not found"}
+}
+
+// DeleteApp removes the app with appID, or answers 404 when there is none.
+func (c *ControlPlane) DeleteApp(_ context.Context, appID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, app := range c.apps {
+		if app.AppID == appID {
+			delete(c.apps, name)
+			c.Deleted = append(c.Deleted, appID)
+			return nil
+		}
+	}
+	return &controlplane.APIError{StatusCode: http.StatusNotFound, Message: "app not found"}
+}
+
+// CheckName treats every stored app as owned by the caller.
+func (c *ControlPlane) CheckName(_ context.Context, name string) (controlplane.NameAvailability, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, exists := c.apps[name]
+	return controlplane.NameAvailability{Available: !exists, OwnedByYou: exists}, nil
+}
+
+// Docker records docker calls without running anything. Calls lists them in
+// order, as "login <registry>", "build <image>", "push <image>",
+// "tag <source> <target>", "copy <source> <target>" and "inspect <image>". It is safe for concurrent
+// use.
+type Docker struct {
+	// BuildErr and PushErr, when set, fail every build or push.
+	BuildErr error
+	PushErr  error
+	// LocalID is reported for every built image. RemoteIDs holds the
+	// config digests of images already in the registry, by reference.
+	LocalID   string
+	RemoteIDs map[string]string
+
+	mu    sync.Mutex
+	Calls []string
+}
+
+func (d *Docker) record(call ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.Calls = append(d.Calls, strings.Join(call, " "))
+}
+
+func (d *Docker) Login(_ context.Context, registry, _, _ string) error {
+	d.record("login", registry)
+	return nil
+}
+
+func (d *Docker) Build(_ context.Context, _, image string, _ docker.BuildOptions) error {
+	d.record("build", image)
+	return d.BuildErr
+}
+
+func (d *Docker) Push(_ context.Context, image string, _ docker.PushOptions) error {
+	d.record("push", image)
+	return d.PushErr
+}
+
+func (d *Docker) Tag(_ context.Context, source, target string) error {
+	d.record("tag", source, target)
+	return nil
+}
+
+func (d *Docker) CopyImage(_ context.Context, source, target string) error {
+	d.record("copy", source, target)
+	return nil
+}
+
+func (d *Docker) InspectManifest(_ context.Context, image string) error {
+	d.record("inspect", image)
+	return nil
+}
+
+func (d *Docker) LocalImageID(context.Context, string) (string, error) {
+	return d.LocalID, nil
+}
+
+func (d *Docker) RemoteImageID(_ context.Context, image string) (string, bool, error) {
+	id, ok := d.RemoteIDs[image]
+	return id, ok, nil
+}
@@ -0,0 +1,90 @@
+package tool
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/controlplane"
+)
+
+// pushCheckingControlPlane fails the test when the app is updated before the
+// image was pushed.
+type pushCheckingControlPlane struct {
+	*stubControlPlane
+	t      *testing.T
+	docker *stubDockerClient
+}
+
+func (c *pushCheckingControlPlane) UpdateApp(ctx context.Context, appID string, req controlplane.DeployAppRequest) (controlplane.DeployAppResponse, error) {
+	if c.docker.pushImage != req.Image {
+		c.t.Errorf("expected %q to be pushed before the update, got push %q", req.Image, c.docker.pushImage)
+	}
+	return c.stubControlPlane.UpdateApp(ctx, appID, req)
+}
+
+func TestDeployApp_UpdatesExistingApp(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		existingID string
+		wantUpdate bool
+	}{
+		{name: "existing app is updated", env: "1", existingID: "app_1", wantUpdate: true},
+		{name: "new app is created", env: "1"},
+		{name: "disabled keeps POST /apps", existingID: "app_1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerStub := &stubDockerClient{}
+			cp := &pushCheckingControlPlane{
+				stubControlPlane: &stubControlPlane{
+					prepareRes: controlplane.PrepareAppResponse{
+						Repository:    "registry.internal/owner/my-app",
+						RequiredTag:   "abc1234",
+						ExistingAppID: tt.existingID,
+					},
+					deployRes: controlplane.DeployAppResponse{AppID: "app_new", DeploymentID: "dep_1", Status: "deploying"},
+					updateRes: controlplane.DeployAppResponse{AppID: "app_1", DeploymentID: "dep_2", Status: "deploying"},
+				},
+				t:      t,
+				docker: dockerStub,
+			}
+			svc := &Service{
+				logger:              &noopLogger{},
+				newControlPlane:     func(string) (controlPlaneClient, error) { return cp, nil },
+				newDockerClient:     func(Logger) dockerClient { return dockerStub },
+				resolveGitCommit:    func(context.Context) (string, error) { return "abc", nil },
+				updateExistingValue: func() string { return tt.env },
+			}
+
+			out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+				SakiControlPlaneURL: "https://cp.internal?token=test-token",
+				Name:                "my-app",
+				Description:         "internal app",
+				AppDir:              t.TempDir(),
+			})
+			if err != nil {
+				t.Fatalf("deploy: %v", err)
+			}
+
+			if tt.wantUpdate {
+				if !slices.Equal(cp.updateAppIDs, []string{"app_1"}) || len(cp.deployReqs) != 0 {
+					t.Fatalf("expected PATCH /apps/app_1 only, got updates %v and %d POST /apps", cp.updateAppIDs, len(cp.deployReqs))
+				}
+				if cp.updateReqs[0].Name != "my-app" || cp.updateReqs[0].Image != out.Image {
+					t.Fatalf("unexpected update request: %+v", cp.updateReqs[0])
+				}
+				if out.DeploymentID != "dep_2" {
+					t.Fatalf("expected the update's deployment, got %+v", out)
+				}
+				return
+			}
+			if len(cp.updateAppIDs) != 0 || len(cp.deployReqs) != 1 {
+				t.Fatalf("expected POST /apps only, got updates %v and %d POST /apps", cp.updateAppIDs, len(cp.deployReqs))
+			}
+		})
+	}
+}
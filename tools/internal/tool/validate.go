@@ -0,0 +1,78 @@
+package tool
+
+import (
+	"context"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/docker"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+const statusValidated = "validated"
+
+// Validate checks in and the deploy configuration without side effects: the
+// input fields, the control plane URL and its token (unless SAKI_LOCAL_TAG
+// skips the control plane), environment settings, build arg files, app_dir,
+// and that the git commit resolves. It never calls the control plane,
+// docker, or the registry, and never fetches git history, even with
+// SAKI_GIT_UNSHALLOW enabled.
+func (s *Service) Validate(ctx context.Context, in contracts.DeployAppInput) error {
+	if err := in.Validate(); err != nil {
+		return apperrors.Wrap(apperrors.CodeInvalidInput, "validate deploy input", err)
+	}
+	if err := docker.ValidatePlatforms(in.Platforms); err != nil {
+		return err
+	}
+	if _, err := resolveDeployTimeout(envValue(s.deployTimeoutValue)); err != nil {
+		return err
+	}
+	if _, err := resolveRetryBudget(envValue(s.retryBudgetValue)); err != nil {
+		return err
+	}
+	if _, err := resolveImageRepositoryOverride(in.ImageRepository, envValue(s.imageRepositoryValue)); err != nil {
+		return err
+	}
+	if _, err := s.buildLimits(); err != nil {
+		return err
+	}
+	if _, err := readBuildArgFiles(in.BuildArgFiles); err != nil {
+		return err
+	}
+
+	if !s.localTagEnabled() {
+		controlPlaneURL, err := s.controlPlaneURL(in.SakiControlPlaneURL)
+		if err != nil {
+			return err
+		}
+		// Building the client parses the URL and its token; it sends no request.
+		if _, err := s.newControlPlane(controlPlaneURL); err != nil {
+			return err
+		}
+	}
+
+	if _, err := resolveAppDir(in.AppDir); err != nil {
+		return err
+	}
+	commit, err := s.gitCommit(withoutUnshallow(ctx), in)
+	if err != nil {
+		return err
+	}
+	if s.localTagEnabled() {
+		if _, err := localImageTag(commit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateOnly runs Validate for a validate_only deploy request.
+func (s *Service) validateOnly(ctx context.Context, in contracts.DeployAppInput) (contracts.DeployAppOutput, error) {
+	if err := s.Validate(ctx, in); err != nil {
+		return contracts.DeployAppOutput{}, err
+	}
+	s.logger.Info("deploy input validated", map[string]any{
+		"name":    in.Name,
+		"app_dir": in.AppDir,
+	})
+	return contracts.DeployAppOutput{Status: statusValidated}, nil
+}
@@ -0,0 +1,128 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/apperrors"
+)
+
+func TestValidate_ReportsEachFailureWithoutSideEffects(t *testing.T) {
+	appDir := t.TempDir()
+	validInput := func() contracts.DeployAppInput {
+		return contracts.DeployAppInput{
+			Name:                "my-app",
+			Description:         "internal app",
+			SakiControlPlaneURL: "https://cp.internal?token=test-token",
+			AppDir:              appDir,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func(in *contracts.DeployAppInput)
+		env      map[string]string
+		gitErr   error
+		wantCode apperrors.Code
+	}{
+		{name: "valid"},
+		{name: "invalid name", mutate: func(in *contracts.DeployAppInput) { in.Name = "Bad_Name" }, wantCode: apperrors.CodeInvalidInput},
+		{name: "invalid platform", mutate: func(in *contracts.DeployAppInput) { in.Platforms = []string{"amd64"} }, wantCode: apperrors.CodeInvalidInput},
+		{name: "invalid deploy timeout", env: map[string]string{"timeout": "soon"}, wantCode: apperrors.CodeConfig},
+		{name: "invalid image repository env", env: map[string]string{"repository": "ghcr.io/team/app:latest"}, wantCode: apperrors.CodeConfig},
+		{name: "missing control plane URL", mutate: func(in *contracts.DeployAppInput) { in.SakiControlPlaneURL = "" }, wantCode: apperrors.CodeInvalidInput},
+		{name: "control plane URL without token", mutate: func(in *contracts.DeployAppInput) { in.SakiControlPlaneURL = "https://cp.internal" }, wantCode: apperrors.CodeInvalidInput},
+		{name: "missing app dir", mutate: func(in *contracts.DeployAppInput) { in.AppDir = filepath.Join(appDir, "missing") }, wantCode: apperrors.CodeInvalidInput},
+		{name: "git commit unresolved", gitErr: apperrors.New(apperrors.CodeConfig, "resolve git commit", "not a git repository"), wantCode: apperrors.CodeConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := validInput()
+			if tt.mutate != nil {
+				tt.mutate(&in)
+			}
+			svc := &Service{
+				newDockerClient: func(Logger) dockerClient {
+					t.Fatal("validate must not create a docker client")
+					return nil
+				},
+				resolveGitCommit:     func(context.Context) (string, error) { return "abc", tt.gitErr },
+				deployTimeoutValue:   func() string { return tt.env["timeout"] },
+				imageRepositoryValue: func() string { return tt.env["repository"] },
+				logger:               &noopLogger{},
+			}
+			svc.newControlPlane = svc.newControlPlaneClient
+
+			err := svc.Validate(context.Background(), in)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("expected valid input, got %v", err)
+				}
+				return
+			}
+			if got := apperrors.CodeOf(err); got != tt.wantCode {
+				t.Fatalf("expected code %q, got %q (%v)", tt.wantCode, got, err)
+			}
+		})
+	}
+}
+
+func TestValidate_DoesNotUnshallow(t *testing.T) {
+	git := &shallowGit{shallow: true}
+	svc := &Service{
+		runGit:            git.run,
+		resolveGitCommit:  func(context.Context) (string, error) { return "abc", nil },
+		pathCommitValue:   func() string { return "1" },
+		gitUnshallowValue: func() string { return "1" },
+		logger:            &noopLogger{},
+	}
+	svc.newControlPlane = svc.newControlPlaneClient
+
+	err := svc.Validate(context.Background(), contracts.DeployAppInput{
+		Name:                "my-app",
+		Description:         "internal app",
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppDir:              t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("expected valid input, got %v", err)
+	}
+	if slices.Contains(git.calls, "fetch --unshallow") {
+		t.Fatalf("validate must not fetch git history, got calls %q", git.calls)
+	}
+	if !slices.Contains(git.calls, "rev-parse --is-shallow-repository") {
+		t.Fatalf("expected the path commit lookup to reach the shallow check, got calls %q", git.calls)
+	}
+}
+
+func TestDeployApp_ValidateOnlySkipsControlPlaneAndDocker(t *testing.T) {
+	cp := &stubControlPlane{prepareErr: errors.New("prepare must not be called")}
+	svc := &Service{
+		newControlPlane:  func(string) (controlPlaneClient, error) { return cp, nil },
+		newDockerClient:  func(Logger) dockerClient { return &stubDockerClient{buildErr: errors.New("build must not be called")} },
+		resolveGitCommit: func(context.Context) (string, error) { return "abc", nil },
+		logger:           &noopLogger{},
+	}
+
+	out, err := svc.DeployApp(context.Background(), contracts.DeployAppInput{
+		Name:                "my-app",
+		Description:         "internal app",
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		AppDir:              t.TempDir(),
+		ValidateOnly:        true,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if out.Status != statusValidated {
+		t.Fatalf("expected status %q, got %q", statusValidated, out.Status)
+	}
+	if len(cp.prepareReqs) != 0 || len(cp.deployReqs) != 0 {
+		t.Fatalf("expected no control plane calls, got prepare=%d deploy=%d", len(cp.prepareReqs), len(cp.deployReqs))
+	}
+}
@@ -0,0 +1,144 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/1800agents/saki/tools/contracts"
+)
+
+const deployWebhookEnv = "SAKI_DEPLOY_WEBHOOK"
+
+// defaultWebhookRetry mirrors the control plane request handling: a 15s
+// per-attempt timeout and jittered retries of transient failures, bounded by
+// the deploy's retry budget.
+var defaultWebhookRetry = prepareRetryPolicy{
+	attempts:     3,
+	firstTimeout: 15 * time.Second,
+	timeout:      15 * time.Second,
+	delay:        time.Second,
+}
+
+// webhookPayload is the deploy summary POSTed to SAKI_DEPLOY_WEBHOOK.
+type webhookPayload struct {
+	App          string `json:"app"`
+	Image        string `json:"image"`
+	URL          string `json:"url,omitempty"`
+	Status       string `json:"status"`
+	GitCommit    string `json:"git_commit,omitempty"`
+	DeploymentID string `json:"deployment_id,omitempty"`
+}
+
+// notifyWebhook POSTs a summary of a successful push or deploy to
+// SAKI_DEPLOY_WEBHOOK. It never fails the deploy: failures are logged, with
+// the webhook URL reduced to its host.
+func (s *Service) notifyWebhook(ctx context.Context, name string, out contracts.DeployAppOutput) {
+	webhookURL := strings.TrimSpace(envValue(s.deployWebhookValue))
+	if webhookURL == "" {
+		return
+	}
+
+	// A struct of strings always marshals.
+	body, _ := json.Marshal(webhookPayload{
+		App:          name,
+		Image:        out.Image,
+		URL:          out.URL,
+		Status:       out.Status,
+		GitCommit:    out.GitCommit,
+		DeploymentID: out.DeploymentID,
+	})
+
+	if err := s.postWebhook(ctx, webhookURL, body); err != nil {
+		s.logger.Error("deploy webhook failed; continuing", map[string]any{
+			"webhook": redactWebhookURL(webhookURL),
+			"error":   err.Error(),
+		})
+		return
+	}
+	s.logger.Info("deploy webhook sent", map[string]any{
+		"webhook": redactWebhookURL(webhookURL),
+	})
+}
+
+func (s *Service) postWebhook(ctx context.Context, webhookURL string, body []byte) error {
+	policy := defaultWebhookRetry
+	if s.webhookRetry != nil {
+		policy = *s.webhookRetry
+	}
+	client := s.webhookHTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= max(policy.attempts, 1); attempt++ {
+		timeout := policy.timeout
+		if attempt == 1 {
+			timeout = policy.firstTimeout
+		}
+
+		retryable, err := webhookAttempt(ctx, client, webhookURL, body, timeout)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if !retryable || ctx.Err() != nil || attempt >= policy.attempts {
+			break
+		}
+		if !s.takeRetry(ctx, "webhook") {
+			break
+		}
+		if !sleepContext(ctx, s.retryDelay(policy.delay)) {
+			break
+		}
+	}
+	return lastErr
+}
+
+// webhookAttempt sends one POST. Network errors, timeouts, and 5xx responses
+// are reported as retryable.
+func webhookAttempt(ctx context.Context, client httpDoer, webhookURL string, body []byte, timeout time.Duration) (retryable bool, err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// The error embeds the URL, which may carry a token.
+		return true, fmt.Errorf("post webhook: %s", strings.ReplaceAll(err.Error(), webhookURL, redactWebhookURL(webhookURL)))
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode >= 500, fmt.Errorf("webhook answered %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+// redactWebhookURL keeps only the scheme and host of a webhook URL. Webhook
+// services commonly embed their secret in the path (e.g. Slack incoming
+// webhooks) or the query.
+func redactWebhookURL(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return "<redacted>"
+	}
+	return u.Scheme + "://" + u.Host + "/<redacted>"
+}
@@ -0,0 +1,101 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/1800agents/saki/tools/contracts"
+	"github.com/1800agents/saki/tools/internal/tool/tooltest"
+)
+
+func TestDeployApp_PostsWebhookSummary(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	svc := NewTestService(TestDeps{
+		ControlPlane: &tooltest.ControlPlane{},
+		Docker:       &tooltest.Docker{},
+		Env:          map[string]string{dockerRegistryEnv: "registry.internal", deployWebhookEnv: server.URL + "/hooks/secret-path"},
+	})
+	if _, err := svc.DeployApp(context.Background(), deployInput(t)); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := map[string]any{
+		"app":           "my-app",
+		"image":         "registry.internal/owner/my-app:0123456",
+		"url":           "https://my-app.saki.internal",
+		"status":        "deploying",
+		"git_commit":    defaultTestGitCommit,
+		"deployment_id": "deployment-1",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected payload: %v", got)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Fatalf("payload %s: got %v want %v", key, got[key], value)
+		}
+	}
+}
+
+func TestDeployApp_WebhookFailureDoesNotAbort(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	logger := &captureLogger{}
+	svc := NewTestService(TestDeps{
+		ControlPlane: &tooltest.ControlPlane{},
+		Docker:       &tooltest.Docker{},
+		Env:          map[string]string{deployWebhookEnv: server.URL + "/hooks/secret-path"},
+		Logger:       logger,
+	})
+	svc.webhookRetry = &prepareRetryPolicy{attempts: 2}
+
+	out, err := svc.DeployApp(context.Background(), deployInput(t))
+	if err != nil {
+		t.Fatalf("expected webhook failure not to abort the deploy, got %v", err)
+	}
+	if out.Status != "deploying" {
+		t.Fatalf("unexpected output: %+v", out)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected the 5xx to be retried once, got %d calls", n)
+	}
+
+	fields, ok := logger.find("deploy webhook failed; continuing")
+	if !ok {
+		t.Fatal("expected webhook failure to be logged")
+	}
+	if webhook, _ := fields["webhook"].(string); strings.Contains(webhook, "secret-path") {
+		t.Fatalf("expected webhook URL to be redacted, got %q", webhook)
+	}
+}
+
+func deployInput(t *testing.T) contracts.DeployAppInput {
+	t.Helper()
+	return contracts.DeployAppInput{
+		SakiControlPlaneURL: "https://cp.internal?token=test-token",
+		Name:                "my-app",
+		Description:         "internal app",
+		AppDir:              t.TempDir(),
+	}
+}
@@ -0,0 +1,7 @@
+// Package version holds the saki-tools build version.
+package version
+
+// Version is the saki-tools version, reported by `saki-tools version`, the
+// MCP server, and the control plane User-Agent. Release builds set it with
+// -ldflags "-X github.com/1800agents/saki/tools/internal/version.Version=v1.2.3".
+var Version = "dev"